@@ -10,6 +10,12 @@ import (
 type Asset interface {
 	// PullAsset pull the asset with given assetCID from specified sources
 	PullAsset(ctx context.Context, assetCID string, sources []*types.CandidateDownloadInfo) error //perm:write
+	// PullAssetFromURL downloads the file of the URL and builds it into an asset, the root CID is reported to the scheduler
+	PullAssetFromURL(ctx context.Context, req *types.URLPullReq) error //perm:write
+	// EncodeAssetShards encodes the asset into erasure coded shards that are stored as assets, the shards are reported to the scheduler
+	EncodeAssetShards(ctx context.Context, req *types.ShardEncodeReq) error //perm:write
+	// ReconstructAsset rebuilds the erasure coded asset from the shards downloaded from the sources
+	ReconstructAsset(ctx context.Context, req *types.ShardReconstructReq) error //perm:write
 	// DeleteAsset deletes the asset with given assetCID
 	DeleteAsset(ctx context.Context, assetCID string) error //perm:write
 	// GetAssetStats retrieves the statistics of assets
@@ -29,6 +29,12 @@ type Scheduler interface {
 	CandidateConnect(ctx context.Context, opts *types.ConnectOptions) error //perm:write
 	// NodeRemoveAssetResult the result of an asset removal operation
 	NodeRemoveAssetResult(ctx context.Context, resultInfo types.RemoveAssetResult) error //perm:write
+	// NodeURLPullResult the result of a candidate building an asset from its origin URL
+	NodeURLPullResult(ctx context.Context, result *types.URLPullResult) error //perm:write
+	// NodeUploadResult the result of an asset uploaded to a candidate with an upload ticket
+	NodeUploadResult(ctx context.Context, result *types.UploadResult) error //perm:write
+	// NodeShardEncodeResult the result of a candidate encoding an asset into erasure coded shards
+	NodeShardEncodeResult(ctx context.Context, result *types.ShardEncodeResult) error //perm:write
 	// GetExternalAddress retrieves the external address of the caller.
 	GetExternalAddress(ctx context.Context) (string, error) //perm:read
 	// VerifyNodeAuthToken checks the authenticity of a node's authentication token and returns the associated permissions
@@ -37,8 +43,18 @@ type Scheduler interface {
 	NodeLogin(ctx context.Context, nodeID, sign string) (string, error) //perm:read
 	// GetNodeInfo get information for node
 	GetNodeInfo(ctx context.Context, nodeID string) (types.NodeInfo, error) //perm:read
-	// GetNodeList retrieves a list of nodes with pagination using the specified cursor and count
-	GetNodeList(ctx context.Context, cursor int, count int) (*types.ListNodesRsp, error) //perm:read
+	// GetNodeList retrieves a list of nodes with pagination using the specified cursor and count, the nodes are filtered by the label selector
+	GetNodeList(ctx context.Context, cursor int, count int, selector *types.LabelSelector) (*types.ListNodesRsp, error) //perm:read
+	// GetNodeScores retrieves the selection scores of the online nodes for a given node type
+	GetNodeScores(ctx context.Context, nodeType types.NodeType) ([]*types.NodeScore, error) //perm:read
+	// SetNodeMaintenance sets the maintenance mode of the node, cordoned or draining, an empty mode puts the node back in service
+	SetNodeMaintenance(ctx context.Context, nodeID string, mode types.NodeMaintenance) error //perm:admin
+	// SetNodeQuarantine quarantines the node until the specified time, a zero time releases the node
+	SetNodeQuarantine(ctx context.Context, nodeID string, until time.Time, reason string) error //perm:admin
+	// SetNodeLabels replaces the labels of the node, e.g. isp=telecom, the labels select the nodes of the asset replicas
+	SetNodeLabels(ctx context.Context, nodeID string, labels map[string]string) error //perm:admin
+	// GetNodeDrainProgress retrieves the replicas that are still on the node
+	GetNodeDrainProgress(ctx context.Context, nodeID string) (*types.NodeDrainProgress, error) //perm:read
 	// GetAssetListForBucket retrieves a list of asset CIDs for a bucket associated with the specified bucket ID (bucketID is 'nodeID + bucketNumber')
 	GetAssetListForBucket(ctx context.Context, bucketID string) ([]string, error) //perm:write
 	// GetEdgeExternalServiceAddress nat travel, get edge external addr with different scheduler
@@ -53,14 +69,60 @@ type Scheduler interface {
 	GetEdgeDownloadInfos(ctx context.Context, cid string) (*types.EdgeDownloadInfoList, error) //perm:read
 	// GetCandidateDownloadInfos retrieves download information for the candidate with the asset with the specified CID.
 	GetCandidateDownloadInfos(ctx context.Context, cid string) ([]*types.CandidateDownloadInfo, error) //perm:read
+	// GetAssetPullSources retrieves the download sources of the asset or shard with the specified CID for the pulling nodes, the candidates and edges are the same as the sources of the pull requests.
+	GetAssetPullSources(ctx context.Context, cid string) ([]*types.CandidateDownloadInfo, error) //perm:read
 	// NodeExists checks if the node with the specified ID exists.
 	NodeExists(ctx context.Context, nodeID string) error //perm:write
 
+	// S3-related methods, the object methods are called by the candidates for the tenant of the access key
+	// CreateS3AccessKey creates an access key of the tenant to the S3 compatible api of the candidates
+	CreateS3AccessKey(ctx context.Context, req *types.S3AccessKeyReq) (*types.S3AccessKey, error) //perm:admin
+	// RemoveS3AccessKey removes the access key, the objects of the tenant are kept
+	RemoveS3AccessKey(ctx context.Context, accessKey string) error //perm:admin
+	// GetS3AccessKey retrieves the access key with its secret for the candidate to verify the request signature, nil if the key does not exist
+	GetS3AccessKey(ctx context.Context, accessKey string) (*types.S3AccessKey, error) //perm:write
+	// PutS3Object saves the object stored by the candidate and pulls its asset with the candidate as the seed
+	PutS3Object(ctx context.Context, accessKey string, obj *types.S3Object) error //perm:write
+	// GetS3Object retrieves the object of the bucket, nil if the object does not exist
+	GetS3Object(ctx context.Context, accessKey, bucket, key string) (*types.S3Object, error) //perm:write
+	// RemoveS3Object removes the object of the bucket, the asset is removed when it expires
+	RemoveS3Object(ctx context.Context, accessKey, bucket, key string) error //perm:write
+	// ListS3Objects lists the objects of the bucket in the order of the keys
+	ListS3Objects(ctx context.Context, accessKey string, req *types.S3ListObjectsReq) ([]*types.S3Object, error) //perm:write
+
+	// Private asset methods, the content is encrypted by the client and only the wrapped data keys are kept
+	// CreateUserToken creates a read token of the user, the wrapped key of a private asset is returned with the download information to the authorised user
+	CreateUserToken(ctx context.Context, userID string) (string, error) //perm:admin
+	// SetAssetKeyEnvelopes saves the wrapped data keys of the private asset with the specified CID for the authorised users
+	SetAssetKeyEnvelopes(ctx context.Context, cid string, envelopes []*types.AssetKeyEnvelope) error //perm:admin
+	// RemoveAssetKeyEnvelope revokes the access of the user to the private asset with the specified CID
+	RemoveAssetKeyEnvelope(ctx context.Context, cid, userID string) error //perm:admin
+
 	// Asset-related methods
 	// PullAsset Pull an asset based on the provided PullAssetReq structure.
 	PullAsset(ctx context.Context, info *types.PullAssetReq) error //perm:admin
+	// PullAssets pulls a batch of assets, the result of each asset is returned in the order of the requests
+	PullAssets(ctx context.Context, infos []*types.PullAssetReq) ([]*types.PullAssetResult, error) //perm:admin
+	// GetURLPullStatus retrieves the status of the asset pulled from its origin URL with the pull id returned by PullAssets
+	GetURLPullStatus(ctx context.Context, id string) (*types.URLPullStatus, error) //perm:read
+	// PlanAssetPlacement selects the replica nodes of the assets like PullAssets does, without saving the replicas or pulling the assets
+	PlanAssetPlacement(ctx context.Context, infos []*types.PullAssetReq) (*types.AssetPlacementPlan, error) //perm:admin
+	// CreateUploadTicket returns the candidate and the ticket to upload a car file of the max size to, the CID and URL of the request are not used
+	CreateUploadTicket(ctx context.Context, maxSize int64, info *types.PullAssetReq) (*types.UploadInfo, error) //perm:admin
+	// GetAssetGroup retrieves the asset records of the group
+	GetAssetGroup(ctx context.Context, groupID string) (*types.AssetGroup, error) //perm:read
+	// UpdateAssetGroupExpiration updates the expiration time for all assets of the group
+	UpdateAssetGroupExpiration(ctx context.Context, groupID string, time time.Time) error //perm:admin
+	// RemoveAssetGroup removes all asset records of the group from the scheduler
+	RemoveAssetGroup(ctx context.Context, groupID string) error //perm:admin
 	// RemoveAssetRecord removes the asset record with the specified CID from the scheduler
 	RemoveAssetRecord(ctx context.Context, cid string) error //perm:admin
+	// RestoreAsset restores the pending delete asset with the specified CID, the expiration is kept if it is zero
+	RestoreAsset(ctx context.Context, cid string, expiration time.Time) error //perm:admin
+	// PauseAsset freezes the pulling of the asset with the specified CID, the asset keeps the state to be resumed
+	PauseAsset(ctx context.Context, cid string) error //perm:admin
+	// ResumeAsset returns the paused asset with the specified CID to the state before it was paused
+	ResumeAsset(ctx context.Context, cid string) error //perm:admin
 	// RemoveAssetReplica deletes an asset replica with the specified CID and node from the scheduler
 	RemoveAssetReplica(ctx context.Context, cid, nodeID string) error //perm:admin
 	// GetAssetRecord retrieves the asset record with the specified CID
@@ -73,6 +135,14 @@ type Scheduler interface {
 	UpdateAssetExpiration(ctx context.Context, cid string, time time.Time) error //perm:admin
 	// GetAssetReplicaInfos retrieves a list of asset replica information using the specified request parameters
 	GetAssetReplicaInfos(ctx context.Context, req types.ListReplicaInfosReq) (*types.ListReplicaInfosRsp, error) //perm:read
+	// GetReplicaScalingRecords retrieves the edge replica scaling decisions of the asset with the specified CID
+	GetReplicaScalingRecords(ctx context.Context, cid string, limit, offset int) ([]*types.ReplicaScalingRecord, error) //perm:read
+	// GetAssetEvents retrieves the state machine events of the asset with the specified CID, the latest first
+	GetAssetEvents(ctx context.Context, cid string, limit, offset int) ([]*types.AssetEvent, error) //perm:read
+	// GetReplicaMoveRecords retrieves the replica moves of the disk-pressure rebalancer from or to the node, all moves if the node id is empty
+	GetReplicaMoveRecords(ctx context.Context, nodeID string, limit, offset int) ([]*types.ReplicaMoveRecord, error) //perm:read
+	// SetReplicaRebalance enables or disables moving replicas off the nodes above the disk high watermark
+	SetReplicaRebalance(ctx context.Context, enable bool) error //perm:admin
 	// GetValidationResults retrieves a list of validation results with pagination using the specified time range, page number, and page size
 	GetValidationResults(ctx context.Context, startTime, endTime time.Time, pageNumber, pageSize int) (*types.ListValidationResultRsp, error) //perm:read
 	// SubmitUserProofsOfWork submits Proof of Work for User Asset Download
@@ -19,6 +19,8 @@ type AssetStruct struct {
 	Internal struct {
 		DeleteAsset func(p0 context.Context, p1 string) error `perm:"write"`
 
+		EncodeAssetShards func(p0 context.Context, p1 *types.ShardEncodeReq) error `perm:"write"`
+
 		GetAssetProgresses func(p0 context.Context, p1 []string) (*types.PullResult, error) `perm:"write"`
 
 		GetAssetStats func(p0 context.Context) (*types.AssetStats, error) `perm:"write"`
@@ -26,6 +28,10 @@ type AssetStruct struct {
 		GetPullingAssetInfo func(p0 context.Context) (*types.InProgressAsset, error) `perm:"write"`
 
 		PullAsset func(p0 context.Context, p1 string, p2 []*types.CandidateDownloadInfo) error `perm:"write"`
+
+		PullAssetFromURL func(p0 context.Context, p1 *types.URLPullReq) error `perm:"write"`
+
+		ReconstructAsset func(p0 context.Context, p1 *types.ShardReconstructReq) error `perm:"write"`
 	}
 }
 
@@ -167,12 +173,24 @@ type SchedulerStruct struct {
 
 		CheckNetworkConnectivity func(p0 context.Context, p1 string, p2 string) error `perm:"read"`
 
+		CreateS3AccessKey func(p0 context.Context, p1 *types.S3AccessKeyReq) (*types.S3AccessKey, error) `perm:"admin"`
+
+		CreateUploadTicket func(p0 context.Context, p1 int64, p2 *types.PullAssetReq) (*types.UploadInfo, error) `perm:"admin"`
+
+		CreateUserToken func(p0 context.Context, p1 string) (string, error) `perm:"admin"`
+
 		DeleteEdgeUpdateConfig func(p0 context.Context, p1 int) error `perm:"admin"`
 
 		EdgeConnect func(p0 context.Context, p1 *types.ConnectOptions) error `perm:"write"`
 
+		GetAssetEvents func(p0 context.Context, p1 string, p2 int, p3 int) ([]*types.AssetEvent, error) `perm:"read"`
+
+		GetAssetGroup func(p0 context.Context, p1 string) (*types.AssetGroup, error) `perm:"read"`
+
 		GetAssetListForBucket func(p0 context.Context, p1 string) ([]string, error) `perm:"write"`
 
+		GetAssetPullSources func(p0 context.Context, p1 string) ([]*types.CandidateDownloadInfo, error) `perm:"read"`
+
 		GetAssetRecord func(p0 context.Context, p1 string) (*types.AssetRecord, error) `perm:"read"`
 
 		GetAssetRecords func(p0 context.Context, p1 int, p2 int, p3 []string) ([]*types.AssetRecord, error) `perm:"read"`
@@ -189,18 +207,34 @@ type SchedulerStruct struct {
 
 		GetExternalAddress func(p0 context.Context) (string, error) `perm:"read"`
 
+		GetNodeDrainProgress func(p0 context.Context, p1 string) (*types.NodeDrainProgress, error) `perm:"read"`
+
 		GetNodeInfo func(p0 context.Context, p1 string) (types.NodeInfo, error) `perm:"read"`
 
-		GetNodeList func(p0 context.Context, p1 int, p2 int) (*types.ListNodesRsp, error) `perm:"read"`
+		GetNodeList func(p0 context.Context, p1 int, p2 int, p3 *types.LabelSelector) (*types.ListNodesRsp, error) `perm:"read"`
 
 		GetNodeNATType func(p0 context.Context, p1 string) (types.NatType, error) `perm:"write"`
 
+		GetNodeScores func(p0 context.Context, p1 types.NodeType) ([]*types.NodeScore, error) `perm:"read"`
+
 		GetOnlineNodeCount func(p0 context.Context, p1 types.NodeType) (int, error) `perm:"read"`
 
+		GetReplicaMoveRecords func(p0 context.Context, p1 string, p2 int, p3 int) ([]*types.ReplicaMoveRecord, error) `perm:"read"`
+
+		GetReplicaScalingRecords func(p0 context.Context, p1 string, p2 int, p3 int) ([]*types.ReplicaScalingRecord, error) `perm:"read"`
+
+		GetS3AccessKey func(p0 context.Context, p1 string) (*types.S3AccessKey, error) `perm:"write"`
+
+		GetS3Object func(p0 context.Context, p1 string, p2 string, p3 string) (*types.S3Object, error) `perm:"write"`
+
 		GetSchedulerPublicKey func(p0 context.Context) (string, error) `perm:"write"`
 
+		GetURLPullStatus func(p0 context.Context, p1 string) (*types.URLPullStatus, error) `perm:"read"`
+
 		GetValidationResults func(p0 context.Context, p1 time.Time, p2 time.Time, p3 int, p4 int) (*types.ListValidationResultRsp, error) `perm:"read"`
 
+		ListS3Objects func(p0 context.Context, p1 string, p2 *types.S3ListObjectsReq) ([]*types.S3Object, error) `perm:"write"`
+
 		NatPunch func(p0 context.Context, p1 *types.NatPunchReq) error `perm:"read"`
 
 		NodeExists func(p0 context.Context, p1 string) error `perm:"write"`
@@ -209,20 +243,56 @@ type SchedulerStruct struct {
 
 		NodeRemoveAssetResult func(p0 context.Context, p1 types.RemoveAssetResult) error `perm:"write"`
 
+		NodeShardEncodeResult func(p0 context.Context, p1 *types.ShardEncodeResult) error `perm:"write"`
+
+		NodeURLPullResult func(p0 context.Context, p1 *types.URLPullResult) error `perm:"write"`
+
+		NodeUploadResult func(p0 context.Context, p1 *types.UploadResult) error `perm:"write"`
+
 		NodeValidationResult func(p0 context.Context, p1 ValidationResult) error `perm:"write"`
 
+		PauseAsset func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		PlanAssetPlacement func(p0 context.Context, p1 []*types.PullAssetReq) (*types.AssetPlacementPlan, error) `perm:"admin"`
+
 		PullAsset func(p0 context.Context, p1 *types.PullAssetReq) error `perm:"admin"`
 
+		PullAssets func(p0 context.Context, p1 []*types.PullAssetReq) ([]*types.PullAssetResult, error) `perm:"admin"`
+
+		PutS3Object func(p0 context.Context, p1 string, p2 *types.S3Object) error `perm:"write"`
+
 		RePullFailedAssets func(p0 context.Context, p1 []types.AssetHash) error `perm:"admin"`
 
 		RegisterNode func(p0 context.Context, p1 string, p2 string, p3 types.NodeType) error `perm:"admin"`
 
+		RemoveAssetGroup func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		RemoveAssetKeyEnvelope func(p0 context.Context, p1 string, p2 string) error `perm:"admin"`
+
 		RemoveAssetRecord func(p0 context.Context, p1 string) error `perm:"admin"`
 
 		RemoveAssetReplica func(p0 context.Context, p1 string, p2 string) error `perm:"admin"`
 
+		RemoveS3AccessKey func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		RemoveS3Object func(p0 context.Context, p1 string, p2 string, p3 string) error `perm:"write"`
+
+		RestoreAsset func(p0 context.Context, p1 string, p2 time.Time) error `perm:"admin"`
+
+		ResumeAsset func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		SetAssetKeyEnvelopes func(p0 context.Context, p1 string, p2 []*types.AssetKeyEnvelope) error `perm:"admin"`
+
 		SetEdgeUpdateConfig func(p0 context.Context, p1 *EdgeUpdateConfig) error `perm:"admin"`
 
+		SetNodeLabels func(p0 context.Context, p1 string, p2 map[string]string) error `perm:"admin"`
+
+		SetNodeMaintenance func(p0 context.Context, p1 string, p2 types.NodeMaintenance) error `perm:"admin"`
+
+		SetNodeQuarantine func(p0 context.Context, p1 string, p2 time.Time, p3 string) error `perm:"admin"`
+
+		SetReplicaRebalance func(p0 context.Context, p1 bool) error `perm:"admin"`
+
 		SubmitUserProofsOfWork func(p0 context.Context, p1 []*types.UserProofOfWork) error `perm:"read"`
 
 		TriggerElection func(p0 context.Context) error `perm:"admin"`
@@ -231,6 +301,8 @@ type SchedulerStruct struct {
 
 		UpdateAssetExpiration func(p0 context.Context, p1 string, p2 time.Time) error `perm:"admin"`
 
+		UpdateAssetGroupExpiration func(p0 context.Context, p1 string, p2 time.Time) error `perm:"admin"`
+
 		UpdateNodePort func(p0 context.Context, p1 string, p2 string) error `perm:"admin"`
 
 		VerifyNodeAuthToken func(p0 context.Context, p1 string) ([]auth.Permission, error) `perm:"read"`
@@ -261,6 +333,17 @@ func (s *AssetStub) DeleteAsset(p0 context.Context, p1 string) error {
 	return ErrNotSupported
 }
 
+func (s *AssetStruct) EncodeAssetShards(p0 context.Context, p1 *types.ShardEncodeReq) error {
+	if s.Internal.EncodeAssetShards == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.EncodeAssetShards(p0, p1)
+}
+
+func (s *AssetStub) EncodeAssetShards(p0 context.Context, p1 *types.ShardEncodeReq) error {
+	return ErrNotSupported
+}
+
 func (s *AssetStruct) GetAssetProgresses(p0 context.Context, p1 []string) (*types.PullResult, error) {
 	if s.Internal.GetAssetProgresses == nil {
 		return nil, ErrNotSupported
@@ -305,6 +388,28 @@ func (s *AssetStub) PullAsset(p0 context.Context, p1 string, p2 []*types.Candida
 	return ErrNotSupported
 }
 
+func (s *AssetStruct) PullAssetFromURL(p0 context.Context, p1 *types.URLPullReq) error {
+	if s.Internal.PullAssetFromURL == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.PullAssetFromURL(p0, p1)
+}
+
+func (s *AssetStub) PullAssetFromURL(p0 context.Context, p1 *types.URLPullReq) error {
+	return ErrNotSupported
+}
+
+func (s *AssetStruct) ReconstructAsset(p0 context.Context, p1 *types.ShardReconstructReq) error {
+	if s.Internal.ReconstructAsset == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ReconstructAsset(p0, p1)
+}
+
+func (s *AssetStub) ReconstructAsset(p0 context.Context, p1 *types.ShardReconstructReq) error {
+	return ErrNotSupported
+}
+
 func (s *CandidateStruct) GetBlocksWithAssetCID(p0 context.Context, p1 string, p2 int64, p3 int) (map[int]string, error) {
 	if s.Internal.GetBlocksWithAssetCID == nil {
 		return *new(map[int]string), ErrNotSupported
@@ -569,6 +674,39 @@ func (s *SchedulerStub) CheckNetworkConnectivity(p0 context.Context, p1 string,
 	return ErrNotSupported
 }
 
+func (s *SchedulerStruct) CreateS3AccessKey(p0 context.Context, p1 *types.S3AccessKeyReq) (*types.S3AccessKey, error) {
+	if s.Internal.CreateS3AccessKey == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.CreateS3AccessKey(p0, p1)
+}
+
+func (s *SchedulerStub) CreateS3AccessKey(p0 context.Context, p1 *types.S3AccessKeyReq) (*types.S3AccessKey, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *SchedulerStruct) CreateUploadTicket(p0 context.Context, p1 int64, p2 *types.PullAssetReq) (*types.UploadInfo, error) {
+	if s.Internal.CreateUploadTicket == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.CreateUploadTicket(p0, p1, p2)
+}
+
+func (s *SchedulerStub) CreateUploadTicket(p0 context.Context, p1 int64, p2 *types.PullAssetReq) (*types.UploadInfo, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *SchedulerStruct) CreateUserToken(p0 context.Context, p1 string) (string, error) {
+	if s.Internal.CreateUserToken == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.CreateUserToken(p0, p1)
+}
+
+func (s *SchedulerStub) CreateUserToken(p0 context.Context, p1 string) (string, error) {
+	return "", ErrNotSupported
+}
+
 func (s *SchedulerStruct) DeleteEdgeUpdateConfig(p0 context.Context, p1 int) error {
 	if s.Internal.DeleteEdgeUpdateConfig == nil {
 		return ErrNotSupported
@@ -591,6 +729,28 @@ func (s *SchedulerStub) EdgeConnect(p0 context.Context, p1 *types.ConnectOptions
 	return ErrNotSupported
 }
 
+func (s *SchedulerStruct) GetAssetEvents(p0 context.Context, p1 string, p2 int, p3 int) ([]*types.AssetEvent, error) {
+	if s.Internal.GetAssetEvents == nil {
+		return *new([]*types.AssetEvent), ErrNotSupported
+	}
+	return s.Internal.GetAssetEvents(p0, p1, p2, p3)
+}
+
+func (s *SchedulerStub) GetAssetEvents(p0 context.Context, p1 string, p2 int, p3 int) ([]*types.AssetEvent, error) {
+	return *new([]*types.AssetEvent), ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetAssetGroup(p0 context.Context, p1 string) (*types.AssetGroup, error) {
+	if s.Internal.GetAssetGroup == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetAssetGroup(p0, p1)
+}
+
+func (s *SchedulerStub) GetAssetGroup(p0 context.Context, p1 string) (*types.AssetGroup, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *SchedulerStruct) GetAssetListForBucket(p0 context.Context, p1 string) ([]string, error) {
 	if s.Internal.GetAssetListForBucket == nil {
 		return *new([]string), ErrNotSupported
@@ -602,6 +762,17 @@ func (s *SchedulerStub) GetAssetListForBucket(p0 context.Context, p1 string) ([]
 	return *new([]string), ErrNotSupported
 }
 
+func (s *SchedulerStruct) GetAssetPullSources(p0 context.Context, p1 string) ([]*types.CandidateDownloadInfo, error) {
+	if s.Internal.GetAssetPullSources == nil {
+		return *new([]*types.CandidateDownloadInfo), ErrNotSupported
+	}
+	return s.Internal.GetAssetPullSources(p0, p1)
+}
+
+func (s *SchedulerStub) GetAssetPullSources(p0 context.Context, p1 string) ([]*types.CandidateDownloadInfo, error) {
+	return *new([]*types.CandidateDownloadInfo), ErrNotSupported
+}
+
 func (s *SchedulerStruct) GetAssetRecord(p0 context.Context, p1 string) (*types.AssetRecord, error) {
 	if s.Internal.GetAssetRecord == nil {
 		return nil, ErrNotSupported
@@ -690,6 +861,17 @@ func (s *SchedulerStub) GetExternalAddress(p0 context.Context) (string, error) {
 	return "", ErrNotSupported
 }
 
+func (s *SchedulerStruct) GetNodeDrainProgress(p0 context.Context, p1 string) (*types.NodeDrainProgress, error) {
+	if s.Internal.GetNodeDrainProgress == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeDrainProgress(p0, p1)
+}
+
+func (s *SchedulerStub) GetNodeDrainProgress(p0 context.Context, p1 string) (*types.NodeDrainProgress, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *SchedulerStruct) GetNodeInfo(p0 context.Context, p1 string) (types.NodeInfo, error) {
 	if s.Internal.GetNodeInfo == nil {
 		return *new(types.NodeInfo), ErrNotSupported
@@ -701,14 +883,14 @@ func (s *SchedulerStub) GetNodeInfo(p0 context.Context, p1 string) (types.NodeIn
 	return *new(types.NodeInfo), ErrNotSupported
 }
 
-func (s *SchedulerStruct) GetNodeList(p0 context.Context, p1 int, p2 int) (*types.ListNodesRsp, error) {
+func (s *SchedulerStruct) GetNodeList(p0 context.Context, p1 int, p2 int, p3 *types.LabelSelector) (*types.ListNodesRsp, error) {
 	if s.Internal.GetNodeList == nil {
 		return nil, ErrNotSupported
 	}
-	return s.Internal.GetNodeList(p0, p1, p2)
+	return s.Internal.GetNodeList(p0, p1, p2, p3)
 }
 
-func (s *SchedulerStub) GetNodeList(p0 context.Context, p1 int, p2 int) (*types.ListNodesRsp, error) {
+func (s *SchedulerStub) GetNodeList(p0 context.Context, p1 int, p2 int, p3 *types.LabelSelector) (*types.ListNodesRsp, error) {
 	return nil, ErrNotSupported
 }
 
@@ -723,6 +905,17 @@ func (s *SchedulerStub) GetNodeNATType(p0 context.Context, p1 string) (types.Nat
 	return *new(types.NatType), ErrNotSupported
 }
 
+func (s *SchedulerStruct) GetNodeScores(p0 context.Context, p1 types.NodeType) ([]*types.NodeScore, error) {
+	if s.Internal.GetNodeScores == nil {
+		return *new([]*types.NodeScore), ErrNotSupported
+	}
+	return s.Internal.GetNodeScores(p0, p1)
+}
+
+func (s *SchedulerStub) GetNodeScores(p0 context.Context, p1 types.NodeType) ([]*types.NodeScore, error) {
+	return *new([]*types.NodeScore), ErrNotSupported
+}
+
 func (s *SchedulerStruct) GetOnlineNodeCount(p0 context.Context, p1 types.NodeType) (int, error) {
 	if s.Internal.GetOnlineNodeCount == nil {
 		return 0, ErrNotSupported
@@ -734,6 +927,50 @@ func (s *SchedulerStub) GetOnlineNodeCount(p0 context.Context, p1 types.NodeType
 	return 0, ErrNotSupported
 }
 
+func (s *SchedulerStruct) GetReplicaMoveRecords(p0 context.Context, p1 string, p2 int, p3 int) ([]*types.ReplicaMoveRecord, error) {
+	if s.Internal.GetReplicaMoveRecords == nil {
+		return *new([]*types.ReplicaMoveRecord), ErrNotSupported
+	}
+	return s.Internal.GetReplicaMoveRecords(p0, p1, p2, p3)
+}
+
+func (s *SchedulerStub) GetReplicaMoveRecords(p0 context.Context, p1 string, p2 int, p3 int) ([]*types.ReplicaMoveRecord, error) {
+	return *new([]*types.ReplicaMoveRecord), ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetReplicaScalingRecords(p0 context.Context, p1 string, p2 int, p3 int) ([]*types.ReplicaScalingRecord, error) {
+	if s.Internal.GetReplicaScalingRecords == nil {
+		return *new([]*types.ReplicaScalingRecord), ErrNotSupported
+	}
+	return s.Internal.GetReplicaScalingRecords(p0, p1, p2, p3)
+}
+
+func (s *SchedulerStub) GetReplicaScalingRecords(p0 context.Context, p1 string, p2 int, p3 int) ([]*types.ReplicaScalingRecord, error) {
+	return *new([]*types.ReplicaScalingRecord), ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetS3AccessKey(p0 context.Context, p1 string) (*types.S3AccessKey, error) {
+	if s.Internal.GetS3AccessKey == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetS3AccessKey(p0, p1)
+}
+
+func (s *SchedulerStub) GetS3AccessKey(p0 context.Context, p1 string) (*types.S3AccessKey, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetS3Object(p0 context.Context, p1 string, p2 string, p3 string) (*types.S3Object, error) {
+	if s.Internal.GetS3Object == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetS3Object(p0, p1, p2, p3)
+}
+
+func (s *SchedulerStub) GetS3Object(p0 context.Context, p1 string, p2 string, p3 string) (*types.S3Object, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *SchedulerStruct) GetSchedulerPublicKey(p0 context.Context) (string, error) {
 	if s.Internal.GetSchedulerPublicKey == nil {
 		return "", ErrNotSupported
@@ -745,6 +982,17 @@ func (s *SchedulerStub) GetSchedulerPublicKey(p0 context.Context) (string, error
 	return "", ErrNotSupported
 }
 
+func (s *SchedulerStruct) GetURLPullStatus(p0 context.Context, p1 string) (*types.URLPullStatus, error) {
+	if s.Internal.GetURLPullStatus == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetURLPullStatus(p0, p1)
+}
+
+func (s *SchedulerStub) GetURLPullStatus(p0 context.Context, p1 string) (*types.URLPullStatus, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *SchedulerStruct) GetValidationResults(p0 context.Context, p1 time.Time, p2 time.Time, p3 int, p4 int) (*types.ListValidationResultRsp, error) {
 	if s.Internal.GetValidationResults == nil {
 		return nil, ErrNotSupported
@@ -756,6 +1004,17 @@ func (s *SchedulerStub) GetValidationResults(p0 context.Context, p1 time.Time, p
 	return nil, ErrNotSupported
 }
 
+func (s *SchedulerStruct) ListS3Objects(p0 context.Context, p1 string, p2 *types.S3ListObjectsReq) ([]*types.S3Object, error) {
+	if s.Internal.ListS3Objects == nil {
+		return *new([]*types.S3Object), ErrNotSupported
+	}
+	return s.Internal.ListS3Objects(p0, p1, p2)
+}
+
+func (s *SchedulerStub) ListS3Objects(p0 context.Context, p1 string, p2 *types.S3ListObjectsReq) ([]*types.S3Object, error) {
+	return *new([]*types.S3Object), ErrNotSupported
+}
+
 func (s *SchedulerStruct) NatPunch(p0 context.Context, p1 *types.NatPunchReq) error {
 	if s.Internal.NatPunch == nil {
 		return ErrNotSupported
@@ -800,6 +1059,39 @@ func (s *SchedulerStub) NodeRemoveAssetResult(p0 context.Context, p1 types.Remov
 	return ErrNotSupported
 }
 
+func (s *SchedulerStruct) NodeShardEncodeResult(p0 context.Context, p1 *types.ShardEncodeResult) error {
+	if s.Internal.NodeShardEncodeResult == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.NodeShardEncodeResult(p0, p1)
+}
+
+func (s *SchedulerStub) NodeShardEncodeResult(p0 context.Context, p1 *types.ShardEncodeResult) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) NodeURLPullResult(p0 context.Context, p1 *types.URLPullResult) error {
+	if s.Internal.NodeURLPullResult == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.NodeURLPullResult(p0, p1)
+}
+
+func (s *SchedulerStub) NodeURLPullResult(p0 context.Context, p1 *types.URLPullResult) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) NodeUploadResult(p0 context.Context, p1 *types.UploadResult) error {
+	if s.Internal.NodeUploadResult == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.NodeUploadResult(p0, p1)
+}
+
+func (s *SchedulerStub) NodeUploadResult(p0 context.Context, p1 *types.UploadResult) error {
+	return ErrNotSupported
+}
+
 func (s *SchedulerStruct) NodeValidationResult(p0 context.Context, p1 ValidationResult) error {
 	if s.Internal.NodeValidationResult == nil {
 		return ErrNotSupported
@@ -811,6 +1103,28 @@ func (s *SchedulerStub) NodeValidationResult(p0 context.Context, p1 ValidationRe
 	return ErrNotSupported
 }
 
+func (s *SchedulerStruct) PauseAsset(p0 context.Context, p1 string) error {
+	if s.Internal.PauseAsset == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.PauseAsset(p0, p1)
+}
+
+func (s *SchedulerStub) PauseAsset(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) PlanAssetPlacement(p0 context.Context, p1 []*types.PullAssetReq) (*types.AssetPlacementPlan, error) {
+	if s.Internal.PlanAssetPlacement == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.PlanAssetPlacement(p0, p1)
+}
+
+func (s *SchedulerStub) PlanAssetPlacement(p0 context.Context, p1 []*types.PullAssetReq) (*types.AssetPlacementPlan, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *SchedulerStruct) PullAsset(p0 context.Context, p1 *types.PullAssetReq) error {
 	if s.Internal.PullAsset == nil {
 		return ErrNotSupported
@@ -822,6 +1136,28 @@ func (s *SchedulerStub) PullAsset(p0 context.Context, p1 *types.PullAssetReq) er
 	return ErrNotSupported
 }
 
+func (s *SchedulerStruct) PullAssets(p0 context.Context, p1 []*types.PullAssetReq) ([]*types.PullAssetResult, error) {
+	if s.Internal.PullAssets == nil {
+		return *new([]*types.PullAssetResult), ErrNotSupported
+	}
+	return s.Internal.PullAssets(p0, p1)
+}
+
+func (s *SchedulerStub) PullAssets(p0 context.Context, p1 []*types.PullAssetReq) ([]*types.PullAssetResult, error) {
+	return *new([]*types.PullAssetResult), ErrNotSupported
+}
+
+func (s *SchedulerStruct) PutS3Object(p0 context.Context, p1 string, p2 *types.S3Object) error {
+	if s.Internal.PutS3Object == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.PutS3Object(p0, p1, p2)
+}
+
+func (s *SchedulerStub) PutS3Object(p0 context.Context, p1 string, p2 *types.S3Object) error {
+	return ErrNotSupported
+}
+
 func (s *SchedulerStruct) RePullFailedAssets(p0 context.Context, p1 []types.AssetHash) error {
 	if s.Internal.RePullFailedAssets == nil {
 		return ErrNotSupported
@@ -844,6 +1180,28 @@ func (s *SchedulerStub) RegisterNode(p0 context.Context, p1 string, p2 string, p
 	return ErrNotSupported
 }
 
+func (s *SchedulerStruct) RemoveAssetGroup(p0 context.Context, p1 string) error {
+	if s.Internal.RemoveAssetGroup == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveAssetGroup(p0, p1)
+}
+
+func (s *SchedulerStub) RemoveAssetGroup(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) RemoveAssetKeyEnvelope(p0 context.Context, p1 string, p2 string) error {
+	if s.Internal.RemoveAssetKeyEnvelope == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveAssetKeyEnvelope(p0, p1, p2)
+}
+
+func (s *SchedulerStub) RemoveAssetKeyEnvelope(p0 context.Context, p1 string, p2 string) error {
+	return ErrNotSupported
+}
+
 func (s *SchedulerStruct) RemoveAssetRecord(p0 context.Context, p1 string) error {
 	if s.Internal.RemoveAssetRecord == nil {
 		return ErrNotSupported
@@ -866,6 +1224,61 @@ func (s *SchedulerStub) RemoveAssetReplica(p0 context.Context, p1 string, p2 str
 	return ErrNotSupported
 }
 
+func (s *SchedulerStruct) RemoveS3AccessKey(p0 context.Context, p1 string) error {
+	if s.Internal.RemoveS3AccessKey == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveS3AccessKey(p0, p1)
+}
+
+func (s *SchedulerStub) RemoveS3AccessKey(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) RemoveS3Object(p0 context.Context, p1 string, p2 string, p3 string) error {
+	if s.Internal.RemoveS3Object == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveS3Object(p0, p1, p2, p3)
+}
+
+func (s *SchedulerStub) RemoveS3Object(p0 context.Context, p1 string, p2 string, p3 string) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) RestoreAsset(p0 context.Context, p1 string, p2 time.Time) error {
+	if s.Internal.RestoreAsset == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RestoreAsset(p0, p1, p2)
+}
+
+func (s *SchedulerStub) RestoreAsset(p0 context.Context, p1 string, p2 time.Time) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) ResumeAsset(p0 context.Context, p1 string) error {
+	if s.Internal.ResumeAsset == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ResumeAsset(p0, p1)
+}
+
+func (s *SchedulerStub) ResumeAsset(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) SetAssetKeyEnvelopes(p0 context.Context, p1 string, p2 []*types.AssetKeyEnvelope) error {
+	if s.Internal.SetAssetKeyEnvelopes == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetAssetKeyEnvelopes(p0, p1, p2)
+}
+
+func (s *SchedulerStub) SetAssetKeyEnvelopes(p0 context.Context, p1 string, p2 []*types.AssetKeyEnvelope) error {
+	return ErrNotSupported
+}
+
 func (s *SchedulerStruct) SetEdgeUpdateConfig(p0 context.Context, p1 *EdgeUpdateConfig) error {
 	if s.Internal.SetEdgeUpdateConfig == nil {
 		return ErrNotSupported
@@ -877,6 +1290,50 @@ func (s *SchedulerStub) SetEdgeUpdateConfig(p0 context.Context, p1 *EdgeUpdateCo
 	return ErrNotSupported
 }
 
+func (s *SchedulerStruct) SetNodeLabels(p0 context.Context, p1 string, p2 map[string]string) error {
+	if s.Internal.SetNodeLabels == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetNodeLabels(p0, p1, p2)
+}
+
+func (s *SchedulerStub) SetNodeLabels(p0 context.Context, p1 string, p2 map[string]string) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) SetNodeMaintenance(p0 context.Context, p1 string, p2 types.NodeMaintenance) error {
+	if s.Internal.SetNodeMaintenance == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetNodeMaintenance(p0, p1, p2)
+}
+
+func (s *SchedulerStub) SetNodeMaintenance(p0 context.Context, p1 string, p2 types.NodeMaintenance) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) SetNodeQuarantine(p0 context.Context, p1 string, p2 time.Time, p3 string) error {
+	if s.Internal.SetNodeQuarantine == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetNodeQuarantine(p0, p1, p2, p3)
+}
+
+func (s *SchedulerStub) SetNodeQuarantine(p0 context.Context, p1 string, p2 time.Time, p3 string) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) SetReplicaRebalance(p0 context.Context, p1 bool) error {
+	if s.Internal.SetReplicaRebalance == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetReplicaRebalance(p0, p1)
+}
+
+func (s *SchedulerStub) SetReplicaRebalance(p0 context.Context, p1 bool) error {
+	return ErrNotSupported
+}
+
 func (s *SchedulerStruct) SubmitUserProofsOfWork(p0 context.Context, p1 []*types.UserProofOfWork) error {
 	if s.Internal.SubmitUserProofsOfWork == nil {
 		return ErrNotSupported
@@ -921,6 +1378,17 @@ func (s *SchedulerStub) UpdateAssetExpiration(p0 context.Context, p1 string, p2
 	return ErrNotSupported
 }
 
+func (s *SchedulerStruct) UpdateAssetGroupExpiration(p0 context.Context, p1 string, p2 time.Time) error {
+	if s.Internal.UpdateAssetGroupExpiration == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.UpdateAssetGroupExpiration(p0, p1, p2)
+}
+
+func (s *SchedulerStub) UpdateAssetGroupExpiration(p0 context.Context, p1 string, p2 time.Time) error {
+	return ErrNotSupported
+}
+
 func (s *SchedulerStruct) UpdateNodePort(p0 context.Context, p1 string, p2 string) error {
 	if s.Internal.UpdateNodePort == nil {
 		return ErrNotSupported
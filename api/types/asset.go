@@ -15,6 +15,19 @@ type AssetPullProgress struct {
 	DoneBlocksCount int
 	Size            int64
 	DoneSize        int64
+	// Sources is the health of the download sources, reported by the nodes that track it
+	Sources []*DownloadSourceStats
+}
+
+// DownloadSourceStats represents the health of a download source used by an asset pull
+type DownloadSourceStats struct {
+	URL        string
+	Requests   int64
+	Failures   int64
+	ErrorRate  float64 // recent error rate
+	Latency    int64   // recent latency in milliseconds
+	Throughput int64   // recent download speed in bytes per second
+	Weight     float64 // share of the blocks requested from the source
 }
 
 // PullResult contains information about the result of a data pull
@@ -44,9 +57,25 @@ type AssetRecord struct {
 	State                 string          `db:"state"`
 	NeedCandidateReplicas int64           `db:"candidate_replicas"`
 	ServerID              dtypes.ServerID `db:"scheduler_sid"`
+	Areas                 string          `db:"areas"`
+	MinEdgeReplicas       int64           `db:"min_edge_replicas"`
+	MaxEdgeReplicas       int64           `db:"max_edge_replicas"`
+	Priority              int64           `db:"priority"`
+	GroupID               string          `db:"group_id"`
+	IncludeLabels         string          `db:"include_labels"`
+	ExcludeLabels         string          `db:"exclude_labels"`
+	DeleteTime            time.Time       `db:"delete_time"` // the time the asset entered the pending delete state
+	PausedFrom            string          `db:"paused_from"` // the state to return to when the paused asset is resumed
+	PullTimeout           int64           `db:"pull_timeout"`
+	RetryInterval         int64           `db:"retry_interval"`
+	MaxRetries            int64           `db:"max_retries"`
+	SelectAttempts        int64           `db:"select_attempts"`
+	DataShards            int64           `db:"data_shards"` // the edges hold the shards instead of full replicas if it is not 0
+	ParityShards          int64           `db:"parity_shards"`
 
-	ReplicaInfos []*ReplicaInfo
-	EdgeReplica  int64
+	ReplicaInfos  []*ReplicaInfo
+	EdgeReplica   int64
+	QueuePosition int // position in the pull queue, starting from 1, only for the queued asset
 }
 
 // ReplicaInfo represents information about an asset replica
@@ -57,6 +86,7 @@ type ReplicaInfo struct {
 	IsCandidate bool          `db:"is_candidate"`
 	EndTime     time.Time     `db:"end_time"`
 	DoneSize    int64         `db:"done_size"`
+	FailMsg     string        `db:"fail_msg"` // the reason reported by the node if the pulling failed
 }
 
 // PullAssetReq represents a request to pull an asset to Titan
@@ -67,6 +97,152 @@ type PullAssetReq struct {
 	Replicas   int64
 	ServerID   string
 	Expiration time.Time
+	// Areas preferred for the replicas, e.g. "CN-GD", matched as a prefix of the node area
+	Areas []string
+	// Bounds of the edge replicas scaled by download demand, scaling is disabled if MaxReplicas is 0
+	MinReplicas int64
+	MaxReplicas int64
+	// Priority in the pull queue, higher is first
+	Priority int64
+	// GroupID groups the assets pulled together, e.g. the assets of a campaign
+	GroupID string
+	// Labels selects the edges and candidates of the replicas by their labels
+	Labels *LabelSelector
+	// Pull policy of the asset, the scheduler defaults are used for the zero values
+	PullTimeout    int64 // seconds without pull progress before the node pulls are failed
+	RetryInterval  int64 // seconds to wait before the failed pulls are retried
+	MaxRetries     int64 // maximum number of retries of the failed pulls
+	SelectAttempts int64 // attempts per replica to select a random node
+	// URL of the asset origin, the asset is downloaded by a seed candidate and its CID is built from the file if CID is empty
+	URL string
+	// UnixFS options to build the file of the URL, the candidate defaults are used if it is nil
+	UnixFS *UnixFSOptions
+	// ErasureCoding stores the asset on the edges in shards instead of full replicas, Replicas is ignored if it is set
+	ErasureCoding *ErasureCoding
+}
+
+// ErasureCoding describes the Reed-Solomon coding of an asset, the asset is split into DataShards shards
+// and ParityShards shards are added, it can be rebuilt from any DataShards of them
+type ErasureCoding struct {
+	DataShards   int64
+	ParityShards int64
+}
+
+// UnixFSOptions describes how a file is built into a UnixFS dag, the same file and options always build the same root CID
+type UnixFSOptions struct {
+	// Chunker splits the file into the leaves, e.g. "size-1048576" or "rabin-262144-524288-1048576"
+	Chunker string
+	// Layout of the dag, "balanced" or "trickle"
+	Layout string
+	// RawLeaves stores the leaves as raw blocks instead of UnixFS nodes
+	RawLeaves bool
+	// CIDVersion of the dag nodes, 0 or 1
+	CIDVersion int
+}
+
+// URLPullReq represents a request to a candidate to build an asset from its origin URL
+type URLPullReq struct {
+	ID     string
+	URL    string
+	UnixFS *UnixFSOptions
+}
+
+// URLPullResult represents the result of a candidate building an asset from its origin URL
+type URLPullResult struct {
+	ID     string
+	URL    string
+	CID    string // root CID of the built asset
+	Size   int64  // size of the downloaded file
+	Blocks int64
+	Err    string // empty if the asset is built
+}
+
+// URLPullStatus represents the status of an asset being built from its origin URL by a seed candidate
+type URLPullStatus struct {
+	ID        string
+	NodeID    string
+	URL       string
+	Status    ReplicaStatus // pulling until the candidate reports the result
+	CID       string        // root CID of the built asset
+	Msg       string        // error of the failed pull
+	StartTime time.Time
+}
+
+// AssetShard represents a shard of an erasure coded asset, the shard is stored as an asset of its own
+type AssetShard struct {
+	Hash      string `db:"hash"` // hash of the erasure coded asset
+	Index     int64  `db:"shard_index"`
+	CID       string `db:"cid"`
+	ShardHash string `db:"shard_hash"`
+	Size      int64  `db:"size"`
+	Blocks    int64  `db:"blocks"`
+}
+
+// ShardEncodeReq represents a request to a candidate to encode the asset it holds into shards
+type ShardEncodeReq struct {
+	CID          string
+	DataShards   int64
+	ParityShards int64
+}
+
+// ShardEncodeResult represents the result of a candidate encoding an asset into shards
+type ShardEncodeResult struct {
+	CID    string
+	Shards []*AssetShard // the shards in the order of their index
+	Err    string        // empty if the asset is encoded
+}
+
+// ShardReconstructReq represents a request to a candidate to rebuild an erasure coded asset from its shards
+type ShardReconstructReq struct {
+	CID          string
+	DataShards   int64
+	ParityShards int64
+	Shards       []*ShardSource
+}
+
+// ShardSource represents the nodes a shard can be downloaded from
+type ShardSource struct {
+	Index   int64
+	CID     string
+	Sources []*CandidateDownloadInfo
+}
+
+// PullAssetResult represents the result of an asset in a batch pull
+type PullAssetResult struct {
+	CID    string // empty if the asset is pulled from its origin URL
+	URL    string
+	PullID string // id of the url pull, its status is retrieved by GetURLPullStatus
+	Err    string // empty if the asset pull task is created
+}
+
+// AssetGroup represents the asset records of a group
+type AssetGroup struct {
+	GroupID   string
+	TotalSize int64
+	Assets    []*AssetRecord
+}
+
+// AssetPlacement represents the dry-run placement of the replicas of an asset
+type AssetPlacement struct {
+	CID            string
+	Err            string // the asset can not be placed, e.g. the request is invalid
+	Size           int64  // 0 if the asset is not pulled yet
+	CandidateNodes []string
+	EdgeNodes      []string
+	// Shortfalls describe the replicas that can not be placed
+	Shortfalls []string
+	// AreaReplicas is the number of chosen nodes in each area
+	AreaReplicas map[string]int
+}
+
+// AssetPlacementPlan represents the dry-run placement of a batch of assets
+type AssetPlacementPlan struct {
+	Assets []*AssetPlacement
+	// EstimatedSize is the bytes pulled by the chosen nodes, the assets not pulled yet are not counted
+	EstimatedSize     int64
+	UnknownSizeAssets int
+	// AreaReplicas is the number of chosen nodes in each area
+	AreaReplicas map[string]int
 }
 
 // ReplicaStatus represents the status of a replica pull
@@ -123,6 +299,68 @@ type ListReplicaInfosRsp struct {
 	Total    int64          `json:"total"`
 }
 
+// ReplicaScalingRecord represents a scaling decision of the asset edge replicas
+type ReplicaScalingRecord struct {
+	Hash         string    `db:"hash"`
+	CID          string    `db:"cid"`
+	FromReplicas int64     `db:"from_replicas"`
+	ToReplicas   int64     `db:"to_replicas"`
+	Requests     int64     `db:"requests"`
+	Downloads    int64     `db:"downloads"`
+	DownloadSize int64     `db:"download_size"`
+	Msg          string    `db:"msg"`
+	CreatedTime  time.Time `db:"created_time"`
+}
+
+// ReplicaMoveRecord represents a move of an asset replica from a node under disk pressure to an under-used node
+type ReplicaMoveRecord struct {
+	ID          string        `db:"id"`
+	Hash        string        `db:"hash"`
+	CID         string        `db:"cid"`
+	FromNodeID  string        `db:"from_node_id"`
+	ToNodeID    string        `db:"to_node_id"`
+	Size        int64         `db:"size"`
+	Status      ReplicaStatus `db:"status"`
+	Msg         string        `db:"msg"`
+	ServerID    string        `db:"scheduler_sid"`
+	CreatedTime time.Time     `db:"created_time"`
+	EndTime     time.Time     `db:"end_time"`
+}
+
+// AssetEvent represents an event applied to the asset in the pulling state machine
+type AssetEvent struct {
+	Hash        string    `db:"hash"`
+	CID         string    `db:"cid"`
+	Event       string    `db:"event"`
+	FromState   string    `db:"from_state"`
+	ToState     string    `db:"to_state"`
+	Msg         string    `db:"msg"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// AssetKeyEnvelope is the data key of a private asset wrapped with the public key of an authorised user,
+// the scheduler only keeps the wrapped keys and the content is encrypted by the client before it is chunked
+type AssetKeyEnvelope struct {
+	Hash        string    `db:"hash"`
+	CID         string    `db:"cid"`
+	UserID      string    `db:"user_id"`
+	WrappedKey  []byte    `db:"wrapped_key"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// SeedTask is an asset being put on a seed candidate outside of the pulling, e.g. built from its origin url or uploaded,
+// the asset pull task is created from the request when the candidate reports the root cid of the asset
+type SeedTask struct {
+	ID         string
+	NodeID     string
+	Request    *PullAssetReq
+	StartTime  time.Time
+	Expiration time.Time // the result reported after the time is dropped
+	Status     ReplicaStatus
+	CID        string // root CID reported by the candidate
+	Msg        string // error reported by the candidate
+}
+
 // AssetStats contains statistics about assets
 type AssetStats struct {
 	TotalAssetCount     int
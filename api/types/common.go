@@ -29,31 +29,35 @@ type NodeInfo struct {
 	MemoryUsage  float64  `json:"memory_usage" form:"memoryUsage" gorm:"column:memory_usage;comment:;"`
 	IsOnline     bool     `json:"is_online" form:"isOnline" gorm:"column:is_online;comment:;"`
 
-	DiskUsage       float64         `json:"disk_usage" form:"diskUsage" gorm:"column:disk_usage;comment:;" db:"disk_usage"`
-	Blocks          int             `json:"blocks" form:"blockCount" gorm:"column:blocks;comment:;" db:"blocks"`
-	BandwidthUp     float64         `json:"bandwidth_up" db:"bandwidth_up"`
-	BandwidthDown   float64         `json:"bandwidth_down" db:"bandwidth_down"`
-	NATType         string          `json:"nat_type" form:"natType" gorm:"column:nat_type;comment:;" db:"nat_type"`
-	DiskSpace       float64         `json:"disk_space" form:"diskSpace" gorm:"column:disk_space;comment:;" db:"disk_space"`
-	SystemVersion   string          `json:"system_version" form:"systemVersion" gorm:"column:system_version;comment:;" db:"system_version"`
-	DiskType        string          `json:"disk_type" form:"diskType" gorm:"column:disk_type;comment:;" db:"disk_type"`
-	IoSystem        string          `json:"io_system" form:"ioSystem" gorm:"column:io_system;comment:;" db:"io_system"`
-	Latitude        float64         `json:"latitude" db:"latitude"`
-	Longitude       float64         `json:"longitude" db:"longitude"`
-	NodeName        string          `json:"node_name" form:"nodeName" gorm:"column:node_name;comment:;" db:"node_name"`
-	Memory          float64         `json:"memory" form:"memory" gorm:"column:memory;comment:;" db:"memory"`
-	CPUCores        int             `json:"cpu_cores" form:"cpuCores" gorm:"column:cpu_cores;comment:;" db:"cpu_cores"`
-	ProductType     string          `json:"product_type" form:"productType" gorm:"column:product_type;comment:;" db:"product_type"`
-	MacLocation     string          `json:"mac_location" form:"macLocation" gorm:"column:mac_location;comment:;" db:"mac_location"`
-	OnlineDuration  int             `json:"online_duration" form:"onlineDuration" db:"online_duration"`
-	Profit          float64         `json:"profit" db:"profit"`
-	DownloadTraffic float64         `json:"download_traffic" db:"download_traffic"`
-	UploadTraffic   float64         `json:"upload_traffic" db:"upload_traffic"`
-	DownloadBlocks  int             `json:"download_blocks" form:"downloadCount" gorm:"column:download_blocks;comment:;" db:"download_blocks"`
-	PortMapping     string          `db:"port_mapping"`
-	LastSeen        time.Time       `db:"last_seen"`
-	IsQuitted       bool            `db:"quitted"`
-	SchedulerID     dtypes.ServerID `db:"scheduler_sid"`
+	DiskUsage       float64           `json:"disk_usage" form:"diskUsage" gorm:"column:disk_usage;comment:;" db:"disk_usage"`
+	Blocks          int               `json:"blocks" form:"blockCount" gorm:"column:blocks;comment:;" db:"blocks"`
+	BandwidthUp     float64           `json:"bandwidth_up" db:"bandwidth_up"`
+	BandwidthDown   float64           `json:"bandwidth_down" db:"bandwidth_down"`
+	NATType         string            `json:"nat_type" form:"natType" gorm:"column:nat_type;comment:;" db:"nat_type"`
+	DiskSpace       float64           `json:"disk_space" form:"diskSpace" gorm:"column:disk_space;comment:;" db:"disk_space"`
+	SystemVersion   string            `json:"system_version" form:"systemVersion" gorm:"column:system_version;comment:;" db:"system_version"`
+	DiskType        string            `json:"disk_type" form:"diskType" gorm:"column:disk_type;comment:;" db:"disk_type"`
+	IoSystem        string            `json:"io_system" form:"ioSystem" gorm:"column:io_system;comment:;" db:"io_system"`
+	Latitude        float64           `json:"latitude" db:"latitude"`
+	Longitude       float64           `json:"longitude" db:"longitude"`
+	NodeName        string            `json:"node_name" form:"nodeName" gorm:"column:node_name;comment:;" db:"node_name"`
+	Memory          float64           `json:"memory" form:"memory" gorm:"column:memory;comment:;" db:"memory"`
+	CPUCores        int               `json:"cpu_cores" form:"cpuCores" gorm:"column:cpu_cores;comment:;" db:"cpu_cores"`
+	ProductType     string            `json:"product_type" form:"productType" gorm:"column:product_type;comment:;" db:"product_type"`
+	MacLocation     string            `json:"mac_location" form:"macLocation" gorm:"column:mac_location;comment:;" db:"mac_location"`
+	OnlineDuration  int               `json:"online_duration" form:"onlineDuration" db:"online_duration"`
+	Profit          float64           `json:"profit" db:"profit"`
+	DownloadTraffic float64           `json:"download_traffic" db:"download_traffic"`
+	UploadTraffic   float64           `json:"upload_traffic" db:"upload_traffic"`
+	DownloadBlocks  int               `json:"download_blocks" form:"downloadCount" gorm:"column:download_blocks;comment:;" db:"download_blocks"`
+	PortMapping     string            `db:"port_mapping"`
+	LastSeen        time.Time         `db:"last_seen"`
+	IsQuitted       bool              `db:"quitted"`
+	Maintenance     NodeMaintenance   `json:"maintenance" db:"maintenance"`
+	Labels          map[string]string `json:"labels" db:"-"`
+	SchedulerID     dtypes.ServerID   `db:"scheduler_sid"`
+	NodeQuarantine
+	Failures *NodeFailureStats `json:"failures" db:"-"` // recent failures counted for the quarantine
 }
 
 // NodeType node type
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"strings"
 	"time"
 )
 
@@ -34,6 +35,8 @@ type EdgeDownloadInfoList struct {
 	Infos        []*EdgeDownloadInfo
 	SchedulerURL string
 	SchedulerKey string
+	// KeyEnvelope is the wrapped data key of a private asset for the user of the token, nil if the asset is not private or the user is not authorised
+	KeyEnvelope *AssetKeyEnvelope
 }
 
 // CandidateDownloadInfo represents download information for a candidate
@@ -105,6 +108,122 @@ func (n NatType) FromString(natType string) NatType {
 	return NatTypeUnknow
 }
 
+// NodeMaintenance represents the maintenance mode of a node
+type NodeMaintenance string
+
+const (
+	// NodeMaintenanceNone the node is in service
+	NodeMaintenanceNone NodeMaintenance = ""
+	// NodeMaintenanceCordoned no new replicas, validations or downloads are assigned to the node
+	NodeMaintenanceCordoned NodeMaintenance = "cordoned"
+	// NodeMaintenanceDraining the node is cordoned and its replicas are re-created on other nodes
+	NodeMaintenanceDraining NodeMaintenance = "draining"
+)
+
+// NodeDrainProgress represents the progress of draining the replicas off a node
+type NodeDrainProgress struct {
+	NodeID      string
+	Maintenance NodeMaintenance
+	// succeeded replicas that are still on the node
+	RemainingReplicas int
+}
+
+// NodeQuarantine represents the quarantine of a node that failed repeatedly, the node is not selected for new replicas until it is released
+type NodeQuarantine struct {
+	QuarantineUntil  time.Time `json:"quarantine_until" db:"quarantine_until"`
+	QuarantineReason string    `json:"quarantine_reason" db:"quarantine_reason"`
+	// successive quarantines of the node, each one doubles the backoff
+	QuarantineCount int `json:"quarantine_count" db:"quarantine_count"`
+}
+
+// InQuarantine checks if the quarantine is not released yet
+func (q *NodeQuarantine) InQuarantine() bool {
+	return time.Now().Before(q.QuarantineUntil)
+}
+
+// NodeFailureKind represents the kind of a node failure counted for the quarantine
+type NodeFailureKind int
+
+const (
+	// NodeFailurePull the node failed to pull a replica
+	NodeFailurePull NodeFailureKind = iota
+	// NodeFailureValidation the node failed or timed out the validation
+	NodeFailureValidation
+	// NodeFailureKeepalive the node dropped the keepalive
+	NodeFailureKeepalive
+	// NodeFailureSource the pull of the node failed by the errors of its download sources, it is not counted for the quarantine
+	NodeFailureSource
+)
+
+// NodeFailureStats represents the failures of a node since the start of the counting window
+type NodeFailureStats struct {
+	Pulls          int
+	Validations    int
+	KeepaliveDrops int
+	SourceErrors   int // pulls failed by the download sources, not in the total
+	Since          time.Time
+}
+
+// Total returns the number of the failures
+func (s *NodeFailureStats) Total() int {
+	return s.Pulls + s.Validations + s.KeepaliveDrops
+}
+
+// LabelSelector selects nodes by their labels, a label is written as "key=value", or as "key" to match any value of the key
+type LabelSelector struct {
+	// Include labels that the node must all have
+	Include []string
+	// Exclude labels that the node must have none of
+	Exclude []string
+}
+
+// IsEmpty checks if the selector selects all nodes
+func (s *LabelSelector) IsEmpty() bool {
+	return s == nil || (len(s.Include) == 0 && len(s.Exclude) == 0)
+}
+
+// Matches checks if the node labels are selected
+func (s *LabelSelector) Matches(labels map[string]string) bool {
+	if s == nil {
+		return true
+	}
+
+	for _, label := range s.Include {
+		if !hasLabel(labels, label) {
+			return false
+		}
+	}
+
+	for _, label := range s.Exclude {
+		if hasLabel(labels, label) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasLabel checks if the labels have the label of the selector
+func hasLabel(labels map[string]string, label string) bool {
+	key, value, hasValue := ParseLabel(label)
+	v, ok := labels[key]
+	if !ok {
+		return false
+	}
+
+	return !hasValue || v == value
+}
+
+// ParseLabel splits the label into its key and value, hasValue is false for a key-only label
+func ParseLabel(label string) (key, value string, hasValue bool) {
+	kv := strings.SplitN(label, "=", 2)
+	if len(kv) == 1 {
+		return strings.TrimSpace(kv[0]), "", false
+	}
+
+	return strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]), true
+}
+
 // ListNodesRsp list node rsp
 type ListNodesRsp struct {
 	Data  []NodeInfo `json:"data"`
@@ -139,6 +258,18 @@ type ValidationResultInfo struct {
 	UploadTraffic float64 `db:"upload_traffic"`
 }
 
+// NodeScore represents the selection score of a node and the factors of the score (0 ~ 1)
+type NodeScore struct {
+	NodeID          string
+	Score           float64
+	FreeDisk        float64
+	Bandwidth       float64 // relative to the maximum upstream bandwidth of the online nodes
+	Load            float64
+	Validation      float64 // recent validation success rate
+	Uptime          float64
+	CurPullingCount int
+}
+
 // ValidationStatus Validation Status
 type ValidationStatus int
 
@@ -182,6 +313,39 @@ type Credentials struct {
 	ClientID  string
 	LimitRate int64
 	ValidTime int64
+	// Private the asset is encrypted by the client, it is only served in raw and car formats
+	Private bool
+}
+
+// UploadTicket allows the client to upload an asset to the candidate, it is encrypted and signed like the Credentials
+type UploadTicket struct {
+	ID        string
+	NodeID    string
+	MaxSize   int64 // maximum size of the uploaded car file
+	ValidTime int64 // the upload must start before the time
+	// the upload must be reported before the time, the unfinished resumable upload can not be written after it
+	Expiration int64
+}
+
+// UploadInfo represents the candidate to upload an asset to
+type UploadInfo struct {
+	NodeID string
+	// URL of the upload endpoint, the car file is posted as the request body
+	URL string
+	// ResumableURL of the tus resumable upload endpoint, the file is built into a UnixFS asset when it is completely uploaded
+	ResumableURL string
+	// encrypted UploadTicket, sent in the Titan-Upload-Ticket header as "Ciphertext.Sign"
+	Ticket *GatewayCredentials
+	// the ticket is valid until the time
+	ValidTime time.Time
+}
+
+// UploadResult represents the result of an asset uploaded to the candidate
+type UploadResult struct {
+	TicketID string
+	CID      string
+	Size     int64 // size of the uploaded car file
+	Blocks   int64
 }
 
 // GatewayCredentials be use for access gateway
@@ -194,6 +358,7 @@ type GatewayCredentials struct {
 
 type UserProofOfWork struct {
 	TicketID      string
+	AssetCID      string
 	ClientID      string
 	DownloadSpeed int64
 	DownloadSize  int64
@@ -0,0 +1,43 @@
+package types
+
+import "time"
+
+// S3AccessKey is an access key of a tenant to the S3 compatible api of the candidates,
+// the assets of the objects put with the key are pulled with its replicas and expiration
+type S3AccessKey struct {
+	AccessKey string `db:"access_key"`
+	SecretKey string `db:"secret_key"`
+	Tenant    string `db:"tenant"`
+	Replicas  int64  `db:"replicas"`
+	// Expiration is the seconds that the asset of an object is kept after it is put
+	Expiration  int64     `db:"expiration"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// S3AccessKeyReq is the request to create an access key of a tenant
+type S3AccessKeyReq struct {
+	Tenant     string
+	Replicas   int64
+	Expiration time.Duration
+}
+
+// S3Object maps an object key of a tenant bucket to the root cid of its asset
+type S3Object struct {
+	Tenant       string    `db:"tenant"`
+	Bucket       string    `db:"bucket"`
+	Key          string    `db:"object_key"`
+	CID          string    `db:"cid"`
+	Size         int64     `db:"size"`
+	ETag         string    `db:"etag"`
+	ContentType  string    `db:"content_type"`
+	LastModified time.Time `db:"last_modified"`
+}
+
+// S3ListObjectsReq lists the objects of a bucket in the order of the keys
+type S3ListObjectsReq struct {
+	Bucket string
+	Prefix string
+	// StartAfter is the key that the listed keys are after
+	StartAfter string
+	Limit      int
+}
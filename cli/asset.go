@@ -31,8 +31,116 @@ var assetCmd = &cli.Command{
 		pullAssetCmd,
 		showAssetInfoCmd,
 		removeAssetRecordCmd,
+		restoreAssetCmd,
+		pauseAssetCmd,
+		resumeAssetCmd,
 		removeAssetReplicaCmd,
 		resetExpirationCmd,
+		listScalingRecordsCmd,
+		listAssetEventsCmd,
+		urlPullStatusCmd,
+		assetGroupCmd,
+		planAssetPlacementCmd,
+	},
+}
+
+var assetGroupCmd = &cli.Command{
+	Name:  "group",
+	Usage: "Manage asset group",
+	Subcommands: []*cli.Command{
+		showAssetGroupCmd,
+		resetGroupExpirationCmd,
+		removeAssetGroupCmd,
+	},
+}
+
+var showAssetGroupCmd = &cli.Command{
+	Name:  "info",
+	Usage: "Show the assets of the group",
+	Flags: []cli.Flag{
+		groupFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		group, err := schedulerAPI.GetAssetGroup(ctx, cctx.String("group"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Group:\t%s\n", group.GroupID)
+		fmt.Printf("Assets:\t%d\n", len(group.Assets))
+		fmt.Printf("Size:\t%s\n", units.BytesSize(float64(group.TotalSize)))
+
+		tw := tablewriter.New(
+			tablewriter.Col("CID"),
+			tablewriter.Col("State"),
+			tablewriter.Col("Size"),
+			tablewriter.Col("Expiration"),
+		)
+
+		for _, info := range group.Assets {
+			m := map[string]interface{}{
+				"CID":        info.CID,
+				"State":      colorState(info.State),
+				"Size":       units.BytesSize(float64(info.TotalSize)),
+				"Expiration": info.Expiration.Format(defaultDateTimeLayout),
+			}
+
+			tw.Write(m)
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var resetGroupExpirationCmd = &cli.Command{
+	Name:  "reset-expiration",
+	Usage: "Reset the expiration of all assets in the group",
+	Flags: []cli.Flag{
+		groupFlag,
+		dateFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		t, err := time.ParseInLocation("2006-1-2 15:04:05", cctx.String("date-time"), time.Local)
+		if err != nil {
+			return xerrors.Errorf("date time err:%s", err.Error())
+		}
+
+		return schedulerAPI.UpdateAssetGroupExpiration(ctx, cctx.String("group"), t)
+	},
+}
+
+var removeAssetGroupCmd = &cli.Command{
+	Name:  "remove",
+	Usage: "Remove all asset records of the group",
+	Flags: []cli.Flag{
+		groupFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.RemoveAssetGroup(ctx, cctx.String("group"))
 	},
 }
 
@@ -69,6 +177,75 @@ var resetExpirationCmd = &cli.Command{
 	},
 }
 
+var restoreAssetCmd = &cli.Command{
+	Name:  "restore",
+	Usage: "Restore the pending delete asset, the expiration is kept if the date time is not set",
+	Flags: []cli.Flag{
+		cidFlag,
+		dateFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		cid := cctx.String("cid")
+		dateTime := cctx.String("date-time")
+
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		var expiration time.Time
+		if dateTime != "" {
+			expiration, err = time.ParseInLocation("2006-1-2 15:04:05", dateTime, time.Local)
+			if err != nil {
+				return xerrors.Errorf("date time err:%s", err.Error())
+			}
+		}
+
+		return schedulerAPI.RestoreAsset(ctx, cid, expiration)
+	},
+}
+
+var pauseAssetCmd = &cli.Command{
+	Name:  "pause",
+	Usage: "Pause the asset pulling, the asset keeps its progress",
+	Flags: []cli.Flag{
+		cidFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.PauseAsset(ctx, cctx.String("cid"))
+	},
+}
+
+var resumeAssetCmd = &cli.Command{
+	Name:  "resume",
+	Usage: "Resume the paused asset pulling",
+	Flags: []cli.Flag{
+		cidFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.ResumeAsset(ctx, cctx.String("cid"))
+	},
+}
+
 var removeAssetRecordCmd = &cli.Command{
 	Name:  "remove",
 	Usage: "Remove the asset record",
@@ -137,6 +314,9 @@ var showAssetInfoCmd = &cli.Command{
 		fmt.Printf("CID:\t%s\n", info.CID)
 		fmt.Printf("Hash:\t%s\n", info.Hash)
 		fmt.Printf("State:\t%s\n", colorState(info.State))
+		if info.QueuePosition > 0 {
+			fmt.Printf("QueuePosition:\t%d\n", info.QueuePosition)
+		}
 		fmt.Printf("Blocks:\t%d\n", info.TotalBlocks)
 		fmt.Printf("Size:\t%s\n", units.BytesSize(float64(info.TotalSize)))
 		fmt.Printf("NeedEdgeReplica:\t%d\n", info.NeedEdgeReplica)
@@ -144,8 +324,12 @@ var showAssetInfoCmd = &cli.Command{
 
 		fmt.Printf("--------\nProcesses:\n")
 		for _, cache := range info.ReplicaInfos {
-			fmt.Printf("%s(%s): %s\t%s/%s\n", cache.NodeID, edgeOrCandidate(cache.IsCandidate), colorState(cache.Status.String()),
+			fmt.Printf("%s(%s): %s\t%s/%s", cache.NodeID, edgeOrCandidate(cache.IsCandidate), colorState(cache.Status.String()),
 				units.BytesSize(float64(cache.DoneSize)), units.BytesSize(float64(info.TotalSize)))
+			if cache.FailMsg != "" {
+				fmt.Printf("\t%s", cache.FailMsg)
+			}
+			fmt.Printf("\n")
 		}
 
 		return nil
@@ -159,12 +343,76 @@ var pullAssetCmd = &cli.Command{
 		cidFlag,
 		replicaCountFlag,
 		expirationDateFlag,
+		areasFlag,
+		groupFlag,
+		includeLabelsFlag,
+		excludeLabelsFlag,
+		&cli.StringFlag{
+			Name:  "cid-file",
+			Usage: "file of the asset cids to pull in a batch, one cid per line",
+		},
+		&cli.Int64Flag{
+			Name:  "priority",
+			Usage: "priority in the pull queue, higher is first",
+		},
+		&cli.Int64Flag{
+			Name:  "min-replicas",
+			Usage: "minimum edge replicas when scaling by download demand",
+		},
+		&cli.Int64Flag{
+			Name:  "max-replicas",
+			Usage: "maximum edge replicas when scaling by download demand, 0 disables the scaling",
+		},
+		&cli.Int64Flag{
+			Name:  "pull-timeout",
+			Usage: "seconds without pull progress before the node pulls are failed, 0 uses the scheduler default",
+		},
+		&cli.Int64Flag{
+			Name:  "retry-interval",
+			Usage: "seconds to wait before the failed pulls are retried, 0 uses the scheduler default",
+		},
+		&cli.Int64Flag{
+			Name:  "max-retries",
+			Usage: "maximum number of retries of the failed pulls, 0 uses the scheduler default",
+		},
+		&cli.Int64Flag{
+			Name:  "select-attempts",
+			Usage: "attempts per replica to select a random node, 0 uses the scheduler default",
+		},
+		&cli.StringFlag{
+			Name:  "url",
+			Usage: "origin url of the asset, the asset cid is built by a seed candidate",
+		},
+		&cli.StringFlag{
+			Name:  "chunker",
+			Usage: "chunker to build the file of the url, e.g. size-1048576 or rabin-262144-524288-1048576",
+			Value: "size-1048576",
+		},
+		&cli.StringFlag{
+			Name:  "layout",
+			Usage: "dag layout to build the file of the url, balanced or trickle",
+			Value: "balanced",
+		},
+		&cli.BoolFlag{
+			Name:  "raw-leaves",
+			Usage: "store the leaves of the file of the url as raw blocks",
+			Value: true,
+		},
+		&cli.IntFlag{
+			Name:  "cid-version",
+			Usage: "cid version of the file of the url",
+			Value: 1,
+		},
+		&cli.Int64Flag{
+			Name:  "data-shards",
+			Usage: "store the asset on the edges in erasure coded shards instead of full replicas, the number of data shards",
+		},
+		&cli.Int64Flag{
+			Name:  "parity-shards",
+			Usage: "the number of parity shards of the erasure coded asset, any data shards of all shards rebuild the asset",
+		},
 	},
 	Action: func(cctx *cli.Context) error {
-		cid := cctx.String("cid")
-		replicaCount := cctx.Int64("replica-count")
-		date := cctx.String("expiration-date")
-
 		ctx := ReqContext(cctx)
 		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
 		if err != nil {
@@ -172,33 +420,135 @@ var pullAssetCmd = &cli.Command{
 		}
 		defer closer()
 
-		if cid == "" {
-			return xerrors.New("cid is nil")
+		info, err := newPullAssetReq(cctx)
+		if err != nil {
+			return err
 		}
 
-		info := &types.PullAssetReq{CID: cid}
+		info.MinReplicas = cctx.Int64("min-replicas")
+		info.MaxReplicas = cctx.Int64("max-replicas")
+		info.Priority = cctx.Int64("priority")
+		info.GroupID = cctx.String("group")
+		info.PullTimeout = cctx.Int64("pull-timeout")
+		info.RetryInterval = cctx.Int64("retry-interval")
+		info.MaxRetries = cctx.Int64("max-retries")
+		info.SelectAttempts = cctx.Int64("select-attempts")
+
+		if dataShards := cctx.Int64("data-shards"); dataShards > 0 {
+			info.ErasureCoding = &types.ErasureCoding{
+				DataShards:   dataShards,
+				ParityShards: cctx.Int64("parity-shards"),
+			}
+		}
 
-		if date == "" {
-			date = time.Now().Add(defaultExpiration).Format(defaultDateTimeLayout)
+		if info.URL = cctx.String("url"); info.URL != "" {
+			info.UnixFS = &types.UnixFSOptions{
+				Chunker:    cctx.String("chunker"),
+				Layout:     cctx.String("layout"),
+				RawLeaves:  cctx.Bool("raw-leaves"),
+				CIDVersion: cctx.Int("cid-version"),
+			}
 		}
 
-		eTime, err := time.ParseInLocation(defaultDateTimeLayout, date, time.Local)
-		if err != nil {
-			return xerrors.Errorf("parse expiration err:%s", err.Error())
+		cidFile := cctx.String("cid-file")
+		if cidFile == "" && info.URL == "" {
+			return schedulerAPI.PullAsset(ctx, info)
 		}
 
-		info.Expiration = eTime
-		info.Replicas = replicaCount
+		infos := []*types.PullAssetReq{info}
+		if cidFile != "" {
+			infos, err = batchPullAssetReqs(info, cidFile)
+			if err != nil {
+				return err
+			}
+		}
 
-		err = schedulerAPI.PullAsset(ctx, info)
+		results, err := schedulerAPI.PullAssets(ctx, infos)
 		if err != nil {
 			return err
 		}
 
+		failed := 0
+		for _, result := range results {
+			if result.Err != "" {
+				failed++
+				fmt.Printf("%s%s: %s\n", result.CID, result.URL, color.RedString(result.Err))
+				continue
+			}
+
+			if result.PullID != "" {
+				fmt.Printf("%s: pull id %s\n", result.URL, result.PullID)
+			}
+		}
+
+		fmt.Printf("pull %d assets, %d succeeded, %d failed\n", len(results), len(results)-failed, failed)
 		return nil
 	},
 }
 
+// newPullAssetReq returns the pull request of the cid, replica count, expiration date and areas flags
+func newPullAssetReq(cctx *cli.Context) (*types.PullAssetReq, error) {
+	cid := cctx.String("cid")
+	if cid == "" && cctx.String("cid-file") == "" && cctx.String("url") == "" {
+		return nil, xerrors.New("cid is nil")
+	}
+
+	date := cctx.String("expiration-date")
+	if date == "" {
+		date = time.Now().Add(defaultExpiration).Format(defaultDateTimeLayout)
+	}
+
+	eTime, err := time.ParseInLocation(defaultDateTimeLayout, date, time.Local)
+	if err != nil {
+		return nil, xerrors.Errorf("parse expiration err:%s", err.Error())
+	}
+
+	return &types.PullAssetReq{
+		CID:        cid,
+		Replicas:   cctx.Int64("replica-count"),
+		Expiration: eTime,
+		Areas:      cctx.StringSlice("areas"),
+		Labels:     labelSelectorFromFlags(cctx),
+	}, nil
+}
+
+// batchPullAssetReqs returns a copy of the pull request for each cid in the file
+func batchPullAssetReqs(info *types.PullAssetReq, cidFile string) ([]*types.PullAssetReq, error) {
+	cids, err := readCIDFile(cidFile)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*types.PullAssetReq, 0, len(cids))
+	for _, cid := range cids {
+		req := *info
+		req.CID = cid
+		infos = append(infos, &req)
+	}
+
+	return infos, nil
+}
+
+// readCIDFile reads the cids in the file, one cid per line, empty lines and lines starting with # are skipped
+func readCIDFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("read cid file err:%s", err.Error())
+	}
+
+	cids := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		cids = append(cids, line)
+	}
+
+	return cids, nil
+}
+
 var listAssetRecordCmd = &cli.Command{
 	Name:  "list",
 	Usage: "List asset record",
@@ -220,6 +570,21 @@ var listAssetRecordCmd = &cli.Command{
 			Usage: "only show the failed state assets",
 			Value: false,
 		},
+		&cli.BoolFlag{
+			Name:  "queued",
+			Usage: "only show the queued assets",
+			Value: false,
+		},
+		&cli.BoolFlag{
+			Name:  "paused",
+			Usage: "only show the paused assets",
+			Value: false,
+		},
+		&cli.BoolFlag{
+			Name:  "pending-delete",
+			Usage: "only show the pending delete assets",
+			Value: false,
+		},
 		&cli.BoolFlag{
 			Name:  "restart",
 			Usage: "restart the failed assets, only apply for failed asset state",
@@ -237,7 +602,7 @@ var listAssetRecordCmd = &cli.Command{
 		limit := cctx.Int("limit")
 		offset := cctx.Int("offset")
 
-		states := append([]string{assets.Servicing.String()}, append(assets.FailedStates, assets.PullingStates...)...)
+		states := append([]string{assets.Servicing.String(), assets.Queued.String(), assets.Paused.String()}, append(assets.FailedStates, assets.PullingStates...)...)
 
 		if cctx.Bool("pulling") {
 			states = assets.PullingStates
@@ -245,6 +610,15 @@ var listAssetRecordCmd = &cli.Command{
 		if cctx.Bool("failed") {
 			states = assets.FailedStates
 		}
+		if cctx.Bool("queued") {
+			states = []string{assets.Queued.String()}
+		}
+		if cctx.Bool("paused") {
+			states = []string{assets.Paused.String()}
+		}
+		if cctx.Bool("pending-delete") {
+			states = []string{assets.PendingDelete.String()}
+		}
 
 		restart := cctx.Bool("restart")
 		if restart && !cctx.Bool("failed") {
@@ -269,9 +643,17 @@ var listAssetRecordCmd = &cli.Command{
 
 		for w := 0; w < len(list); w++ {
 			info := list[w]
+			state := info.State
+			if info.QueuePosition > 0 {
+				state = fmt.Sprintf("%s(%d)", state, info.QueuePosition)
+			}
+			if info.PausedFrom != "" {
+				state = fmt.Sprintf("%s(%s)", state, info.PausedFrom)
+			}
+
 			m := map[string]interface{}{
 				"CID":        info.CID,
-				"State":      colorState(info.State),
+				"State":      colorState(state),
 				"Blocks":     info.TotalBlocks,
 				"Size":       units.BytesSize(float64(info.TotalSize)),
 				"CreateTime": info.CreateTime.Format(defaultDateTimeLayout),
@@ -326,3 +708,246 @@ func colorState(state string) string {
 		return color.YellowString(state)
 	}
 }
+
+var listScalingRecordsCmd = &cli.Command{
+	Name:  "scaling-records",
+	Usage: "List the edge replica scaling records of the asset",
+	Flags: []cli.Flag{
+		cidFlag,
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		cid := cctx.String("cid")
+		if cid == "" {
+			return xerrors.New("cid is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		records, err := schedulerAPI.GetReplicaScalingRecords(ctx, cid, cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Time"),
+			tablewriter.Col("Replicas"),
+			tablewriter.Col("Requests"),
+			tablewriter.Col("Downloads"),
+			tablewriter.Col("Size"),
+			tablewriter.Col("Msg"),
+		)
+
+		for _, record := range records {
+			m := map[string]interface{}{
+				"Time":      record.CreatedTime.Format(defaultDateTimeLayout),
+				"Replicas":  fmt.Sprintf("%d -> %d", record.FromReplicas, record.ToReplicas),
+				"Requests":  record.Requests,
+				"Downloads": record.Downloads,
+				"Size":      units.BytesSize(float64(record.DownloadSize)),
+				"Msg":       record.Msg,
+			}
+			tw.Write(m)
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var listAssetEventsCmd = &cli.Command{
+	Name:  "events",
+	Usage: "List the state machine events of the asset",
+	Flags: []cli.Flag{
+		cidFlag,
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		cid := cctx.String("cid")
+		if cid == "" {
+			return xerrors.New("cid is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		events, err := schedulerAPI.GetAssetEvents(ctx, cid, cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Time"),
+			tablewriter.Col("Event"),
+			tablewriter.Col("State"),
+			tablewriter.Col("Msg"),
+		)
+
+		for _, event := range events {
+			state := colorState(event.ToState)
+			if event.FromState != event.ToState {
+				state = fmt.Sprintf("%s -> %s", event.FromState, colorState(event.ToState))
+			}
+
+			m := map[string]interface{}{
+				"Time":  event.CreatedTime.Format(defaultDateTimeLayout),
+				"Event": event.Event,
+				"State": state,
+				"Msg":   event.Msg,
+			}
+			tw.Write(m)
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var urlPullStatusCmd = &cli.Command{
+	Name:      "url-pull",
+	Usage:     "Show the status of the asset pulled from its origin url",
+	ArgsUsage: "[pull id]",
+	Action: func(cctx *cli.Context) error {
+		id := cctx.Args().First()
+		if id == "" {
+			return xerrors.New("pull id is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		status, err := schedulerAPI.GetURLPullStatus(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("URL:\t%s\n", status.URL)
+		fmt.Printf("Node:\t%s\n", status.NodeID)
+		fmt.Printf("Status:\t%s\n", colorState(status.Status.String()))
+		fmt.Printf("CID:\t%s\n", status.CID)
+		fmt.Printf("StartTime:\t%s\n", status.StartTime.Format(defaultDateTimeLayout))
+		if status.Msg != "" {
+			fmt.Printf("Msg:\t%s\n", status.Msg)
+		}
+
+		return nil
+	},
+}
+
+var planAssetPlacementCmd = &cli.Command{
+	Name:  "plan",
+	Usage: "Show the nodes that would pull the asset replicas, without pulling the assets",
+	Flags: []cli.Flag{
+		cidFlag,
+		replicaCountFlag,
+		expirationDateFlag,
+		areasFlag,
+		includeLabelsFlag,
+		excludeLabelsFlag,
+		&cli.StringFlag{
+			Name:  "cid-file",
+			Usage: "file of the asset cids to plan in a batch, one cid per line",
+		},
+		&cli.BoolFlag{
+			Name:  "nodes",
+			Usage: "show the chosen nodes of each asset",
+			Value: false,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		info, err := newPullAssetReq(cctx)
+		if err != nil {
+			return err
+		}
+
+		infos := []*types.PullAssetReq{info}
+		if cidFile := cctx.String("cid-file"); cidFile != "" {
+			infos, err = batchPullAssetReqs(info, cidFile)
+			if err != nil {
+				return err
+			}
+		}
+
+		plan, err := schedulerAPI.PlanAssetPlacement(ctx, infos)
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("CID"),
+			tablewriter.Col("Size"),
+			tablewriter.Col("Candidates"),
+			tablewriter.Col("Edges"),
+			tablewriter.NewLineCol("Shortfalls"),
+			tablewriter.NewLineCol("Nodes"),
+		)
+
+		for _, placement := range plan.Assets {
+			m := map[string]interface{}{
+				"CID":        placement.CID,
+				"Size":       "unknown",
+				"Candidates": len(placement.CandidateNodes),
+				"Edges":      len(placement.EdgeNodes),
+			}
+
+			if placement.Size > 0 {
+				m["Size"] = units.BytesSize(float64(placement.Size))
+			}
+
+			if placement.Err != "" {
+				m["Shortfalls"] = color.RedString(placement.Err)
+			} else if len(placement.Shortfalls) > 0 {
+				m["Shortfalls"] = color.YellowString(strings.Join(placement.Shortfalls, "; "))
+			}
+
+			if cctx.Bool("nodes") {
+				m["Nodes"] = strings.Join(append(placement.CandidateNodes, placement.EdgeNodes...), ", ")
+			}
+
+			tw.Write(m)
+		}
+
+		if err := tw.Flush(os.Stdout); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nEstimated size: %s", units.BytesSize(float64(plan.EstimatedSize)))
+		if plan.UnknownSizeAssets > 0 {
+			fmt.Printf(" (%d assets not pulled yet, their size is unknown)", plan.UnknownSizeAssets)
+		}
+		fmt.Println()
+
+		areas := make([]string, 0, len(plan.AreaReplicas))
+		for area := range plan.AreaReplicas {
+			areas = append(areas, area)
+		}
+		sort.Strings(areas)
+
+		fmt.Println("Area distribution:")
+		for _, area := range areas {
+			fmt.Printf("  %s: %d\n", area, plan.AreaReplicas[area])
+		}
+
+		return nil
+	},
+}
@@ -3,9 +3,13 @@ package cli
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/docker/go-units"
 	"github.com/linguohua/titan/api/types"
+	"github.com/linguohua/titan/lib/tablewriter"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
 )
@@ -20,6 +24,15 @@ var nodeCmd = &cli.Command{
 		nodeQuitCmd,
 		setNodePortCmd,
 		edgeExternalAddrCmd,
+		nodeScoresCmd,
+		nodeCordonCmd,
+		nodeDrainCmd,
+		nodeUncordonCmd,
+		nodeDrainStatusCmd,
+		nodeRebalanceCmd,
+		listNodesCmd,
+		setNodeLabelsCmd,
+		nodeQuarantineCmd,
 	},
 }
 
@@ -134,6 +147,19 @@ var showNodeInfoCmd = &cli.Command{
 		//
 		fmt.Printf("DownloadCount: %d \n", info.DownloadBlocks)
 		fmt.Printf("NatType: %s \n", natType.String())
+		if len(info.Labels) > 0 {
+			fmt.Printf("labels: %s \n", formatLabels(info.Labels))
+		}
+		if info.Maintenance != types.NodeMaintenanceNone {
+			fmt.Printf("maintenance: %s \n", info.Maintenance)
+		}
+		if info.InQuarantine() {
+			fmt.Printf("quarantine: until %s, %s \n", info.QuarantineUntil.Format(defaultDateTimeLayout), info.QuarantineReason)
+		}
+		if info.Failures != nil {
+			fmt.Printf("failures: pull %d, validation %d, keepalive %d, source %d since %s \n", info.Failures.Pulls, info.Failures.Validations,
+				info.Failures.KeepaliveDrops, info.Failures.SourceErrors, info.Failures.Since.Format(defaultDateTimeLayout))
+		}
 
 		return nil
 	},
@@ -203,3 +229,378 @@ var edgeExternalAddrCmd = &cli.Command{
 		return nil
 	},
 }
+
+var nodeScoresCmd = &cli.Command{
+	Name:  "scores",
+	Usage: "Show the selection scores of online nodes",
+	Flags: []cli.Flag{
+		nodeTypeFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		t := cctx.Int("node-type")
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		scores, err := schedulerAPI.GetNodeScores(ctx, types.NodeType(t))
+		if err != nil {
+			return err
+		}
+
+		sort.Slice(scores, func(i, j int) bool {
+			return scores[i].Score > scores[j].Score
+		})
+
+		tw := tablewriter.New(
+			tablewriter.Col("NodeID"),
+			tablewriter.Col("Score"),
+			tablewriter.Col("FreeDisk"),
+			tablewriter.Col("Bandwidth"),
+			tablewriter.Col("Load"),
+			tablewriter.Col("Validation"),
+			tablewriter.Col("Uptime"),
+			tablewriter.Col("Pulling"),
+		)
+
+		for _, score := range scores {
+			m := map[string]interface{}{
+				"NodeID":     score.NodeID,
+				"Score":      fmt.Sprintf("%.3f", score.Score),
+				"FreeDisk":   fmt.Sprintf("%.2f", score.FreeDisk),
+				"Bandwidth":  fmt.Sprintf("%.2f", score.Bandwidth),
+				"Load":       fmt.Sprintf("%.2f", score.Load),
+				"Validation": fmt.Sprintf("%.2f", score.Validation),
+				"Uptime":     fmt.Sprintf("%.2f", score.Uptime),
+				"Pulling":    score.CurPullingCount,
+			}
+			tw.Write(m)
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var nodeCordonCmd = &cli.Command{
+	Name:  "cordon",
+	Usage: "Stop assigning new replicas, validations and downloads to the node",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		return setNodeMaintenance(cctx, types.NodeMaintenanceCordoned)
+	},
+}
+
+var nodeDrainCmd = &cli.Command{
+	Name:  "drain",
+	Usage: "Cordon the node and re-create its replicas on other nodes",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		return setNodeMaintenance(cctx, types.NodeMaintenanceDraining)
+	},
+}
+
+var nodeUncordonCmd = &cli.Command{
+	Name:  "uncordon",
+	Usage: "Put the cordoned or draining node back in service",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		return setNodeMaintenance(cctx, types.NodeMaintenanceNone)
+	},
+}
+
+var nodeQuarantineCmd = &cli.Command{
+	Name:  "quarantine",
+	Usage: "Quarantine the node until the date time, or release it",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+		dateFlag,
+		&cli.StringFlag{
+			Name:  "reason",
+			Usage: "the reason of the quarantine",
+			Value: "",
+		},
+		&cli.BoolFlag{
+			Name:  "release",
+			Usage: "release the quarantined node",
+			Value: false,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeID := cctx.String("node-id")
+		if nodeID == "" {
+			return xerrors.New("node-id is nil")
+		}
+
+		var until time.Time
+		if !cctx.Bool("release") {
+			dateTime := cctx.String("date-time")
+			if dateTime == "" {
+				return xerrors.New("date-time is nil")
+			}
+
+			var err error
+			until, err = time.ParseInLocation("2006-1-2 15:04:05", dateTime, time.Local)
+			if err != nil {
+				return xerrors.Errorf("date time err:%s", err.Error())
+			}
+		}
+
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.SetNodeQuarantine(ctx, nodeID, until, cctx.String("reason"))
+	},
+}
+
+var nodeDrainStatusCmd = &cli.Command{
+	Name:  "drain-status",
+	Usage: "Show the replicas left on the draining node",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeID := cctx.String("node-id")
+		if nodeID == "" {
+			return xerrors.New("node-id is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		progress, err := schedulerAPI.GetNodeDrainProgress(ctx, nodeID)
+		if err != nil {
+			return err
+		}
+
+		mode := string(progress.Maintenance)
+		if mode == "" {
+			mode = "none"
+		}
+
+		fmt.Printf("node id: %s \n", progress.NodeID)
+		fmt.Printf("maintenance: %s \n", mode)
+		fmt.Printf("remaining replicas: %d \n", progress.RemainingReplicas)
+
+		return nil
+	},
+}
+
+func setNodeMaintenance(cctx *cli.Context, mode types.NodeMaintenance) error {
+	nodeID := cctx.String("node-id")
+	if nodeID == "" {
+		return xerrors.New("node-id is nil")
+	}
+
+	ctx := ReqContext(cctx)
+	schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	return schedulerAPI.SetNodeMaintenance(ctx, nodeID, mode)
+}
+
+var nodeRebalanceCmd = &cli.Command{
+	Name:  "rebalance",
+	Usage: "Manage the moving of replicas off the nodes under disk pressure",
+	Subcommands: []*cli.Command{
+		enableRebalanceCmd,
+		disableRebalanceCmd,
+		listReplicaMovesCmd,
+	},
+}
+
+var enableRebalanceCmd = &cli.Command{
+	Name:  "enable",
+	Usage: "Enable the replica rebalancer",
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.SetReplicaRebalance(ctx, true)
+	},
+}
+
+var disableRebalanceCmd = &cli.Command{
+	Name:  "disable",
+	Usage: "Disable the replica rebalancer",
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.SetReplicaRebalance(ctx, false)
+	},
+}
+
+var listReplicaMovesCmd = &cli.Command{
+	Name:  "moves",
+	Usage: "List the replica moves from or to the node, all moves if node-id is not set",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		records, err := schedulerAPI.GetReplicaMoveRecords(ctx, cctx.String("node-id"), cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Time"),
+			tablewriter.Col("CID"),
+			tablewriter.Col("From"),
+			tablewriter.Col("To"),
+			tablewriter.Col("Size"),
+			tablewriter.Col("Status"),
+			tablewriter.Col("Msg"),
+		)
+
+		for _, record := range records {
+			m := map[string]interface{}{
+				"Time":   record.CreatedTime.Format(defaultDateTimeLayout),
+				"CID":    record.CID,
+				"From":   record.FromNodeID,
+				"To":     record.ToNodeID,
+				"Size":   units.BytesSize(float64(record.Size)),
+				"Status": colorState(record.Status.String()),
+				"Msg":    record.Msg,
+			}
+			tw.Write(m)
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var listNodesCmd = &cli.Command{
+	Name:  "list",
+	Usage: "List the nodes, filtered by their labels",
+	Flags: []cli.Flag{
+		limitFlag,
+		offsetFlag,
+		includeLabelsFlag,
+		excludeLabelsFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		rsp, err := schedulerAPI.GetNodeList(ctx, cctx.Int("offset"), cctx.Int("limit"), labelSelectorFromFlags(cctx))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("NodeID"),
+			tablewriter.Col("Name"),
+			tablewriter.Col("DiskUsage"),
+			tablewriter.Col("Maintenance"),
+			tablewriter.Col("Labels"),
+		)
+
+		for _, info := range rsp.Data {
+			m := map[string]interface{}{
+				"NodeID":      info.NodeID,
+				"Name":        info.NodeName,
+				"DiskUsage":   fmt.Sprintf("%.2f%%", info.DiskUsage),
+				"Maintenance": info.Maintenance,
+				"Labels":      formatLabels(info.Labels),
+			}
+			tw.Write(m)
+		}
+
+		if err := tw.Flush(os.Stdout); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nTotal: %d\n", rsp.Total)
+		return nil
+	},
+}
+
+var setNodeLabelsCmd = &cli.Command{
+	Name:  "set-labels",
+	Usage: "Replace the labels of the node, no label clears the labels",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+		&cli.StringSliceFlag{
+			Name:  "label",
+			Usage: "node label, example: --label=isp=telecom --label=tier=gold",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeID := cctx.String("node-id")
+		if nodeID == "" {
+			return xerrors.New("node-id is nil")
+		}
+
+		labels := make(map[string]string)
+		for _, label := range cctx.StringSlice("label") {
+			key, value, hasValue := types.ParseLabel(label)
+			if !hasValue {
+				return xerrors.Errorf("label %s must be key=value", label)
+			}
+
+			labels[key] = value
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.SetNodeLabels(ctx, nodeID, labels)
+	},
+}
+
+// formatLabels formats the labels as key=value pairs sorted by key
+func formatLabels(labels map[string]string) string {
+	list := make([]string, 0, len(labels))
+	for key, value := range labels {
+		list = append(list, key+"="+value)
+	}
+	sort.Strings(list)
+
+	return strings.Join(list, ",")
+}
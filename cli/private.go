@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/linguohua/titan/api/types"
+	"github.com/urfave/cli/v2"
+)
+
+var privateCmd = &cli.Command{
+	Name:  "private",
+	Usage: "Manage the users and the wrapped keys of the private assets",
+	Subcommands: []*cli.Command{
+		createUserTokenCmd,
+		setAssetKeyCmd,
+		removeAssetKeyCmd,
+	},
+}
+
+var userIDFlag = &cli.StringFlag{
+	Name:     "user-id",
+	Usage:    "the id of the user",
+	Required: true,
+}
+
+var createUserTokenCmd = &cli.Command{
+	Name:  "create-token",
+	Usage: "create a read token of the user, the requests sent with the token are of the user",
+	Flags: []cli.Flag{
+		userIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		token, err := schedulerAPI.CreateUserToken(ctx, cctx.String("user-id"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(token)
+		return nil
+	},
+}
+
+var setAssetKeyCmd = &cli.Command{
+	Name:  "set-key",
+	Usage: "save the wrapped data key of the private asset for the user",
+	Flags: []cli.Flag{
+		cidFlag,
+		userIDFlag,
+		&cli.StringFlag{
+			Name:     "wrapped-key",
+			Usage:    "the data key wrapped with the public key of the user, in hex",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		cid := cctx.String("cid")
+		if cid == "" {
+			return fmt.Errorf("must specify the cid")
+		}
+
+		wrappedKey, err := hex.DecodeString(cctx.String("wrapped-key"))
+		if err != nil {
+			return fmt.Errorf("decode wrapped key error %s", err.Error())
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		envelope := &types.AssetKeyEnvelope{UserID: cctx.String("user-id"), WrappedKey: wrappedKey}
+		return schedulerAPI.SetAssetKeyEnvelopes(ctx, cid, []*types.AssetKeyEnvelope{envelope})
+	},
+}
+
+var removeAssetKeyCmd = &cli.Command{
+	Name:  "remove-key",
+	Usage: "revoke the access of the user to the private asset",
+	Flags: []cli.Flag{
+		cidFlag,
+		userIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		cid := cctx.String("cid")
+		if cid == "" {
+			return fmt.Errorf("must specify the cid")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.RemoveAssetKeyEnvelope(ctx, cid, cctx.String("user-id"))
+	},
+}
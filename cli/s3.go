@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+	"github.com/urfave/cli/v2"
+)
+
+var s3Cmd = &cli.Command{
+	Name:  "s3",
+	Usage: "Manage the access keys of the s3 api",
+	Subcommands: []*cli.Command{
+		createS3AccessKeyCmd,
+		removeS3AccessKeyCmd,
+	},
+}
+
+var createS3AccessKeyCmd = &cli.Command{
+	Name:  "create-key",
+	Usage: "create an access key of the tenant, the secret is only shown once",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "tenant",
+			Usage:    "the tenant of the key",
+			Required: true,
+		},
+		replicaCountFlag,
+		&cli.DurationFlag{
+			Name:  "expiration",
+			Usage: "the time that the assets of the objects put with the key are kept",
+			Value: 7 * 24 * time.Hour,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		key, err := schedulerAPI.CreateS3AccessKey(ctx, &types.S3AccessKeyReq{
+			Tenant:     cctx.String("tenant"),
+			Replicas:   cctx.Int64("replica-count"),
+			Expiration: cctx.Duration("expiration"),
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Tenant:%s\n", key.Tenant)
+		fmt.Printf("AccessKey:%s\n", key.AccessKey)
+		fmt.Printf("SecretKey:%s\n", key.SecretKey)
+		return nil
+	},
+}
+
+var removeS3AccessKeyCmd = &cli.Command{
+	Name:      "remove-key",
+	Usage:     "remove the access key, the objects of the tenant are kept",
+	ArgsUsage: "<access key>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return fmt.Errorf("must specify the access key")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.RemoveS3AccessKey(ctx, cctx.Args().First())
+	},
+}
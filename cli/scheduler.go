@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/linguohua/titan/api"
+	"github.com/linguohua/titan/api/types"
 	"github.com/urfave/cli/v2"
 )
 
@@ -13,6 +14,8 @@ import (
 var SchedulerCMDs = []*cli.Command{
 	WithCategory("node", nodeCmd),
 	WithCategory("asset", assetCmd),
+	WithCategory("s3", s3Cmd),
+	WithCategory("private", privateCmd),
 	startElectionCmd,
 	// other
 	edgeUpdaterCmd,
@@ -76,8 +79,40 @@ var (
 		Usage: "port",
 		Value: "",
 	}
+
+	areasFlag = &cli.StringSliceFlag{
+		Name:  "areas",
+		Usage: "preferred areas of the replicas, example: --areas=CN-GD --areas=CN-FJ",
+	}
+
+	groupFlag = &cli.StringFlag{
+		Name:  "group",
+		Usage: "asset group id",
+		Value: "",
+	}
+
+	includeLabelsFlag = &cli.StringSliceFlag{
+		Name:  "include-labels",
+		Usage: "labels that the nodes must all have, a label without value matches any value, example: --include-labels=isp=telecom --include-labels=hw",
+	}
+
+	excludeLabelsFlag = &cli.StringSliceFlag{
+		Name:  "exclude-labels",
+		Usage: "labels that the nodes must have none of, example: --exclude-labels=tier=bronze",
+	}
 )
 
+// labelSelectorFromFlags returns the label selector of the include and exclude labels flags, nil if no label is set
+func labelSelectorFromFlags(cctx *cli.Context) *types.LabelSelector {
+	include := cctx.StringSlice("include-labels")
+	exclude := cctx.StringSlice("exclude-labels")
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+
+	return &types.LabelSelector{Include: include, Exclude: exclude}
+}
+
 var setNodePortCmd = &cli.Command{
 	Name:  "set-node-port",
 	Usage: "set the node port",
@@ -0,0 +1,209 @@
+// Package assetcrypt implements the encryption format of the private assets.
+//
+// The content of a private asset is encrypted by the client before it is chunked into blocks, so the nodes
+// only store and serve the ciphertext. An encrypted stream is a header followed by chunks sealed with AES-256-GCM:
+//
+//	header: magic "TENC" | version (1 byte) | chunk size (4 bytes, big endian) | nonce prefix (7 bytes)
+//	chunk:  AES-GCM(key, nonce prefix | chunk index (4 bytes, big endian) | last flag (1 byte), plaintext, header)
+//
+// Every chunk holds chunk size bytes of plaintext except the last one, which is flagged so that a truncated
+// stream is rejected. The data key of an asset is wrapped with the RSA public key of each authorised user.
+package assetcrypt
+
+import (
+	"bufio"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"io"
+	"math"
+
+	titanrsa "github.com/linguohua/titan/node/rsa"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// KeySize is the size of the data key of an asset
+	KeySize = 32
+	// DefaultChunkSize is the plaintext size of a chunk
+	DefaultChunkSize = 64 << 10
+	// maxChunkSize limits the memory used to decrypt a chunk
+	maxChunkSize = 16 << 20
+
+	version         = 1
+	headerSize      = 16
+	noncePrefixSize = 7
+)
+
+var magic = []byte("TENC")
+
+// ErrInvalidFormat is returned when the stream is not encrypted with this format or is corrupted
+var ErrInvalidFormat = xerrors.New("invalid encrypted stream")
+
+// GenerateKey returns a random data key
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// WrapKey encrypts the data key with the public key of a user
+func WrapKey(key []byte, pub *rsa.PublicKey) ([]byte, error) {
+	return titanrsa.New(crypto.SHA256, crypto.SHA256.New()).Encrypt(key, pub)
+}
+
+// UnwrapKey decrypts the wrapped data key with the private key of the user
+func UnwrapKey(wrapped []byte, priv *rsa.PrivateKey) ([]byte, error) {
+	key, err := titanrsa.New(crypto.SHA256, crypto.SHA256.New()).Decrypt(wrapped, priv)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) != KeySize {
+		return nil, xerrors.Errorf("invalid key size %d", len(key))
+	}
+
+	return key, nil
+}
+
+// Encrypt encrypts the content read from src into dst with the data key
+func Encrypt(dst io.Writer, src io.Reader, key []byte, chunkSize int) error {
+	if chunkSize <= 0 || chunkSize > maxChunkSize {
+		return xerrors.Errorf("chunk size must be between 1 and %d", maxChunkSize)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	header[4] = version
+	binary.BigEndian.PutUint32(header[5:9], uint32(chunkSize))
+	if _, err := io.ReadFull(rand.Reader, header[9:]); err != nil {
+		return err
+	}
+
+	if _, err := dst.Write(header); err != nil {
+		return err
+	}
+
+	r := bufio.NewReaderSize(src, chunkSize)
+	buf := make([]byte, chunkSize, chunkSize+aead.Overhead())
+	for index := uint32(0); ; index++ {
+		n, last, err := readChunk(r, buf)
+		if err != nil {
+			return err
+		}
+
+		if !last && index == math.MaxUint32 {
+			return xerrors.New("too many chunks")
+		}
+
+		sealed := aead.Seal(buf[:0], chunkNonce(header, index, last), buf[:n], header)
+		if _, err := dst.Write(sealed); err != nil {
+			return err
+		}
+
+		if last {
+			return nil
+		}
+	}
+}
+
+// Decrypt decrypts the encrypted content read from src into dst with the data key,
+// the content written before an error must be discarded
+func Decrypt(dst io.Writer, src io.Reader, key []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(src, header); err != nil {
+		return ErrInvalidFormat
+	}
+
+	if string(header[:4]) != string(magic) || header[4] != version {
+		return ErrInvalidFormat
+	}
+
+	chunkSize := int(binary.BigEndian.Uint32(header[5:9]))
+	if chunkSize <= 0 || chunkSize > maxChunkSize {
+		return ErrInvalidFormat
+	}
+
+	r := bufio.NewReaderSize(src, chunkSize+aead.Overhead())
+	buf := make([]byte, chunkSize+aead.Overhead())
+	for index := uint32(0); ; index++ {
+		n, last, err := readChunk(r, buf)
+		if err != nil {
+			return err
+		}
+
+		plaintext, err := aead.Open(buf[:0], chunkNonce(header, index, last), buf[:n], header)
+		if err != nil {
+			return ErrInvalidFormat
+		}
+
+		if _, err := dst.Write(plaintext); err != nil {
+			return err
+		}
+
+		if last {
+			return nil
+		}
+	}
+}
+
+// readChunk fills the buffer from the reader, the chunk is the last one if the reader has no more data after it
+func readChunk(r *bufio.Reader, buf []byte) (int, bool, error) {
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return n, true, nil
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	if _, err := r.Peek(1); err == io.EOF {
+		return n, true, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	return n, false, nil
+}
+
+// chunkNonce returns the nonce of the chunk, it binds the position of the chunk in the stream
+func chunkNonce(header []byte, index uint32, last bool) []byte {
+	nonce := make([]byte, noncePrefixSize+5)
+	copy(nonce, header[headerSize-noncePrefixSize:])
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], index)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+
+	return nonce
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, xerrors.Errorf("key size must be %d", KeySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,111 @@
+package assetcrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	titanrsa "github.com/linguohua/titan/node/rsa"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key error:%s", err.Error())
+	}
+
+	chunkSize := 16
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3 * chunkSize, 3*chunkSize + 5} {
+		content := make([]byte, size)
+		if _, err := io.ReadFull(rand.Reader, content); err != nil {
+			t.Fatal(err)
+		}
+
+		encrypted := &bytes.Buffer{}
+		if err := Encrypt(encrypted, bytes.NewReader(content), key, chunkSize); err != nil {
+			t.Fatalf("size %d encrypt error:%s", size, err.Error())
+		}
+
+		if size > 0 && bytes.Contains(encrypted.Bytes(), content) {
+			t.Fatalf("size %d content is not encrypted", size)
+		}
+
+		decrypted := &bytes.Buffer{}
+		if err := Decrypt(decrypted, bytes.NewReader(encrypted.Bytes()), key); err != nil {
+			t.Fatalf("size %d decrypt error:%s", size, err.Error())
+		}
+
+		if !bytes.Equal(decrypted.Bytes(), content) {
+			t.Fatalf("size %d decrypted content does not match", size)
+		}
+	}
+}
+
+func TestDecryptRejects(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key error:%s", err.Error())
+	}
+
+	content := bytes.Repeat([]byte("titan"), 20)
+	encrypted := &bytes.Buffer{}
+	if err := Encrypt(encrypted, bytes.NewReader(content), key, 16); err != nil {
+		t.Fatalf("encrypt error:%s", err.Error())
+	}
+	data := encrypted.Bytes()
+
+	otherKey, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key error:%s", err.Error())
+	}
+
+	tampered := append([]byte(nil), data...)
+	tampered[headerSize+3] ^= 1
+
+	// drop the last chunk, the stream ends at a chunk boundary
+	truncated := data[:headerSize+2*(16+16)]
+
+	cases := map[string]struct {
+		data []byte
+		key  []byte
+	}{
+		"wrong key": {data, otherKey},
+		"tampered":  {tampered, key},
+		"truncated": {truncated, key},
+		"header":    {data[:headerSize], key},
+		"plaintext": {content, key},
+	}
+
+	for name, c := range cases {
+		if err := Decrypt(io.Discard, bytes.NewReader(c.data), c.key); err == nil {
+			t.Errorf("%s: expect decrypt error", name)
+		}
+	}
+}
+
+func TestWrapKey(t *testing.T) {
+	priv, err := titanrsa.GeneratePrivateKey(1024)
+	if err != nil {
+		t.Fatalf("generate private key error:%s", err.Error())
+	}
+
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key error:%s", err.Error())
+	}
+
+	wrapped, err := WrapKey(key, &priv.PublicKey)
+	if err != nil {
+		t.Fatalf("wrap key error:%s", err.Error())
+	}
+
+	unwrapped, err := UnwrapKey(wrapped, priv)
+	if err != nil {
+		t.Fatalf("unwrap key error:%s", err.Error())
+	}
+
+	if !bytes.Equal(key, unwrapped) {
+		t.Fatal("unwrapped key does not match")
+	}
+}
@@ -0,0 +1,351 @@
+// Package erasure implements the Reed-Solomon erasure coding of the assets stored in shards.
+//
+// An asset is split into k data shards and m parity shards, it can be rebuilt from any k of them.
+// The coding matrix is systematic, so the data shards hold the content itself. A shard stream is a header followed by stripes:
+//
+//	header: magic "TESH" | version (1 byte) | data shards (1 byte) | parity shards (1 byte) | shard index (1 byte) |
+//	        stripe size (4 bytes, big endian) | content size (8 bytes, big endian)
+//	stripe: stripe size bytes of the shard
+//
+// The content is read in rows of k stripes, the last row is padded with zeros.
+package erasure
+
+import (
+	"encoding/binary"
+	"io"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// MaxShards is the maximum number of data and parity shards of an asset
+	MaxShards = 256
+	// StripeSize is the size of the stripe a shard is written in
+	StripeSize = 64 << 10
+
+	version    = 1
+	headerSize = 20
+)
+
+var magic = []byte("TESH")
+
+var (
+	// ErrTooFewShards is returned when fewer than data shards are available to reconstruct
+	ErrTooFewShards = xerrors.New("too few shards")
+	// ErrShardSize is returned when the shards do not have the same size
+	ErrShardSize = xerrors.New("shards must have the same size")
+	// ErrInvalidShard is returned when a shard stream is not written with the coding of the encoder
+	ErrInvalidShard = xerrors.New("invalid shard stream")
+
+	errSingular = xerrors.New("matrix is singular")
+)
+
+// Encoder encodes the data shards into the parity shards and reconstructs the lost shards
+type Encoder struct {
+	dataShards   int
+	parityShards int
+	// matrix has a row of each shard, the rows of the data shards make the identity matrix
+	matrix matrix
+}
+
+// New creates an encoder of the number of data and parity shards
+func New(dataShards, parityShards int) (*Encoder, error) {
+	if dataShards <= 0 || parityShards <= 0 {
+		return nil, xerrors.New("the number of data and parity shards must be positive")
+	}
+
+	if dataShards+parityShards > MaxShards {
+		return nil, xerrors.Errorf("the total number of shards can not be greater than %d", MaxShards)
+	}
+
+	total := dataShards + parityShards
+	v := vandermonde(total, dataShards)
+	inv, err := v[:dataShards].invert()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Encoder{dataShards: dataShards, parityShards: parityShards, matrix: v.multiply(inv)}, nil
+}
+
+// DataShards returns the number of data shards
+func (e *Encoder) DataShards() int {
+	return e.dataShards
+}
+
+// ParityShards returns the number of parity shards
+func (e *Encoder) ParityShards() int {
+	return e.parityShards
+}
+
+// Encode computes the parity shards from the data shards, the parity shards are allocated if their size is wrong
+func (e *Encoder) Encode(shards [][]byte) error {
+	if len(shards) != e.dataShards+e.parityShards {
+		return xerrors.Errorf("expect %d shards, got %d", e.dataShards+e.parityShards, len(shards))
+	}
+
+	size := len(shards[0])
+	for _, shard := range shards[1:e.dataShards] {
+		if len(shard) != size {
+			return ErrShardSize
+		}
+	}
+
+	for i := e.dataShards; i < len(shards); i++ {
+		if len(shards[i]) != size {
+			shards[i] = make([]byte, size)
+		}
+		e.encodeShard(shards, i)
+	}
+
+	return nil
+}
+
+// encodeShard computes the shard of the index from the data shards
+func (e *Encoder) encodeShard(shards [][]byte, index int) {
+	out := shards[index]
+	for i := range out {
+		out[i] = 0
+	}
+
+	for c := 0; c < e.dataShards; c++ {
+		galMulAdd(e.matrix[index][c], shards[c], out)
+	}
+}
+
+// Reconstruct rebuilds the missing shards, a shard is missing if it is empty.
+// ErrTooFewShards is returned if fewer than data shards are present
+func (e *Encoder) Reconstruct(shards [][]byte) error {
+	return e.reconstruct(shards, false)
+}
+
+// ReconstructData rebuilds the missing data shards only
+func (e *Encoder) ReconstructData(shards [][]byte) error {
+	return e.reconstruct(shards, true)
+}
+
+func (e *Encoder) reconstruct(shards [][]byte, dataOnly bool) error {
+	if len(shards) != e.dataShards+e.parityShards {
+		return xerrors.Errorf("expect %d shards, got %d", e.dataShards+e.parityShards, len(shards))
+	}
+
+	size := 0
+	present := make([]int, 0, e.dataShards)
+	for i, shard := range shards {
+		if len(shard) == 0 {
+			continue
+		}
+
+		if size == 0 {
+			size = len(shard)
+		} else if len(shard) != size {
+			return ErrShardSize
+		}
+
+		if len(present) < e.dataShards {
+			present = append(present, i)
+		}
+	}
+
+	if len(present) < e.dataShards {
+		return ErrTooFewShards
+	}
+
+	sub := newMatrix(e.dataShards, e.dataShards)
+	for r, index := range present {
+		copy(sub[r], e.matrix[index])
+	}
+
+	dec, err := sub.invert()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < e.dataShards; i++ {
+		if len(shards[i]) != 0 {
+			continue
+		}
+
+		out := make([]byte, size)
+		for c, index := range present {
+			galMulAdd(dec[i][c], shards[index], out)
+		}
+		shards[i] = out
+	}
+
+	if dataOnly {
+		return nil
+	}
+
+	for i := e.dataShards; i < len(shards); i++ {
+		if len(shards[i]) == 0 {
+			shards[i] = make([]byte, size)
+			e.encodeShard(shards, i)
+		}
+	}
+
+	return nil
+}
+
+// EncodeStream splits the content of the size read from src into the shard streams written to dst, dst holds a writer of each shard
+func (e *Encoder) EncodeStream(dst []io.Writer, src io.Reader, size int64) error {
+	total := e.dataShards + e.parityShards
+	if len(dst) != total {
+		return xerrors.Errorf("expect %d writers, got %d", total, len(dst))
+	}
+
+	if size < 0 {
+		return xerrors.Errorf("invalid size %d", size)
+	}
+
+	for i, w := range dst {
+		if _, err := w.Write(e.header(i, size)); err != nil {
+			return err
+		}
+	}
+
+	row := make([]byte, e.dataShards*StripeSize)
+	shards := make([][]byte, total)
+	for i := 0; i < e.dataShards; i++ {
+		shards[i] = row[i*StripeSize : (i+1)*StripeSize]
+	}
+
+	for remaining := size; remaining > 0; {
+		n := int64(len(row))
+		if remaining < n {
+			n = remaining
+		}
+
+		if _, err := io.ReadFull(src, row[:n]); err != nil {
+			return xerrors.Errorf("read content: %w", err)
+		}
+
+		for i := n; i < int64(len(row)); i++ {
+			row[i] = 0
+		}
+
+		if err := e.Encode(shards); err != nil {
+			return err
+		}
+
+		for i, w := range dst {
+			if _, err := w.Write(shards[i]); err != nil {
+				return err
+			}
+		}
+
+		remaining -= n
+	}
+
+	return nil
+}
+
+// DecodeStream writes the content rebuilt from the shard streams to dst, src holds a reader of each shard and nil for the missing ones.
+// Only the first data shards readers are read, the content written before an error must be discarded
+func (e *Encoder) DecodeStream(dst io.Writer, src []io.Reader) error {
+	total := e.dataShards + e.parityShards
+	if len(src) != total {
+		return xerrors.Errorf("expect %d readers, got %d", total, len(src))
+	}
+
+	size := int64(-1)
+	readers := make([]io.Reader, total)
+	count := 0
+	for i, r := range src {
+		if r == nil || count == e.dataShards {
+			continue
+		}
+
+		header := make([]byte, headerSize)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return ErrInvalidShard
+		}
+
+		s, err := e.parseHeader(header, i)
+		if err != nil {
+			return err
+		}
+
+		if size >= 0 && s != size {
+			return ErrInvalidShard
+		}
+
+		size = s
+		readers[i] = r
+		count++
+	}
+
+	if count < e.dataShards {
+		return ErrTooFewShards
+	}
+
+	shards := make([][]byte, total)
+	for remaining := size; remaining > 0; {
+		for i, r := range readers {
+			shards[i] = nil
+			if r == nil {
+				continue
+			}
+
+			shards[i] = make([]byte, StripeSize)
+			if _, err := io.ReadFull(r, shards[i]); err != nil {
+				return ErrInvalidShard
+			}
+		}
+
+		if err := e.ReconstructData(shards); err != nil {
+			return err
+		}
+
+		for i := 0; i < e.dataShards && remaining > 0; i++ {
+			n := int64(StripeSize)
+			if remaining < n {
+				n = remaining
+			}
+
+			if _, err := dst.Write(shards[i][:n]); err != nil {
+				return err
+			}
+
+			remaining -= n
+		}
+	}
+
+	return nil
+}
+
+// header returns the header of the shard stream of the index
+func (e *Encoder) header(index int, size int64) []byte {
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	header[4] = version
+	header[5] = byte(e.dataShards)
+	header[6] = byte(e.parityShards)
+	header[7] = byte(index)
+	binary.BigEndian.PutUint32(header[8:12], StripeSize)
+	binary.BigEndian.PutUint64(header[12:20], uint64(size))
+
+	return header
+}
+
+// parseHeader checks the header of the shard stream of the index and returns the content size
+func (e *Encoder) parseHeader(header []byte, index int) (int64, error) {
+	if string(header[:4]) != string(magic) || header[4] != version {
+		return 0, ErrInvalidShard
+	}
+
+	if int(header[5]) != e.dataShards || int(header[6]) != e.parityShards || int(header[7]) != index {
+		return 0, ErrInvalidShard
+	}
+
+	if binary.BigEndian.Uint32(header[8:12]) != StripeSize {
+		return 0, ErrInvalidShard
+	}
+
+	size := binary.BigEndian.Uint64(header[12:20])
+	if size > 1<<62 {
+		return 0, ErrInvalidShard
+	}
+
+	return int64(size), nil
+}
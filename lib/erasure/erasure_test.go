@@ -0,0 +1,122 @@
+package erasure
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+func TestReconstruct(t *testing.T) {
+	e, err := New(4, 2)
+	if err != nil {
+		t.Fatalf("new encoder error:%s", err.Error())
+	}
+
+	shards := make([][]byte, 6)
+	for i := 0; i < 4; i++ {
+		shards[i] = make([]byte, 100)
+		if _, err := io.ReadFull(rand.Reader, shards[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := e.Encode(shards); err != nil {
+		t.Fatalf("encode error:%s", err.Error())
+	}
+
+	// every pair of lost shards can be reconstructed
+	for a := 0; a < 6; a++ {
+		for b := a + 1; b < 6; b++ {
+			lost := make([][]byte, 6)
+			copy(lost, shards)
+			lost[a], lost[b] = nil, nil
+
+			if err := e.Reconstruct(lost); err != nil {
+				t.Fatalf("lost %d %d reconstruct error:%s", a, b, err.Error())
+			}
+
+			for i := range shards {
+				if !bytes.Equal(lost[i], shards[i]) {
+					t.Fatalf("lost %d %d shard %d does not match", a, b, i)
+				}
+			}
+		}
+	}
+
+	lost := make([][]byte, 6)
+	copy(lost, shards)
+	lost[0], lost[3], lost[5] = nil, nil, nil
+	if err := e.Reconstruct(lost); err != ErrTooFewShards {
+		t.Fatalf("expect too few shards error, got %v", err)
+	}
+}
+
+func TestStream(t *testing.T) {
+	e, err := New(3, 2)
+	if err != nil {
+		t.Fatalf("new encoder error:%s", err.Error())
+	}
+
+	row := 3 * StripeSize
+	for _, size := range []int{0, 1, StripeSize + 7, row, 2*row + 11} {
+		content := make([]byte, size)
+		if _, err := io.ReadFull(rand.Reader, content); err != nil {
+			t.Fatal(err)
+		}
+
+		bufs := make([]*bytes.Buffer, 5)
+		writers := make([]io.Writer, 5)
+		for i := range bufs {
+			bufs[i] = &bytes.Buffer{}
+			writers[i] = bufs[i]
+		}
+
+		if err := e.EncodeStream(writers, bytes.NewReader(content), int64(size)); err != nil {
+			t.Fatalf("size %d encode error:%s", size, err.Error())
+		}
+
+		for _, lost := range [][]int{{}, {0, 1}, {2, 4}, {0, 2}} {
+			readers := make([]io.Reader, 5)
+			for i := range readers {
+				readers[i] = bytes.NewReader(bufs[i].Bytes())
+			}
+
+			for _, i := range lost {
+				readers[i] = nil
+			}
+
+			out := &bytes.Buffer{}
+			if err := e.DecodeStream(out, readers); err != nil {
+				t.Fatalf("size %d lost %v decode error:%s", size, lost, err.Error())
+			}
+
+			if !bytes.Equal(out.Bytes(), content) {
+				t.Fatalf("size %d lost %v content does not match", size, lost)
+			}
+		}
+
+		readers := []io.Reader{nil, bytes.NewReader(bufs[1].Bytes()), nil, nil, bytes.NewReader(bufs[4].Bytes())}
+		if err := e.DecodeStream(io.Discard, readers); err != ErrTooFewShards {
+			t.Fatalf("size %d expect too few shards error, got %v", size, err)
+		}
+
+		// a shard stream at the position of another shard is rejected
+		readers = []io.Reader{bytes.NewReader(bufs[1].Bytes()), bytes.NewReader(bufs[0].Bytes()), bytes.NewReader(bufs[2].Bytes()), nil, nil}
+		if err := e.DecodeStream(io.Discard, readers); err != ErrInvalidShard {
+			t.Fatalf("size %d expect invalid shard error, got %v", size, err)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, c := range [][2]int{{0, 1}, {1, 0}, {200, 57}} {
+		if _, err := New(c[0], c[1]); err == nil {
+			t.Errorf("data %d parity %d: expect error", c[0], c[1])
+		}
+	}
+
+	if _, err := New(200, 56); err != nil {
+		t.Errorf("new encoder error:%s", err.Error())
+	}
+}
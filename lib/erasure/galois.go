@@ -0,0 +1,151 @@
+package erasure
+
+// the arithmetic of GF(2^8) with the primitive polynomial x^8 + x^4 + x^3 + x^2 + 1
+const polynomial = 0x11d
+
+var (
+	expTable [510]byte
+	logTable [256]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		expTable[i] = byte(x)
+		expTable[i+255] = byte(x)
+		logTable[x] = byte(i)
+
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= polynomial
+		}
+	}
+}
+
+// galMul multiplies two elements of the field
+func galMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+// galInv returns the multiplicative inverse of a non-zero element
+func galInv(a byte) byte {
+	return expTable[255-int(logTable[a])]
+}
+
+// galExp returns a raised to the power n
+func galExp(a byte, n int) byte {
+	if n == 0 {
+		return 1
+	}
+
+	if a == 0 {
+		return 0
+	}
+
+	return expTable[(int(logTable[a])*n)%255]
+}
+
+// galMulAdd adds c times the input to the output
+func galMulAdd(c byte, in, out []byte) {
+	if c == 0 {
+		return
+	}
+
+	logC := int(logTable[c])
+	for i, v := range in {
+		if v != 0 {
+			out[i] ^= expTable[logC+int(logTable[v])]
+		}
+	}
+}
+
+type matrix [][]byte
+
+func newMatrix(rows, cols int) matrix {
+	m := make(matrix, rows)
+	for i := range m {
+		m[i] = make([]byte, cols)
+	}
+
+	return m
+}
+
+// vandermonde returns the matrix whose element at row r and column c is r^c,
+// any square matrix made of its distinct rows is invertible
+func vandermonde(rows, cols int) matrix {
+	m := newMatrix(rows, cols)
+	for r := range m {
+		for c := range m[r] {
+			m[r][c] = galExp(byte(r), c)
+		}
+	}
+
+	return m
+}
+
+// multiply returns the product of the matrices
+func (m matrix) multiply(right matrix) matrix {
+	out := newMatrix(len(m), len(right[0]))
+	for r := range out {
+		for c := range out[r] {
+			var v byte
+			for i := range right {
+				v ^= galMul(m[r][i], right[i][c])
+			}
+			out[r][c] = v
+		}
+	}
+
+	return out
+}
+
+// invert returns the inverse of the square matrix by gauss-jordan elimination
+func (m matrix) invert() (matrix, error) {
+	size := len(m)
+	work := newMatrix(size, 2*size)
+	for r := range m {
+		copy(work[r], m[r])
+		work[r][size+r] = 1
+	}
+
+	for c := 0; c < size; c++ {
+		pivot := c
+		for pivot < size && work[pivot][c] == 0 {
+			pivot++
+		}
+
+		if pivot == size {
+			return nil, errSingular
+		}
+		work[c], work[pivot] = work[pivot], work[c]
+
+		if v := work[c][c]; v != 1 {
+			inv := galInv(v)
+			for i := range work[c] {
+				work[c][i] = galMul(work[c][i], inv)
+			}
+		}
+
+		for r := 0; r < size; r++ {
+			if r == c || work[r][c] == 0 {
+				continue
+			}
+
+			factor := work[r][c]
+			for i := range work[r] {
+				work[r][i] ^= galMul(factor, work[c][i])
+			}
+		}
+	}
+
+	out := newMatrix(size, size)
+	for r := range out {
+		copy(out[r], work[r][size:])
+	}
+
+	return out, nil
+}
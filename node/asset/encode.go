@@ -16,6 +16,7 @@ type AssetPullerEncoder struct {
 	DownloadSources         []*types.CandidateDownloadInfo
 	TotalSize               uint64
 	DoneSize                uint64
+	ErrMsg                  string
 }
 
 // Encode encodes the input value into a byte slice using gob encoding.
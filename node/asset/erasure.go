@@ -0,0 +1,481 @@
+package asset
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-libipfs/blocks"
+	"github.com/ipld/go-car/v2"
+	"github.com/linguohua/titan/api/types"
+	"github.com/linguohua/titan/lib/erasure"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// maxConcurrentShardTasks is the maximum number of assets encoded into shards or reconstructed at the same time
+	maxConcurrentShardTasks = 2
+	// shardDownloadTimeout is the timeout to download a shard from a source
+	shardDownloadTimeout = 30 * time.Minute
+)
+
+// EncodeAssetShards encodes the asset into erasure coded shards in the background, the shards are reported to the scheduler
+func (a *Asset) EncodeAssetShards(ctx context.Context, req *types.ShardEncodeReq) error {
+	if types.RunningNodeType != types.NodeCandidate {
+		return fmt.Errorf("only candidate can encode asset shards")
+	}
+
+	root, err := cid.Decode(req.CID)
+	if err != nil {
+		return err
+	}
+
+	enc, err := erasure.New(int(req.DataShards), int(req.ParityShards))
+	if err != nil {
+		return err
+	}
+
+	if has, err := a.mgr.AssetExists(root); err != nil {
+		return err
+	} else if !has {
+		return fmt.Errorf("asset %s not exist", req.CID)
+	}
+
+	if !a.mgr.startShardTask(a.mgr.encoding, root) {
+		return fmt.Errorf("asset %s is encoding", req.CID)
+	}
+
+	log.Debugf("encode asset %s into %d+%d shards", req.CID, req.DataShards, req.ParityShards)
+
+	go func() {
+		defer a.mgr.finishShardTask(a.mgr.encoding, root)
+
+		result := a.mgr.encodeAssetShards(context.Background(), root, enc)
+		if err := a.scheduler.NodeShardEncodeResult(context.Background(), result); err != nil {
+			log.Errorf("shard encode result %s err:%s", req.CID, err.Error())
+		}
+	}()
+
+	return nil
+}
+
+// ReconstructAsset rebuilds the erasure coded asset from its shards in the background, the asset is reported as pulling until it is stored
+func (a *Asset) ReconstructAsset(ctx context.Context, req *types.ShardReconstructReq) error {
+	if types.RunningNodeType != types.NodeCandidate {
+		return fmt.Errorf("only candidate can reconstruct asset")
+	}
+
+	root, err := cid.Decode(req.CID)
+	if err != nil {
+		return err
+	}
+
+	enc, err := erasure.New(int(req.DataShards), int(req.ParityShards))
+	if err != nil {
+		return err
+	}
+
+	if len(req.Shards) < enc.DataShards() {
+		return fmt.Errorf("asset %s needs %d shards, got %d", req.CID, enc.DataShards(), len(req.Shards))
+	}
+
+	if has, err := a.mgr.AssetExists(root); err != nil {
+		return err
+	} else if has {
+		return nil
+	}
+
+	if !a.mgr.startShardTask(a.mgr.reconstructing, root) {
+		return fmt.Errorf("asset %s is reconstructing", req.CID)
+	}
+
+	log.Debugf("reconstruct asset %s from %d shards", req.CID, len(req.Shards))
+
+	go func() {
+		defer a.mgr.finishShardTask(a.mgr.reconstructing, root)
+
+		if err := a.mgr.reconstructAsset(context.Background(), root, enc, req.Shards); err != nil {
+			log.Errorf("reconstruct asset %s err:%s", req.CID, err.Error())
+		}
+	}()
+
+	return nil
+}
+
+// startShardTask adds the asset to the tasks, false is returned if the asset is already in the tasks
+func (m *Manager) startShardTask(tasks map[string]struct{}, root cid.Cid) bool {
+	m.shardLock.Lock()
+	defer m.shardLock.Unlock()
+
+	if _, ok := tasks[root.Hash().String()]; ok {
+		return false
+	}
+
+	tasks[root.Hash().String()] = struct{}{}
+	return true
+}
+
+// finishShardTask removes the asset from the tasks
+func (m *Manager) finishShardTask(tasks map[string]struct{}, root cid.Cid) {
+	m.shardLock.Lock()
+	defer m.shardLock.Unlock()
+
+	delete(tasks, root.Hash().String())
+}
+
+// isReconstructing checks if the asset is being reconstructed from its shards
+func (m *Manager) isReconstructing(root cid.Cid) bool {
+	m.shardLock.Lock()
+	defer m.shardLock.Unlock()
+
+	_, ok := m.reconstructing[root.Hash().String()]
+	return ok
+}
+
+// encodeAssetShards encodes the asset into shards, the failure is set to the result
+func (m *Manager) encodeAssetShards(ctx context.Context, root cid.Cid, enc *erasure.Encoder) *types.ShardEncodeResult {
+	m.shardCh <- struct{}{}
+	defer func() { <-m.shardCh }()
+
+	result := &types.ShardEncodeResult{CID: root.String()}
+
+	shards, err := m.storeAssetShards(ctx, root, enc)
+	if err != nil {
+		log.Errorf("encode asset %s shards err:%s", root.String(), err.Error())
+		result.Err = err.Error()
+		return result
+	}
+
+	result.Shards = shards
+	return result
+}
+
+// storeAssetShards encodes the car of the asset into shard files, then stores every shard file as an asset
+func (m *Manager) storeAssetShards(ctx context.Context, root cid.Cid, enc *erasure.Encoder) ([]*types.AssetShard, error) {
+	reader, err := m.GetAsset(root)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close() //nolint:errcheck
+
+	size, err := reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	total := enc.DataShards() + enc.ParityShards()
+	files := make([]*os.File, 0, total)
+	defer func() {
+		for _, f := range files {
+			f.Close()           //nolint:errcheck
+			os.Remove(f.Name()) //nolint:errcheck
+		}
+	}()
+
+	writers := make([]io.Writer, 0, total)
+	for i := 0; i < total; i++ {
+		f, err := os.CreateTemp("", "titan-shard-*")
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+
+		if err := writeShardPrefix(f, root); err != nil {
+			return nil, err
+		}
+		writers = append(writers, f)
+	}
+
+	if err := enc.EncodeStream(writers, reader, size); err != nil {
+		return nil, xerrors.Errorf("encode: %w", err)
+	}
+
+	shards := make([]*types.AssetShard, 0, total)
+	for i, f := range files {
+		shard, err := m.storeShardFile(ctx, f, i)
+		if err != nil {
+			for _, s := range shards {
+				if e := m.removeShard(s.CID); e != nil {
+					log.Errorf("remove shard %s error:%s", s.CID, e.Error())
+				}
+			}
+			return nil, xerrors.Errorf("store shard %d: %w", i, err)
+		}
+
+		shards = append(shards, shard)
+	}
+
+	return shards, nil
+}
+
+// storeShardFile stores the shard file as an asset with the default UnixFS options
+func (m *Manager) storeShardFile(ctx context.Context, f *os.File, index int) (*types.AssetShard, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	shardRoot, blockCount, _, err := m.StoreFile(ctx, f, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AssetShard{Index: int64(index), CID: shardRoot.String(), Size: info.Size(), Blocks: int64(blockCount)}, nil
+}
+
+// removeShard removes the stored shard
+func (m *Manager) removeShard(shardCID string) error {
+	c, err := cid.Decode(shardCID)
+	if err != nil {
+		return err
+	}
+
+	return m.DeleteAsset(c)
+}
+
+// reconstructAsset downloads the first data shards that can be verified, then decodes them into the car of the asset and stores it
+func (m *Manager) reconstructAsset(ctx context.Context, root cid.Cid, enc *erasure.Encoder, shards []*types.ShardSource) error {
+	m.shardCh <- struct{}{}
+	defer func() { <-m.shardCh }()
+
+	total := enc.DataShards() + enc.ParityShards()
+	readers := make([]io.Reader, total)
+	files := make([]*os.File, 0, enc.DataShards())
+	defer func() {
+		for _, f := range files {
+			f.Close()           //nolint:errcheck
+			os.Remove(f.Name()) //nolint:errcheck
+		}
+	}()
+
+	count := 0
+	for _, shard := range shards {
+		if count == enc.DataShards() {
+			break
+		}
+
+		if shard.Index < 0 || shard.Index >= int64(total) || readers[shard.Index] != nil {
+			log.Warnf("reconstruct asset %s, invalid shard index %d", root.String(), shard.Index)
+			continue
+		}
+
+		f, err := m.downloadShard(ctx, root, shard)
+		if err != nil {
+			log.Warnf("reconstruct asset %s, download shard %d err:%s", root.String(), shard.Index, err.Error())
+			continue
+		}
+		files = append(files, f)
+
+		readers[shard.Index] = f
+		count++
+	}
+
+	if count < enc.DataShards() {
+		return xerrors.Errorf("%d of %d shards downloaded: %w", count, enc.DataShards(), erasure.ErrTooFewShards)
+	}
+
+	carFile, err := os.CreateTemp("", "titan-reconstruct-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(carFile.Name()) //nolint:errcheck
+	defer carFile.Close()           //nolint:errcheck
+
+	if err := enc.DecodeStream(carFile, readers); err != nil {
+		return xerrors.Errorf("decode: %w", err)
+	}
+
+	if _, err := carFile.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	return m.storeCar(ctx, root, carFile)
+}
+
+// downloadShard downloads the shard from its sources in turn, the shard file is verified against the shard cid and the asset root.
+// The returned file is positioned after the shard prefix
+func (m *Manager) downloadShard(ctx context.Context, root cid.Cid, shard *types.ShardSource) (*os.File, error) {
+	shardCID, err := cid.Decode(shard.CID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, source := range shard.Sources {
+		f, err := os.CreateTemp("", "titan-shard-*")
+		if err != nil {
+			return nil, err
+		}
+
+		err = downloadShardFrom(ctx, source, shardCID, f)
+		if err == nil {
+			err = verifyShardFile(f, shardCID, root)
+		}
+
+		if err == nil {
+			return f, nil
+		}
+
+		log.Warnf("download shard %s from %s err:%s", shard.CID, source.URL, err.Error())
+		f.Close()           //nolint:errcheck
+		os.Remove(f.Name()) //nolint:errcheck
+	}
+
+	return nil, xerrors.Errorf("no source of shard %s succeeded", shard.CID)
+}
+
+// downloadShardFrom downloads the file of the shard from the source to the writer
+func downloadShardFrom(ctx context.Context, source *types.CandidateDownloadInfo, shardCID cid.Cid, w io.Writer) error {
+	body, err := encode(source.Credentials)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, shardDownloadTimeout)
+	defer cancel()
+
+	u := fmt.Sprintf("http://%s/ipfs/%s", source.URL, shardCID.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("http status code %d", resp.StatusCode)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// verifyShardFile checks the shard file builds into the shard cid with the default UnixFS options and belongs to the asset,
+// the file is positioned after the shard prefix
+func verifyShardFile(f *os.File, shardCID, root cid.Cid) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	hasher := newAssetDAGService(cid.Undef, nil)
+	nd, err := buildUnixFS(f, defaultUnixFSOptions(), hasher)
+	if err != nil {
+		return err
+	}
+
+	if !nd.Cid().Equals(shardCID) {
+		return xerrors.Errorf("shard cid %s does not match %s", nd.Cid().String(), shardCID.String())
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	return readShardPrefix(f, root)
+}
+
+// writeShardPrefix writes the root of the asset before the shard stream, so the shards of different assets never share a cid
+func writeShardPrefix(w io.Writer, root cid.Cid) error {
+	prefix := make([]byte, 2, 2+root.ByteLen())
+	binary.BigEndian.PutUint16(prefix, uint16(root.ByteLen()))
+	prefix = append(prefix, root.Bytes()...)
+
+	_, err := w.Write(prefix)
+	return err
+}
+
+// readShardPrefix reads the root of the asset before the shard stream, an error is returned if it is not the root
+func readShardPrefix(r io.Reader, root cid.Cid) error {
+	size := make([]byte, 2)
+	if _, err := io.ReadFull(r, size); err != nil {
+		return erasure.ErrInvalidShard
+	}
+
+	buf := make([]byte, binary.BigEndian.Uint16(size))
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return erasure.ErrInvalidShard
+	}
+
+	if !bytes.Equal(buf, root.Bytes()) {
+		return xerrors.Errorf("shard is not of asset %s", root.String())
+	}
+
+	return nil
+}
+
+// storeCar stores the blocks of the car as the asset of the root, the block reader checks every block against its cid
+func (m *Manager) storeCar(ctx context.Context, root cid.Cid, r io.Reader) (err error) {
+	br, err := car.NewBlockReader(r)
+	if err != nil {
+		return err
+	}
+
+	if len(br.Roots) != 1 || !br.Roots[0].Equals(root) {
+		return xerrors.Errorf("car roots %v do not match %s", br.Roots, root.String())
+	}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		if e := m.DeleteBlocks(root); e != nil {
+			log.Errorf("remove asset blocks error:%s", e.Error())
+		}
+	}()
+
+	cids := make(map[cid.Cid]struct{})
+	batch := make([]blocks.Block, 0, urlBlocksBatch)
+	for {
+		blk, err := br.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if _, ok := cids[blk.Cid()]; ok {
+			continue
+		}
+		cids[blk.Cid()] = struct{}{}
+
+		if batch = append(batch, blk); len(batch) >= urlBlocksBatch {
+			if err := m.StoreBlocks(ctx, root, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if _, ok := cids[root]; !ok {
+		return xerrors.Errorf("car does not hold the root block %s", root.String())
+	}
+
+	if len(batch) > 0 {
+		if err := m.StoreBlocks(ctx, root, batch); err != nil {
+			return err
+		}
+	}
+
+	if err := m.SetBlockCount(ctx, root, uint32(len(cids))); err != nil {
+		return err
+	}
+
+	return m.StoreAsset(ctx, root)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -20,6 +21,7 @@ import (
 type CandidateFetcher struct {
 	retryCount int
 	httpClient *http.Client
+	health     *healthTracker
 }
 
 // NewCandidateFetcher creates a new CandidateFetcher with the specified timeout and retry count
@@ -33,16 +35,21 @@ func NewCandidateFetcher(timeout, retryCount int) *CandidateFetcher {
 		Transport: t,
 	}
 
-	return &CandidateFetcher{retryCount: retryCount, httpClient: httpClient}
+	return &CandidateFetcher{retryCount: retryCount, httpClient: httpClient, health: newHealthTracker()}
+}
+
+// SourceStats returns the health stats of the given download sources
+func (c *CandidateFetcher) SourceStats(dss []*types.CandidateDownloadInfo) []*types.DownloadSourceStats {
+	return c.health.stats(dss)
 }
 
 // FetchBlocks fetches blocks for the given cids and candidate download info
 func (c *CandidateFetcher) FetchBlocks(ctx context.Context, cids []string, dss []*types.CandidateDownloadInfo) ([]blocks.Block, error) {
-	return c.retrieveBlocks(cids, dss)
+	return c.retrieveBlocks(ctx, cids, dss)
 }
 
 // fetchSingleBlock fetches a single block for the given candidate download info and cid string
-func (c *CandidateFetcher) fetchSingleBlock(ds *types.CandidateDownloadInfo, cidStr string) (blocks.Block, error) {
+func (c *CandidateFetcher) fetchSingleBlock(ctx context.Context, ds *types.CandidateDownloadInfo, cidStr string) (blocks.Block, error) {
 	if len(ds.URL) == 0 {
 		return nil, fmt.Errorf("candidate address can not empty")
 	}
@@ -52,7 +59,7 @@ func (c *CandidateFetcher) fetchSingleBlock(ds *types.CandidateDownloadInfo, cid
 	}
 	url := fmt.Sprintf("http://%s/ipfs/%s?format=raw", ds.URL, cidStr)
 
-	req, err := http.NewRequest(http.MethodGet, url, buf)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, buf)
 	if err != nil {
 		return nil, err
 	}
@@ -68,6 +75,9 @@ func (c *CandidateFetcher) fetchSingleBlock(ds *types.CandidateDownloadInfo, cid
 		if err != nil {
 			return nil, err
 		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			return nil, fmt.Errorf("%w: %s", ErrUnauthorized, string(data))
+		}
 		return nil, fmt.Errorf("http status code: %d, error msg: %s", resp.StatusCode, string(data))
 	}
 
@@ -90,7 +100,7 @@ func (c *CandidateFetcher) fetchSingleBlock(ds *types.CandidateDownloadInfo, cid
 }
 
 // retrieveBlocks retrieves multiple blocks using the given cids and candidate download info
-func (c *CandidateFetcher) retrieveBlocks(cids []string, dss []*types.CandidateDownloadInfo) ([]blocks.Block, error) {
+func (c *CandidateFetcher) retrieveBlocks(ctx context.Context, cids []string, dss []*types.CandidateDownloadInfo) ([]blocks.Block, error) {
 	if len(dss) == 0 {
 		return nil, fmt.Errorf("download infos can not empty")
 	}
@@ -98,25 +108,50 @@ func (c *CandidateFetcher) retrieveBlocks(cids []string, dss []*types.CandidateD
 	blks := make([]blocks.Block, 0, len(cids))
 	// candidates := make(map[string]api.CandidateFetcher)
 	blksLock := &sync.Mutex{}
+	unauthorized := false
 
 	var wg sync.WaitGroup
 
-	for index, cid := range cids {
+	// every source is tried before the block fails
+	attempts := c.retryCount
+	if attempts < len(dss) {
+		attempts = len(dss)
+	}
+
+	for _, cid := range cids {
 		cidStr := cid
-		i := index % len(dss)
-		ds := dss[i]
 
 		wg.Add(1)
 
 		go func() {
 			defer wg.Done()
 
-			for i := 0; i < c.retryCount; i++ {
-				b, err := c.fetchSingleBlock(ds, cidStr)
+			failed := make(map[string]struct{})
+			for i := 0; i < attempts && ctx.Err() == nil; i++ {
+				ds := c.health.pick(dss, failed)
+				if ds == nil {
+					// all sources failed, retry them again
+					failed = make(map[string]struct{})
+					ds = c.health.pick(dss, failed)
+				}
+
+				start := time.Now()
+				b, err := c.fetchSingleBlock(ctx, ds, cidStr)
 				if err != nil {
-					log.Errorf("getBlock error:%s, cid:%s", err.Error(), cidStr)
+					if ctx.Err() == nil {
+						c.health.onFailure(ds.URL)
+					}
+					failed[ds.URL] = struct{}{}
+					if errors.Is(err, ErrUnauthorized) {
+						blksLock.Lock()
+						unauthorized = true
+						blksLock.Unlock()
+					}
+					log.Errorf("getBlock error:%s, cid:%s, source:%s", err.Error(), cidStr, ds.URL)
 					continue
 				}
+				c.health.onSuccess(ds.URL, time.Since(start), len(b.RawData()))
+
 				blksLock.Lock()
 				blks = append(blks, b)
 				blksLock.Unlock()
@@ -126,6 +161,14 @@ func (c *CandidateFetcher) retrieveBlocks(cids []string, dss []*types.CandidateD
 	}
 	wg.Wait()
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(blks) != len(cids) && unauthorized {
+		return nil, ErrUnauthorized
+	}
+
 	return blks, nil
 }
 
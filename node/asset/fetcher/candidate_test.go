@@ -0,0 +1,27 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/linguohua/titan/api/types"
+)
+
+func TestCandidateFetcherUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "credentials expired", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	c := NewCandidateFetcher(5, 1)
+	dss := []*types.CandidateDownloadInfo{{URL: strings.TrimPrefix(server.URL, "http://"), Credentials: &types.GatewayCredentials{}}}
+
+	_, err := c.FetchBlocks(context.Background(), []string{"QmTcAg1KeDYJFpTJh3rkZGLhnnVKeXWNtjwPufjVvwPTpG"}, dss)
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+}
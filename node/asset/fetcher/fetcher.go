@@ -2,11 +2,15 @@ package fetcher
 
 import (
 	"context"
+	"errors"
 
 	"github.com/ipfs/go-libipfs/blocks"
 	"github.com/linguohua/titan/api/types"
 )
 
+// ErrUnauthorized is returned when the credentials of the download sources are rejected, e.g. expired
+var ErrUnauthorized = errors.New("download sources unauthorized")
+
 // BlockFetcher is an interface for fetching blocks from remote sources
 type BlockFetcher interface {
 	// FetchBlocks retrieves blocks with the given cids from remote sources using the provided CandidateDownloadInfo
@@ -0,0 +1,197 @@
+package fetcher
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+)
+
+const (
+	// healthDecay is the weight of the latest request in the moving averages
+	healthDecay = 0.2
+	// minSourceWeight keeps an unhealthy source probed so that it can recover
+	minSourceWeight = 0.05
+)
+
+// SourceReporter is implemented by the block fetchers that track the health of the download sources
+type SourceReporter interface {
+	// SourceStats returns the stats of the given download sources
+	SourceStats(dss []*types.CandidateDownloadInfo) []*types.DownloadSourceStats
+}
+
+// sourceHealth is the health of a download source
+type sourceHealth struct {
+	requests   int64
+	failures   int64
+	errorRate  float64 // moving average of the failures
+	latency    float64 // moving average of the request latency in milliseconds
+	throughput float64 // moving average of the download speed in bytes per second
+}
+
+// healthTracker tracks the health of the download sources and picks sources by their weights
+type healthTracker struct {
+	lock    sync.Mutex
+	sources map[string]*sourceHealth
+	rand    *rand.Rand
+}
+
+func newHealthTracker() *healthTracker {
+	return &healthTracker{
+		sources: make(map[string]*sourceHealth),
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// source returns the health of the source, the caller must hold the lock
+func (t *healthTracker) source(url string) *sourceHealth {
+	h, ok := t.sources[url]
+	if !ok {
+		h = &sourceHealth{}
+		t.sources[url] = h
+	}
+
+	return h
+}
+
+// onSuccess records a successful request to the source
+func (t *healthTracker) onSuccess(url string, latency time.Duration, size int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	h := t.source(url)
+	ms := float64(latency.Milliseconds())
+	if ms < 1 {
+		ms = 1
+	}
+	throughput := float64(size) * 1000 / ms
+
+	if h.requests == 0 {
+		h.latency = ms
+		h.throughput = throughput
+	} else {
+		h.latency += healthDecay * (ms - h.latency)
+		h.throughput += healthDecay * (throughput - h.throughput)
+	}
+
+	h.requests++
+	h.errorRate -= healthDecay * h.errorRate
+}
+
+// onFailure records a failed request to the source
+func (t *healthTracker) onFailure(url string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	h := t.source(url)
+	h.requests++
+	h.failures++
+	h.errorRate += healthDecay * (1 - h.errorRate)
+}
+
+// weights returns the weights of the sources, a source is weighted by its relative throughput and its success rate,
+// the caller must hold the lock
+func (t *healthTracker) weights(dss []*types.CandidateDownloadInfo) []float64 {
+	maxThroughput := 0.0
+	for _, ds := range dss {
+		if h, ok := t.sources[ds.URL]; ok && h.throughput > maxThroughput {
+			maxThroughput = h.throughput
+		}
+	}
+
+	weights := make([]float64, len(dss))
+	for i, ds := range dss {
+		h, ok := t.sources[ds.URL]
+		if !ok {
+			// unknown sources are explored with the full weight
+			weights[i] = 1
+			continue
+		}
+
+		w := 1 - h.errorRate
+		if maxThroughput > 0 {
+			w *= h.throughput / maxThroughput
+		}
+
+		if w < minSourceWeight {
+			w = minSourceWeight
+		}
+		weights[i] = w
+	}
+
+	return weights
+}
+
+// pick selects a source by the weights, the excluded sources are skipped, returns nil if no source is left
+func (t *healthTracker) pick(dss []*types.CandidateDownloadInfo, excludes map[string]struct{}) *types.CandidateDownloadInfo {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	weights := t.weights(dss)
+
+	total := 0.0
+	for i, ds := range dss {
+		if _, ok := excludes[ds.URL]; ok {
+			weights[i] = 0
+		}
+		total += weights[i]
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	r := t.rand.Float64() * total
+	for i, ds := range dss {
+		if weights[i] == 0 {
+			continue
+		}
+
+		r -= weights[i]
+		if r < 0 {
+			return ds
+		}
+	}
+
+	// rounding errors, return the last available source
+	for i := len(dss) - 1; i >= 0; i-- {
+		if weights[i] > 0 {
+			return dss[i]
+		}
+	}
+
+	return nil
+}
+
+// stats returns the stats of the sources
+func (t *healthTracker) stats(dss []*types.CandidateDownloadInfo) []*types.DownloadSourceStats {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	weights := t.weights(dss)
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+
+	stats := make([]*types.DownloadSourceStats, 0, len(dss))
+	for i, ds := range dss {
+		s := &types.DownloadSourceStats{URL: ds.URL}
+		if h, ok := t.sources[ds.URL]; ok {
+			s.Requests = h.requests
+			s.Failures = h.failures
+			s.ErrorRate = h.errorRate
+			s.Latency = int64(h.latency)
+			s.Throughput = int64(h.throughput)
+		}
+
+		if total > 0 {
+			s.Weight = weights[i] / total
+		}
+
+		stats = append(stats, s)
+	}
+
+	return stats
+}
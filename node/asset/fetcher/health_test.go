@@ -0,0 +1,55 @@
+package fetcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+)
+
+func TestHealthTrackerWeights(t *testing.T) {
+	tracker := newHealthTracker()
+	dss := []*types.CandidateDownloadInfo{{URL: "fast"}, {URL: "slow"}, {URL: "dead"}}
+
+	for i := 0; i < 10; i++ {
+		tracker.onSuccess("fast", 10*time.Millisecond, 1<<20)
+		tracker.onSuccess("slow", 100*time.Millisecond, 1<<20)
+		tracker.onFailure("dead")
+	}
+
+	stats := tracker.stats(dss)
+	if len(stats) != len(dss) {
+		t.Fatalf("expected %d stats, got %d", len(dss), len(stats))
+	}
+
+	fast, slow, dead := stats[0], stats[1], stats[2]
+	if fast.Weight <= slow.Weight || slow.Weight <= dead.Weight {
+		t.Errorf("unexpected weights fast:%f slow:%f dead:%f", fast.Weight, slow.Weight, dead.Weight)
+	}
+
+	if dead.Failures != 10 || dead.ErrorRate < 0.5 {
+		t.Errorf("unexpected dead source stats %#v", dead)
+	}
+
+	if fast.Failures != 0 || fast.Latency != 10 {
+		t.Errorf("unexpected fast source stats %#v", fast)
+	}
+}
+
+func TestHealthTrackerPick(t *testing.T) {
+	tracker := newHealthTracker()
+	dss := []*types.CandidateDownloadInfo{{URL: "a"}, {URL: "b"}}
+
+	excludes := map[string]struct{}{"a": {}}
+	for i := 0; i < 100; i++ {
+		ds := tracker.pick(dss, excludes)
+		if ds == nil || ds.URL != "b" {
+			t.Fatalf("expected source b, got %v", ds)
+		}
+	}
+
+	excludes["b"] = struct{}{}
+	if ds := tracker.pick(dss, excludes); ds != nil {
+		t.Fatalf("expected no source, got %s", ds.URL)
+	}
+}
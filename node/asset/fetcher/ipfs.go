@@ -40,8 +40,8 @@ func (ipfs *IPFSClient) FetchBlocks(ctx context.Context, cids []string, dss []*t
 }
 
 // retrieveBlock gets a block from IPFSClient with the specified CID
-func (ipfs *IPFSClient) retrieveBlock(cidStr string) (blocks.Block, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ipfs.timeout)*time.Second)
+func (ipfs *IPFSClient) retrieveBlock(ctx context.Context, cidStr string) (blocks.Block, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(ipfs.timeout)*time.Second)
 	defer cancel()
 
 	reader, err := ipfs.httpAPI.Block().Get(ctx, path.New(cidStr))
@@ -71,8 +71,8 @@ func (ipfs *IPFSClient) retrieveBlocks(ctx context.Context, cids []string) ([]bl
 		go func() {
 			defer wg.Done()
 
-			for i := 0; i < ipfs.retryCount; i++ {
-				b, err := ipfs.retrieveBlock(cidStr)
+			for i := 0; i < ipfs.retryCount && ctx.Err() == nil; i++ {
+				b, err := ipfs.retrieveBlock(ctx, cidStr)
 				if err != nil {
 					log.Errorf("getBlock error:%s, cid:%s", err.Error(), cidStr)
 					continue
@@ -87,6 +87,10 @@ func (ipfs *IPFSClient) retrieveBlocks(ctx context.Context, cids []string) ([]bl
 	}
 	wg.Wait()
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return blks, nil
 }
 
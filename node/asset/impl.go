@@ -228,6 +228,9 @@ func (a *Asset) progress(root cid.Cid) (*types.AssetPullProgress, error) {
 	case types.ReplicaStatusWaiting:
 		return &types.AssetPullProgress{CID: root.String(), Status: types.ReplicaStatusWaiting}, nil
 	case types.ReplicaStatusPulling:
+		if a.mgr.isReconstructing(root) {
+			return &types.AssetPullProgress{CID: root.String(), Status: types.ReplicaStatusPulling}, nil
+		}
 		return a.mgr.puller().getAssetProgress(), nil
 	case types.ReplicaStatusFailed:
 		return a.mgr.progressForAssetPulledFailed(root)
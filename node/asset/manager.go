@@ -11,6 +11,7 @@ import (
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
 	"github.com/ipfs/go-libipfs/blocks"
+	"github.com/linguohua/titan/api"
 	"github.com/linguohua/titan/api/types"
 	"github.com/linguohua/titan/node/asset/fetcher"
 	titanindex "github.com/linguohua/titan/node/asset/index"
@@ -19,7 +20,11 @@ import (
 	"golang.org/x/xerrors"
 )
 
-const maxSizeOfCache = 128
+const (
+	maxSizeOfCache = 128
+	// uploadGCInterval is the interval to remove the expired resumable uploads
+	uploadGCInterval = 10 * time.Minute
+)
 
 // assetWaiter is used by Manager to store waiting assets for pulling
 type assetWaiter struct {
@@ -37,6 +42,18 @@ type Manager struct {
 	pullParallel int
 	bFetcher     fetcher.BlockFetcher
 	lru          *lruCache
+	// urlPullCh limits the assets built from origin urls at the same time
+	urlPullCh chan struct{}
+	// shardCh limits the assets encoded into shards or reconstructed at the same time
+	shardCh chan struct{}
+	// the hashes of the assets being encoded into shards or reconstructed from shards
+	shardLock      sync.Mutex
+	encoding       map[string]struct{}
+	reconstructing map[string]struct{}
+	// uploadTTL is the time that an unfinished resumable upload is kept after its last write, the uploads are not removed if it is 0
+	uploadTTL time.Duration
+	// scheduler refreshes the download sources of the pulling assets
+	scheduler api.Scheduler
 	storage.Storage
 }
 
@@ -45,6 +62,9 @@ type ManagerOptions struct {
 	Storage      storage.Storage
 	BFetcher     fetcher.BlockFetcher
 	PullParallel int
+	UploadTTL    time.Duration
+	// Scheduler refreshes the download sources of the pulling assets, it is optional
+	Scheduler api.Scheduler
 }
 
 // NewManager creates a new instance of Manager
@@ -55,13 +75,19 @@ func NewManager(opts *ManagerOptions) (*Manager, error) {
 	}
 
 	m := &Manager{
-		waitList:     make([]*assetWaiter, 0),
-		waitListLock: &sync.Mutex{},
-		pullCh:       make(chan bool),
-		Storage:      opts.Storage,
-		bFetcher:     opts.BFetcher,
-		lru:          lru,
-		pullParallel: opts.PullParallel,
+		waitList:       make([]*assetWaiter, 0),
+		waitListLock:   &sync.Mutex{},
+		pullCh:         make(chan bool),
+		Storage:        opts.Storage,
+		bFetcher:       opts.BFetcher,
+		lru:            lru,
+		pullParallel:   opts.PullParallel,
+		urlPullCh:      make(chan struct{}, maxConcurrentURLPulls),
+		uploadTTL:      opts.UploadTTL,
+		scheduler:      opts.Scheduler,
+		shardCh:        make(chan struct{}, maxConcurrentShardTasks),
+		encoding:       make(map[string]struct{}),
+		reconstructing: make(map[string]struct{}),
 	}
 
 	m.restoreWaitListFromStore()
@@ -94,6 +120,33 @@ func (m *Manager) startTick() {
 	}
 }
 
+// startUploadGC removes the records of the expired upload tickets and the unfinished resumable uploads that expired
+func (m *Manager) startUploadGC() {
+	ticker := time.NewTicker(uploadGCInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+
+		if err := m.DeleteExpiredTickets(); err != nil {
+			log.Errorf("delete expired tickets error:%s", err.Error())
+		}
+
+		if m.uploadTTL <= 0 {
+			continue
+		}
+
+		ids, err := m.DeleteExpiredUploads(m.uploadTTL)
+		if err != nil {
+			log.Errorf("delete expired uploads error:%s", err.Error())
+		}
+
+		for _, id := range ids {
+			log.Infof("delete expired upload %s", id)
+		}
+	}
+}
+
 // triggerPuller is a helper function that is used to trigger asset downloads
 func (m *Manager) triggerPuller() {
 	select {
@@ -109,6 +162,7 @@ func (m *Manager) start() {
 	}
 
 	go m.startTick()
+	go m.startUploadGC()
 
 	// delay 15 second to pull asset if exist waitList
 	time.AfterFunc(15*time.Second, m.triggerPuller)
@@ -134,21 +188,53 @@ func (m *Manager) doPullAsset() {
 	}
 	defer m.removeAssetFromWaitList(cw.Root)
 
-	assetPuller, err := m.restoreAssetPullerOrNew(&pullerOptions{cw.Root, cw.Dss, m.Storage, m.bFetcher, m.pullParallel})
+	assetPuller, err := m.restoreAssetPullerOrNew(&pullerOptions{cw.Root, cw.Dss, m.Storage, m.bFetcher, m.pullParallel, m.scheduler})
 	if err != nil {
 		log.Errorf("restore asset puller error:%s", err)
 		return
 	}
+	defer close(assetPuller.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assetPuller.cancel = cancel
 
-	cw.puller = assetPuller
-	err = assetPuller.pullAsset()
+	if !m.setWaiterPuller(cw, assetPuller) {
+		// the asset is deleted before the pulling starts
+		m.onPullAssetCanceled(assetPuller)
+		return
+	}
+
+	err = assetPuller.pullAsset(ctx)
 	if err != nil {
 		log.Errorf("pull asset error:%s", err)
+		assetPuller.errMsg = err.Error()
+	}
+
+	if ctx.Err() != nil {
+		m.onPullAssetCanceled(assetPuller)
+		return
 	}
 
 	m.onPullAssetFinish(assetPuller)
 }
 
+// setWaiterPuller sets the puller of the assetWaiter, returns false if the assetWaiter is no longer in waitList
+func (m *Manager) setWaiterPuller(cw *assetWaiter, puller *assetPuller) bool {
+	m.waitListLock.Lock()
+	defer m.waitListLock.Unlock()
+
+	for _, waiter := range m.waitList {
+		if waiter == cw {
+			cw.puller = puller
+			return true
+		}
+	}
+
+	return false
+}
+
 // headFromWaitList returns the first assetWaiter in waitList, which is the oldest asset waiting to be downloaded
 
 func (m *Manager) headFromWaitList() *assetWaiter {
@@ -244,6 +330,19 @@ func (m *Manager) onPullAssetFinish(puller *assetPuller) {
 	}
 }
 
+// onPullAssetCanceled is called when an assetPuller is canceled, the pulled blocks and the puller are removed
+func (m *Manager) onPullAssetCanceled(puller *assetPuller) {
+	log.Debugf("onPullAssetCanceled, asset %s", puller.root.String())
+
+	if err := m.DeletePuller(puller.root); err != nil && !os.IsNotExist(err) {
+		log.Errorf("remove asset puller error:%s", err.Error())
+	}
+
+	if err := m.DeleteBlocks(puller.root); err != nil {
+		log.Errorf("remove asset blocks error:%s", err.Error())
+	}
+}
+
 // saveWaitList encodes the waitList and stores it in the datastore.
 func (m *Manager) saveWaitList() error {
 	data, err := encode(&m.waitList)
@@ -284,6 +383,9 @@ func (m *Manager) waitListLen() int {
 
 // Puller returns the asset puller associated with the first waiting item in the waitList.
 func (m *Manager) puller() *assetPuller {
+	m.waitListLock.Lock()
+	defer m.waitListLock.Unlock()
+
 	for _, cw := range m.waitList {
 		if cw.puller != nil {
 			return cw.puller
@@ -336,7 +438,7 @@ func (m *Manager) restoreAssetPullerOrNew(opts *pullerOptions) (*assetPuller, er
 	return cc, nil
 }
 
-// deleteAssetFromWaitList removes an asset from the waitList.
+// deleteAssetFromWaitList removes an asset from the waitList and cancels the pulling of the asset.
 // return true if exist in waitList
 func (m *Manager) deleteAssetFromWaitList(root cid.Cid) (bool, error) {
 	if c := m.removeAssetFromWaitList(root); c != nil {
@@ -358,6 +460,10 @@ func (m *Manager) assetStatus(root cid.Cid) (types.ReplicaStatus, error) {
 		return types.ReplicaStatusSucceeded, nil
 	}
 
+	if m.isReconstructing(root) {
+		return types.ReplicaStatusPulling, nil
+	}
+
 	for _, cw := range m.waitList {
 		if cw.Root.Hash().String() == root.Hash().String() {
 			if cw.puller != nil {
@@ -396,6 +502,7 @@ func (m *Manager) progressForAssetPulledFailed(root cid.Cid) (*types.AssetPullPr
 	progress.DoneBlocksCount = len(cc.blocksPulledSuccessList)
 	progress.Size = int64(cc.totalSize)
 	progress.DoneSize = int64(cc.doneSize)
+	progress.Msg = cc.errMsg
 
 	return progress, nil
 }
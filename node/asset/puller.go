@@ -2,10 +2,13 @@ package asset
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ipfs/go-cid"
 	legacy "github.com/ipfs/go-ipld-legacy"
+	"github.com/ipfs/go-libipfs/blocks"
+	"github.com/linguohua/titan/api"
 	"github.com/linguohua/titan/api/types"
 	"github.com/linguohua/titan/node/asset/fetcher"
 	"github.com/linguohua/titan/node/asset/storage"
@@ -23,6 +26,8 @@ type assetPuller struct {
 	storage         storage.Storage
 	bFetcher        fetcher.BlockFetcher
 	downloadSources []*types.CandidateDownloadInfo
+	// scheduler provides new download sources when the credentials of the old ones are expired
+	scheduler api.Scheduler
 
 	blocksWaitList          []string
 	blocksPulledSuccessList []string
@@ -33,19 +38,25 @@ type assetPuller struct {
 	// pull block async
 	parallel int
 	isFinish bool
+	// errMsg is the reason of the failed pulling, reported to the scheduler
+	errMsg string
+	// cancel cancels the pulling, done is closed when the pulling is stopped
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
 type pullerOptions struct {
-	root     cid.Cid
-	dss      []*types.CandidateDownloadInfo
-	storage  storage.Storage
-	bFetcher fetcher.BlockFetcher
-	parallel int
+	root      cid.Cid
+	dss       []*types.CandidateDownloadInfo
+	storage   storage.Storage
+	bFetcher  fetcher.BlockFetcher
+	parallel  int
+	scheduler api.Scheduler
 }
 
 // newAssetPuller creates a new asset puller with the given options
 func newAssetPuller(opts *pullerOptions) *assetPuller {
-	return &assetPuller{root: opts.root, storage: opts.storage, downloadSources: opts.dss, bFetcher: opts.bFetcher, parallel: opts.parallel}
+	return &assetPuller{root: opts.root, storage: opts.storage, downloadSources: opts.dss, bFetcher: opts.bFetcher, parallel: opts.parallel, scheduler: opts.scheduler, done: make(chan struct{})}
 }
 
 // getBlocksFromWaitListFront get n block from front of wait list
@@ -65,8 +76,8 @@ func (ap *assetPuller) removeBlocksFromWaitList(n int) {
 	ap.blocksWaitList = ap.blocksWaitList[n:]
 }
 
-// pullAsset pulls the asset by downloading its blocks
-func (ap *assetPuller) pullAsset() error {
+// pullAsset pulls the asset by downloading its blocks, it stops when the context is canceled
+func (ap *assetPuller) pullAsset(ctx context.Context) error {
 	defer func() {
 		ap.isFinish = true
 	}()
@@ -77,7 +88,7 @@ func (ap *assetPuller) pullAsset() error {
 	}
 
 	for len(netLayerCIDs) > 0 {
-		ret, err := ap.pullBlocksWithBreadthFirst(netLayerCIDs)
+		ret, err := ap.pullBlocksWithBreadthFirst(ctx, netLayerCIDs)
 		if err != nil {
 			return err
 		}
@@ -92,17 +103,21 @@ func (ap *assetPuller) pullAsset() error {
 }
 
 // pullBlocksWithBreadthFirst pulls blocks with breadth first algorithm.
-func (ap *assetPuller) pullBlocksWithBreadthFirst(layerCids []string) (result *pulledResult, err error) {
+func (ap *assetPuller) pullBlocksWithBreadthFirst(ctx context.Context, layerCids []string) (result *pulledResult, err error) {
 	ap.blocksWaitList = layerCids
 	result = &pulledResult{netLayerCids: ap.nextLayerCIDs}
 	for len(ap.blocksWaitList) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		doLen := len(ap.blocksWaitList)
 		if doLen > ap.parallel {
 			doLen = ap.parallel
 		}
 
 		blocks := ap.getBlocksFromWaitListFront(doLen)
-		ret, err := ap.pullBlocks(blocks)
+		ret, err := ap.pullBlocks(ctx, blocks)
 		if err != nil {
 			return nil, err
 		}
@@ -122,9 +137,26 @@ func (ap *assetPuller) pullBlocksWithBreadthFirst(layerCids []string) (result *p
 	return result, nil
 }
 
+// fetchBlocks fetches blocks from the download sources, the sources are refreshed once
+// from the scheduler if their credentials are expired, e.g. for the long-running or restored pulling
+func (ap *assetPuller) fetchBlocks(ctx context.Context, cids []string) ([]blocks.Block, error) {
+	blks, err := ap.bFetcher.FetchBlocks(ctx, cids, ap.downloadSources)
+	if !errors.Is(err, fetcher.ErrUnauthorized) || ap.scheduler == nil {
+		return blks, err
+	}
+
+	dss, err := ap.scheduler.GetAssetPullSources(ctx, ap.root.String())
+	if err != nil {
+		return nil, fmt.Errorf("refresh download sources error: %w", err)
+	}
+	ap.downloadSources = dss
+
+	return ap.bFetcher.FetchBlocks(ctx, cids, ap.downloadSources)
+}
+
 // pullBlocks fetches blocks for given cids, stores them in the storage
-func (ap *assetPuller) pullBlocks(cids []string) (*pulledResult, error) {
-	blks, err := ap.bFetcher.FetchBlocks(context.Background(), cids, ap.downloadSources)
+func (ap *assetPuller) pullBlocks(ctx context.Context, cids []string) (*pulledResult, error) {
+	blks, err := ap.fetchBlocks(ctx, cids)
 	if err != nil {
 		log.Errorf("loadBlocksAsync loadBlocks err %s", err.Error())
 		return nil, err
@@ -139,7 +171,7 @@ func (ap *assetPuller) pullBlocks(cids []string) (*pulledResult, error) {
 	linksMap := make(map[string][]string)
 	for _, b := range blks {
 		// get block links
-		node, err := legacy.DecodeNode(context.Background(), b)
+		node, err := legacy.DecodeNode(ctx, b)
 		if err != nil {
 			log.Errorf("downloadBlocks decode block error:%s", err.Error())
 			return nil, err
@@ -162,7 +194,7 @@ func (ap *assetPuller) pullBlocks(cids []string) (*pulledResult, error) {
 		nexLayerCids = append(nexLayerCids, links...)
 	}
 
-	err = ap.storage.StoreBlocks(context.Background(), ap.root, blks)
+	err = ap.storage.StoreBlocks(ctx, ap.root, blks)
 	if err != nil {
 		return nil, err
 	}
@@ -185,10 +217,16 @@ func (ap *assetPuller) isPulledComplete() bool {
 	return true
 }
 
-// cancelPulling cancels the asset pulling
+// cancelPulling cancels the asset pulling and waits until the pulling is stopped
 func (ap *assetPuller) cancelPulling() error {
-	// TODO: implement cancel
-	return fmt.Errorf("")
+	if ap.cancel == nil {
+		return fmt.Errorf("asset %s pulling is not started", ap.root.String())
+	}
+
+	ap.cancel()
+	<-ap.done
+
+	return nil
 }
 
 // encode encodes the asset puller to bytes
@@ -201,6 +239,7 @@ func (ap *assetPuller) encode() ([]byte, error) {
 		DownloadSources:         ap.downloadSources,
 		TotalSize:               ap.totalSize,
 		DoneSize:                ap.doneSize,
+		ErrMsg:                  ap.errMsg,
 	}
 
 	return encode(eac)
@@ -226,6 +265,7 @@ func (ap *assetPuller) decode(data []byte) error {
 	ap.downloadSources = eac.DownloadSources
 	ap.totalSize = eac.TotalSize
 	ap.doneSize = eac.DoneSize
+	ap.errMsg = eac.ErrMsg
 
 	return nil
 }
@@ -244,7 +284,7 @@ func (ap *assetPuller) getAssetStatus() types.ReplicaStatus {
 
 // getAssetProgress returns the current progress of the asset
 func (ap *assetPuller) getAssetProgress() *types.AssetPullProgress {
-	return &types.AssetPullProgress{
+	progress := &types.AssetPullProgress{
 		CID:             ap.root.String(),
 		Status:          ap.getAssetStatus(),
 		BlocksCount:     len(ap.blocksPulledSuccessList) + len(ap.blocksWaitList),
@@ -252,4 +292,14 @@ func (ap *assetPuller) getAssetProgress() *types.AssetPullProgress {
 		Size:            int64(ap.totalSize),
 		DoneSize:        int64(ap.doneSize),
 	}
+
+	if progress.Status == types.ReplicaStatusFailed {
+		progress.Msg = ap.errMsg
+	}
+
+	if reporter, ok := ap.bFetcher.(fetcher.SourceReporter); ok && len(ap.downloadSources) > 0 {
+		progress.Sources = reporter.SourceStats(ap.downloadSources)
+	}
+
+	return progress
 }
@@ -1,10 +1,15 @@
 package asset
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-libipfs/blocks"
 	logging "github.com/ipfs/go-log/v2"
+	"github.com/ipfs/go-merkledag"
+	"github.com/linguohua/titan/api"
+	"github.com/linguohua/titan/api/types"
 	"github.com/linguohua/titan/node/asset/fetcher"
 	"github.com/linguohua/titan/node/asset/storage"
 )
@@ -29,9 +34,66 @@ func TestCache(t *testing.T) {
 	}
 
 	assetPuller := newAssetPuller(&pullerOptions{root: c, dss: nil, storage: manger, bFetcher: fetcher.NewIPFSClient("http://192.168.0.132:5001", 15, 1), parallel: 5})
-	err = assetPuller.pullAsset()
+	err = assetPuller.pullAsset(context.Background())
 	if err != nil {
 		t.Errorf("pull asset error:%s", err)
 		return
 	}
 }
+
+type sourcesFetcher struct {
+	valid string
+}
+
+func (f *sourcesFetcher) FetchBlocks(ctx context.Context, cids []string, dss []*types.CandidateDownloadInfo) ([]blocks.Block, error) {
+	if len(dss) == 0 || dss[0].URL != f.valid {
+		return nil, fetcher.ErrUnauthorized
+	}
+
+	blks := make([]blocks.Block, 0, len(cids))
+	for range cids {
+		blks = append(blks, merkledag.NewRawNode([]byte("block")))
+	}
+	return blks, nil
+}
+
+type sourcesScheduler struct {
+	api.SchedulerStub
+	calls int
+	dss   []*types.CandidateDownloadInfo
+}
+
+func (s *sourcesScheduler) GetAssetPullSources(ctx context.Context, cid string) ([]*types.CandidateDownloadInfo, error) {
+	s.calls++
+	return s.dss, nil
+}
+
+func TestFetchBlocksRefreshesExpiredSources(t *testing.T) {
+	c := merkledag.NewRawNode([]byte("root")).Cid()
+	scheduler := &sourcesScheduler{dss: []*types.CandidateDownloadInfo{{URL: "new"}}}
+
+	puller := newAssetPuller(&pullerOptions{root: c, dss: []*types.CandidateDownloadInfo{{URL: "old"}}, bFetcher: &sourcesFetcher{valid: "new"}, parallel: 1, scheduler: scheduler})
+	blks, err := puller.fetchBlocks(context.Background(), []string{c.String()})
+	if err != nil {
+		t.Fatalf("fetch blocks error:%s", err)
+	}
+
+	if len(blks) != 1 || scheduler.calls != 1 || puller.downloadSources[0].URL != "new" {
+		t.Errorf("expected the sources to be refreshed once, blocks:%d calls:%d", len(blks), scheduler.calls)
+	}
+}
+
+func TestFetchBlocksKeepsValidSources(t *testing.T) {
+	c := merkledag.NewRawNode([]byte("root")).Cid()
+	scheduler := &sourcesScheduler{dss: []*types.CandidateDownloadInfo{{URL: "new"}}}
+
+	puller := newAssetPuller(&pullerOptions{root: c, dss: []*types.CandidateDownloadInfo{{URL: "old"}}, bFetcher: &sourcesFetcher{valid: "old"}, parallel: 1, scheduler: scheduler})
+	blks, err := puller.fetchBlocks(context.Background(), []string{c.String()})
+	if err != nil {
+		t.Fatalf("fetch blocks error:%s", err)
+	}
+
+	if len(blks) != 1 || scheduler.calls != 0 || puller.downloadSources[0].URL != "old" {
+		t.Errorf("expected the valid sources to be kept, blocks:%d calls:%d", len(blks), scheduler.calls)
+	}
+}
@@ -52,6 +52,12 @@ func (a *asset) storeBlocks(ctx context.Context, root cid.Cid, blks []blocks.Blo
 	return nil
 }
 
+// removeBlocks deletes the blocks directory of the asset from the file system.
+func (a *asset) removeBlocks(root cid.Cid) error {
+	assetDir := filepath.Join(a.baseDir, root.Hash().String())
+	return os.RemoveAll(assetDir)
+}
+
 // storeAsset stores the asset to the file system.
 func (a *asset) storeAsset(ctx context.Context, root cid.Cid) error {
 	assetDir := filepath.Join(a.baseDir, root.Hash().String())
@@ -3,7 +3,9 @@ package storage
 import (
 	"context"
 	"io"
+	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-libipfs/blocks"
@@ -16,6 +18,9 @@ var log = logging.Logger("asset/store")
 const (
 	// dir or file name
 	pullerDir      = "asset-puller"
+	uploadDir      = "asset-upload"
+	stagingDir     = "asset-staging"
+	ticketDir      = "upload-ticket"
 	waitListFile   = "wait-list"
 	assetsDir      = "assets"
 	transientsDir  = "tmp"
@@ -32,6 +37,9 @@ type Manager struct {
 	asset      *asset
 	wl         *waitList
 	puller     *puller
+	upload     *upload
+	staging    *staging
+	ticket     *ticket
 	blockCount *blockCount
 	assetsView *assetsView
 }
@@ -39,6 +47,9 @@ type Manager struct {
 // ManagerOptions contains configuration options for the Manager
 type ManagerOptions struct {
 	PullerDir        string
+	UploadDir        string
+	StagingDir       string
+	TicketDir        string
 	waitListFilePath string
 	AssetsDir        string
 	AssetSuffix      string
@@ -64,6 +75,21 @@ func NewManager(baseDir string, opts *ManagerOptions) (*Manager, error) {
 		return nil, err
 	}
 
+	upload, err := newUpload(opts.UploadDir)
+	if err != nil {
+		return nil, err
+	}
+
+	staging, err := newStaging(opts.StagingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := newTicket(opts.TicketDir)
+	if err != nil {
+		return nil, err
+	}
+
 	blockCount, err := newBlockCount(opts.CountDir)
 	if err != nil {
 		return nil, err
@@ -81,6 +107,9 @@ func NewManager(baseDir string, opts *ManagerOptions) (*Manager, error) {
 		assetsView: assetsView,
 		wl:         waitList,
 		puller:     puller,
+		upload:     upload,
+		staging:    staging,
+		ticket:     ticket,
 		blockCount: blockCount,
 	}, nil
 }
@@ -89,6 +118,9 @@ func NewManager(baseDir string, opts *ManagerOptions) (*Manager, error) {
 func defaultOptions(baseDir string) *ManagerOptions {
 	opts := &ManagerOptions{
 		PullerDir:        filepath.Join(baseDir, pullerDir),
+		UploadDir:        filepath.Join(baseDir, uploadDir),
+		StagingDir:       filepath.Join(baseDir, stagingDir),
+		TicketDir:        filepath.Join(baseDir, ticketDir),
 		waitListFilePath: filepath.Join(baseDir, waitListFile),
 		AssetsDir:        filepath.Join(baseDir, assetsDir),
 		AssetSuffix:      assetSuffix,
@@ -120,12 +152,63 @@ func (m *Manager) DeletePuller(c cid.Cid) error {
 	return m.puller.remove(c)
 }
 
+// upload api
+// CreateUpload saves the meta of a resumable upload
+func (m *Manager) CreateUpload(id string, meta []byte) error {
+	return m.upload.create(id, meta)
+}
+
+// GetUpload retrieves the meta of a resumable upload and the size of the data written
+func (m *Manager) GetUpload(id string) ([]byte, int64, error) {
+	return m.upload.get(id)
+}
+
+// WriteUpload appends data to a resumable upload at the offset
+func (m *Manager) WriteUpload(id string, offset int64, r io.Reader) (int64, error) {
+	return m.upload.write(id, offset, r)
+}
+
+// OpenUpload opens the data of a resumable upload
+func (m *Manager) OpenUpload(id string) (io.ReadSeekCloser, error) {
+	return m.upload.open(id)
+}
+
+// DeleteUpload removes a resumable upload
+func (m *Manager) DeleteUpload(id string) error {
+	return m.upload.remove(id)
+}
+
+// DeleteExpiredUploads removes the resumable uploads that are not written in the ttl
+func (m *Manager) DeleteExpiredUploads(ttl time.Duration) ([]string, error) {
+	return m.upload.removeExpired(ttl)
+}
+
+// CreateStagingFile creates a temporary file in the storage for an asset being built
+func (m *Manager) CreateStagingFile(pattern string) (*os.File, error) {
+	return m.staging.create(pattern)
+}
+
+// UseTicket records the upload ticket as used until its expiration, an error satisfying os.IsExist is returned if it is already used
+func (m *Manager) UseTicket(id string, expiration time.Time) error {
+	return m.ticket.use(id, expiration)
+}
+
+// DeleteExpiredTickets removes the records of the expired upload tickets
+func (m *Manager) DeleteExpiredTickets() error {
+	return m.ticket.removeExpired()
+}
+
 // asset api
 // StoreBlocks stores multiple blocks for an asset
 func (m *Manager) StoreBlocks(ctx context.Context, root cid.Cid, blks []blocks.Block) error {
 	return m.asset.storeBlocks(ctx, root, blks)
 }
 
+// DeleteBlocks removes the blocks of an asset that is not stored yet
+func (m *Manager) DeleteBlocks(root cid.Cid) error {
+	return m.asset.removeBlocks(root)
+}
+
 // StoreAsset stores a single asset
 func (m *Manager) StoreAsset(ctx context.Context, root cid.Cid) error {
 	return m.asset.storeAsset(ctx, root)
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"os"
+)
+
+// staging stores the temporary files of the assets being built using the filesystem,
+// so that they are counted in the disk usage of the storage.
+type staging struct {
+	baseDir string
+}
+
+// newStaging initializes a new staging instance, the files left by the last run are removed.
+func newStaging(baseDir string) (*staging, error) {
+	if err := os.RemoveAll(baseDir); err != nil {
+		return nil, err
+	}
+
+	err := os.MkdirAll(baseDir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &staging{baseDir: baseDir}, nil
+}
+
+// create creates a new temporary file, the pattern is used as in os.CreateTemp.
+func (s *staging) create(pattern string) (*os.File, error) {
+	return os.CreateTemp(s.baseDir, pattern)
+}
@@ -3,6 +3,8 @@ package storage
 import (
 	"context"
 	"io"
+	"os"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-libipfs/blocks"
@@ -15,7 +17,23 @@ type Storage interface {
 	PullerExists(c cid.Cid) (bool, error)
 	DeletePuller(c cid.Cid) error
 
+	// resumable uploads
+	CreateUpload(id string, meta []byte) error
+	GetUpload(id string) ([]byte, int64, error)
+	WriteUpload(id string, offset int64, r io.Reader) (int64, error)
+	OpenUpload(id string) (io.ReadSeekCloser, error)
+	DeleteUpload(id string) error
+	DeleteExpiredUploads(ttl time.Duration) ([]string, error)
+
+	// used upload tickets
+	UseTicket(id string, expiration time.Time) error
+	DeleteExpiredTickets() error
+
+	// temporary files of the assets being built
+	CreateStagingFile(pattern string) (*os.File, error)
+
 	StoreBlocks(ctx context.Context, root cid.Cid, blks []blocks.Block) error
+	DeleteBlocks(root cid.Cid) error
 
 	StoreAsset(ctx context.Context, root cid.Cid) error
 	GetAsset(root cid.Cid) (io.ReadSeekCloser, error)
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// ticket records the used upload tickets using the filesystem, each ticket is an empty file named by its id,
+// the modification time of the file is the expiration of the ticket.
+type ticket struct {
+	baseDir string
+}
+
+// newTicket initializes a new ticket instance.
+func newTicket(baseDir string) (*ticket, error) {
+	err := os.MkdirAll(baseDir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ticket{baseDir: baseDir}, nil
+}
+
+// use records the ticket as used until the expiration, an error satisfying os.IsExist is returned if it is already used.
+func (t *ticket) use(id string, expiration time.Time) error {
+	if id == "" || id == "." || id == ".." || filepath.Base(id) != id {
+		return xerrors.Errorf("invalid ticket id %s", id)
+	}
+
+	path := filepath.Join(t.baseDir, id)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Chtimes(path, expiration, expiration)
+}
+
+// removeExpired deletes the records of the expired tickets, they can not be used anyway.
+func (t *ticket) removeExpired() error {
+	entries, err := os.ReadDir(t.baseDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(time.Now()) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(t.baseDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
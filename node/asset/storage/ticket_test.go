@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestTicket(t *testing.T) {
+	tk, err := newTicket(t.TempDir())
+	if err != nil {
+		t.Fatalf("new ticket error:%s", err.Error())
+	}
+
+	if err := tk.use("../escape", time.Now()); err == nil {
+		t.Fatal("expect invalid ticket id error")
+	}
+
+	if err := tk.use("valid", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("use ticket error:%s", err.Error())
+	}
+
+	if err := tk.use("valid", time.Now().Add(time.Hour)); !os.IsExist(err) {
+		t.Fatalf("expect exist error, got %v", err)
+	}
+
+	if err := tk.use("expired", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("use ticket error:%s", err.Error())
+	}
+
+	if err := tk.removeExpired(); err != nil {
+		t.Fatalf("remove expired tickets error:%s", err.Error())
+	}
+
+	if err := tk.use("valid", time.Now().Add(time.Hour)); !os.IsExist(err) {
+		t.Errorf("expect the valid ticket to be kept, got %v", err)
+	}
+
+	if err := tk.use("expired", time.Now().Add(-time.Minute)); err != nil {
+		t.Errorf("expect the expired ticket to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	uploadMetaFile = "meta"
+	uploadDataFile = "data"
+)
+
+// upload stores the partial files of the resumable uploads using the filesystem, each upload is a directory of its meta and data.
+type upload struct {
+	baseDir string
+}
+
+// newUpload initializes a new upload instance.
+func newUpload(baseDir string) (*upload, error) {
+	err := os.MkdirAll(baseDir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &upload{baseDir: baseDir}, nil
+}
+
+// dir returns the directory of the upload, the id must be a single path element.
+func (u *upload) dir(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || filepath.Base(id) != id {
+		return "", xerrors.Errorf("invalid upload id %s", id)
+	}
+
+	return filepath.Join(u.baseDir, id), nil
+}
+
+// create saves the meta of the upload and creates the empty data file.
+func (u *upload) create(id string, meta []byte) error {
+	dir, err := u.dir(id)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, uploadMetaFile), meta, 0o644); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, uploadDataFile), nil, 0o644)
+}
+
+// get returns the meta of the upload and the size of the data written.
+func (u *upload) get(id string) ([]byte, int64, error) {
+	dir, err := u.dir(id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	meta, err := os.ReadFile(filepath.Join(dir, uploadMetaFile))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := os.Stat(filepath.Join(dir, uploadDataFile))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return meta, info.Size(), nil
+}
+
+// write appends the data of the reader at the offset, the offset must be the size of the data written.
+// The data read before an error is kept for the upload to be resumed
+func (u *upload) write(id string, offset int64, r io.Reader) (int64, error) {
+	dir, err := u.dir(id)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, uploadDataFile), os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	if info.Size() != offset {
+		return 0, xerrors.Errorf("offset %d does not match the upload size %d", offset, info.Size())
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(f, r)
+	if err != nil {
+		return n, err
+	}
+
+	return n, f.Sync()
+}
+
+// open opens the data of the upload for reading.
+func (u *upload) open(id string) (io.ReadSeekCloser, error) {
+	dir, err := u.dir(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(filepath.Join(dir, uploadDataFile))
+}
+
+// remove deletes the upload.
+func (u *upload) remove(id string) error {
+	dir, err := u.dir(id)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(dir)
+}
+
+// removeExpired deletes the uploads that the data is not written in the ttl, the ids of the deleted uploads are returned.
+func (u *upload) removeExpired(ttl time.Duration) ([]string, error) {
+	entries, err := os.ReadDir(u.baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0)
+	for _, entry := range entries {
+		dir := filepath.Join(u.baseDir, entry.Name())
+
+		modTime := time.Time{}
+		if info, err := os.Stat(filepath.Join(dir, uploadDataFile)); err == nil {
+			modTime = info.ModTime()
+		} else if info, err := entry.Info(); err == nil {
+			modTime = info.ModTime()
+		}
+
+		if time.Since(modTime) < ttl {
+			continue
+		}
+
+		if err := os.RemoveAll(dir); err != nil {
+			return ids, err
+		}
+		ids = append(ids, entry.Name())
+	}
+
+	return ids, nil
+}
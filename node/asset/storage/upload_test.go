@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpload(t *testing.T) {
+	u, err := newUpload(t.TempDir())
+	if err != nil {
+		t.Fatalf("new upload error:%s", err.Error())
+	}
+
+	if err := u.create("../escape", nil); err == nil {
+		t.Fatal("expect invalid upload id error")
+	}
+
+	if err := u.create("ticket", []byte("meta")); err != nil {
+		t.Fatalf("create upload error:%s", err.Error())
+	}
+
+	if err := u.create("ticket", []byte("meta")); !os.IsExist(err) {
+		t.Fatalf("expect exist error, got %v", err)
+	}
+
+	if _, err := u.write("ticket", 0, strings.NewReader("hello ")); err != nil {
+		t.Fatalf("write upload error:%s", err.Error())
+	}
+
+	if _, err := u.write("ticket", 0, strings.NewReader("again")); err == nil {
+		t.Fatal("expect offset mismatch error")
+	}
+
+	if _, err := u.write("ticket", 6, strings.NewReader("world")); err != nil {
+		t.Fatalf("write upload error:%s", err.Error())
+	}
+
+	meta, size, err := u.get("ticket")
+	if err != nil {
+		t.Fatalf("get upload error:%s", err.Error())
+	}
+
+	if string(meta) != "meta" || size != 11 {
+		t.Fatalf("unexpected upload meta %s size %d", string(meta), size)
+	}
+
+	f, err := u.open("ticket")
+	if err != nil {
+		t.Fatalf("open upload error:%s", err.Error())
+	}
+	data, err := io.ReadAll(f)
+	f.Close() //nolint:errcheck
+	if err != nil || string(data) != "hello world" {
+		t.Fatalf("unexpected upload data %s, error %v", string(data), err)
+	}
+}
+
+func TestUploadRemoveExpired(t *testing.T) {
+	u, err := newUpload(t.TempDir())
+	if err != nil {
+		t.Fatalf("new upload error:%s", err.Error())
+	}
+
+	for _, id := range []string{"old", "new"} {
+		if err := u.create(id, nil); err != nil {
+			t.Fatalf("create upload error:%s", err.Error())
+		}
+	}
+
+	past := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(u.baseDir, "old", uploadDataFile), past, past); err != nil {
+		t.Fatalf("change time error:%s", err.Error())
+	}
+
+	ids, err := u.removeExpired(time.Hour)
+	if err != nil {
+		t.Fatalf("remove expired error:%s", err.Error())
+	}
+
+	if len(ids) != 1 || ids[0] != "old" {
+		t.Fatalf("unexpected removed uploads %v", ids)
+	}
+
+	if _, _, err := u.get("new"); err != nil {
+		t.Fatalf("get upload error:%s", err.Error())
+	}
+}
@@ -0,0 +1,374 @@
+package asset
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	chunker "github.com/ipfs/go-ipfs-chunker"
+	ipld "github.com/ipfs/go-ipld-format"
+	"github.com/ipfs/go-libipfs/blocks"
+	"github.com/ipfs/go-merkledag"
+	"github.com/ipfs/go-unixfs/importer/balanced"
+	"github.com/ipfs/go-unixfs/importer/helpers"
+	"github.com/ipfs/go-unixfs/importer/trickle"
+	"github.com/linguohua/titan/api/types"
+	"github.com/linguohua/titan/node/asset/storage"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// maxConcurrentURLPulls is the maximum number of assets built from origin urls at the same time
+	maxConcurrentURLPulls = 2
+	// urlBlocksBatch is the number of blocks stored in a batch when the asset is built
+	urlBlocksBatch = 64
+	// urlPullTimeout is the timeout to download the file of an origin url
+	urlPullTimeout = 2 * time.Hour
+	// maxURLFileSize is the maximum size of the file of an origin url
+	maxURLFileSize = 64 << 30
+
+	defaultChunker = "size-1048576"
+	layoutBalanced = "balanced"
+	layoutTrickle  = "trickle"
+)
+
+// defaultUnixFSOptions returns the options to build the file of the url if the request does not set them
+func defaultUnixFSOptions() *types.UnixFSOptions {
+	return &types.UnixFSOptions{Chunker: defaultChunker, Layout: layoutBalanced, RawLeaves: true, CIDVersion: 1}
+}
+
+// checkUnixFSOptions returns the options with the defaults of the empty fields, an error is returned if the options are invalid
+func checkUnixFSOptions(opts *types.UnixFSOptions) (*types.UnixFSOptions, error) {
+	if opts == nil {
+		return defaultUnixFSOptions(), nil
+	}
+
+	out := *opts
+	if out.Chunker == "" {
+		out.Chunker = defaultChunker
+	}
+
+	if out.Layout == "" {
+		out.Layout = layoutBalanced
+	}
+
+	if out.Layout != layoutBalanced && out.Layout != layoutTrickle {
+		return nil, xerrors.Errorf("unknown layout %s", out.Layout)
+	}
+
+	if out.CIDVersion != 0 && out.CIDVersion != 1 {
+		return nil, xerrors.Errorf("unknown cid version %d", out.CIDVersion)
+	}
+
+	if _, err := chunker.FromString(bytes.NewReader(nil), out.Chunker); err != nil {
+		return nil, xerrors.Errorf("chunker %s: %w", out.Chunker, err)
+	}
+
+	return &out, nil
+}
+
+// PullAssetFromURL downloads the file of the url and builds it into an asset in the background, the root cid is reported to the scheduler
+func (a *Asset) PullAssetFromURL(ctx context.Context, req *types.URLPullReq) error {
+	if types.RunningNodeType != types.NodeCandidate {
+		return fmt.Errorf("only candidate can pull asset from url")
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported url scheme %s", u.Scheme)
+	}
+
+	opts, err := checkUnixFSOptions(req.UnixFS)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("pull asset from url %s", req.URL)
+
+	go func() {
+		result := a.mgr.pullAssetFromURL(context.Background(), req.URL, opts)
+		result.ID = req.ID
+
+		if err := a.scheduler.NodeURLPullResult(context.Background(), result); err != nil {
+			log.Errorf("url pull result %s err:%s", req.URL, err.Error())
+		}
+	}()
+
+	return nil
+}
+
+// pullAssetFromURL downloads the file of the url and builds it into an asset, the failure is set to the result
+func (m *Manager) pullAssetFromURL(ctx context.Context, u string, opts *types.UnixFSOptions) *types.URLPullResult {
+	m.urlPullCh <- struct{}{}
+	defer func() { <-m.urlPullCh }()
+
+	result := &types.URLPullResult{URL: u}
+
+	root, size, blockCount, err := m.buildAssetFromURL(ctx, u, opts)
+	if err != nil {
+		log.Errorf("pull asset from url %s err:%s", u, err.Error())
+		result.Err = err.Error()
+		return result
+	}
+
+	result.CID = root.String()
+	result.Size = size
+	result.Blocks = int64(blockCount)
+
+	return result
+}
+
+// buildAssetFromURL downloads the file of the url to a temporary file, then stores it as an asset
+func (m *Manager) buildAssetFromURL(ctx context.Context, u string, opts *types.UnixFSOptions) (root cid.Cid, size int64, blockCount int, err error) {
+	file, err := m.CreateStagingFile("url-asset-*")
+	if err != nil {
+		return cid.Undef, 0, 0, err
+	}
+	defer os.Remove(file.Name()) //nolint:errcheck
+	defer file.Close()           //nolint:errcheck
+
+	size, err = downloadURL(ctx, u, file)
+	if err != nil {
+		return cid.Undef, 0, 0, xerrors.Errorf("download: %w", err)
+	}
+
+	root, blockCount, _, err = m.StoreFile(ctx, file, opts)
+	if err != nil {
+		return cid.Undef, 0, 0, err
+	}
+
+	return root, size, blockCount, nil
+}
+
+// StoreFile builds the file into a UnixFS dag with the options and stores it as an asset, the default options are used if the options are nil.
+// The file is built twice, the first pass only hashes the blocks to get the root cid the asset is stored by.
+// It returns whether the asset is created by the call, false if the asset already exists
+func (m *Manager) StoreFile(ctx context.Context, file io.ReadSeeker, opts *types.UnixFSOptions) (cid.Cid, int, bool, error) {
+	opts, err := checkUnixFSOptions(opts)
+	if err != nil {
+		return cid.Undef, 0, false, err
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return cid.Undef, 0, false, err
+	}
+
+	hasher := newAssetDAGService(cid.Undef, nil)
+	nd, err := buildUnixFS(file, opts, hasher)
+	if err != nil {
+		return cid.Undef, 0, false, xerrors.Errorf("build unixfs: %w", err)
+	}
+
+	root := nd.Cid()
+	if has, err := m.AssetExists(root); err != nil {
+		return cid.Undef, 0, false, err
+	} else if has {
+		log.Debugf("asset %s already exist", root.String())
+		return root, hasher.count(), false, nil
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return cid.Undef, 0, false, err
+	}
+
+	dserv := newAssetDAGService(root, m.Storage)
+	if err = storeUnixFS(ctx, file, opts, dserv); err != nil {
+		if e := m.DeleteBlocks(root); e != nil {
+			log.Errorf("remove asset blocks error:%s", e.Error())
+		}
+		return cid.Undef, 0, false, err
+	}
+
+	if err = m.SetBlockCount(ctx, root, uint32(dserv.count())); err != nil {
+		return cid.Undef, 0, false, err
+	}
+
+	if err = m.StoreAsset(ctx, root); err != nil {
+		return cid.Undef, 0, false, err
+	}
+
+	return root, dserv.count(), true, nil
+}
+
+// storeUnixFS builds the file into a UnixFS dag and stores the blocks of the dag
+func storeUnixFS(ctx context.Context, r io.Reader, opts *types.UnixFSOptions, dserv *assetDAGService) error {
+	nd, err := buildUnixFS(r, opts, dserv)
+	if err != nil {
+		return xerrors.Errorf("build unixfs: %w", err)
+	}
+
+	if !nd.Cid().Equals(dserv.root) {
+		return xerrors.Errorf("root cid %s is changed to %s", dserv.root.String(), nd.Cid().String())
+	}
+
+	return dserv.flush(ctx)
+}
+
+// downloadURL downloads the file of the url to the writer and returns the size of the file,
+// the file larger than the max url file size is rejected
+func downloadURL(ctx context.Context, u string, w io.Writer) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, urlPullTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("http status code %d", resp.StatusCode)
+	}
+
+	if resp.ContentLength > maxURLFileSize {
+		return 0, fmt.Errorf("file size %d exceeds the limit %d", resp.ContentLength, maxURLFileSize)
+	}
+
+	n, err := io.Copy(w, io.LimitReader(resp.Body, maxURLFileSize+1))
+	if err != nil {
+		return n, err
+	}
+
+	if n > maxURLFileSize {
+		return n, fmt.Errorf("file size exceeds the limit %d", maxURLFileSize)
+	}
+
+	return n, nil
+}
+
+// buildUnixFS builds the data of the reader into a UnixFS dag with the options, the nodes are added to the dag service
+func buildUnixFS(r io.Reader, opts *types.UnixFSOptions, dserv ipld.DAGService) (ipld.Node, error) {
+	spl, err := chunker.FromString(r, opts.Chunker)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := merkledag.PrefixForCidVersion(opts.CIDVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	params := helpers.DagBuilderParams{
+		Maxlinks:   helpers.DefaultLinksPerBlock,
+		RawLeaves:  opts.RawLeaves,
+		CidBuilder: &prefix,
+		Dagserv:    dserv,
+	}
+
+	db, err := params.New(spl)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Layout == layoutTrickle {
+		return trickle.Layout(db)
+	}
+
+	return balanced.Layout(db)
+}
+
+// assetDAGService is a write only dag service that stores the added nodes as the blocks of the asset,
+// the nodes are only counted if the storage is nil
+type assetDAGService struct {
+	root    cid.Cid
+	storage storage.Storage
+	batch   []blocks.Block
+	cids    map[cid.Cid]struct{}
+}
+
+// newAssetDAGService creates a new assetDAGService of the asset root
+func newAssetDAGService(root cid.Cid, s storage.Storage) *assetDAGService {
+	return &assetDAGService{root: root, storage: s, cids: make(map[cid.Cid]struct{})}
+}
+
+// count returns the number of distinct blocks added
+func (s *assetDAGService) count() int {
+	return len(s.cids)
+}
+
+// flush stores the blocks of the batch
+func (s *assetDAGService) flush(ctx context.Context) error {
+	if s.storage == nil || len(s.batch) == 0 {
+		return nil
+	}
+
+	err := s.storage.StoreBlocks(ctx, s.root, s.batch)
+	s.batch = s.batch[:0]
+	return err
+}
+
+// Add adds the node to the batch, the batch is stored if it is full
+func (s *assetDAGService) Add(ctx context.Context, nd ipld.Node) error {
+	if _, ok := s.cids[nd.Cid()]; ok {
+		return nil
+	}
+	s.cids[nd.Cid()] = struct{}{}
+
+	if s.storage == nil {
+		return nil
+	}
+
+	blk, err := blocks.NewBlockWithCid(nd.RawData(), nd.Cid())
+	if err != nil {
+		return err
+	}
+
+	s.batch = append(s.batch, blk)
+	if len(s.batch) < urlBlocksBatch {
+		return nil
+	}
+
+	return s.flush(ctx)
+}
+
+// AddMany adds the nodes to the batch
+func (s *assetDAGService) AddMany(ctx context.Context, nds []ipld.Node) error {
+	for _, nd := range nds {
+		if err := s.Add(ctx, nd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get is not supported, the dag service is write only
+func (s *assetDAGService) Get(ctx context.Context, c cid.Cid) (ipld.Node, error) {
+	return nil, ipld.ErrNotFound{Cid: c}
+}
+
+// GetMany is not supported, the dag service is write only
+func (s *assetDAGService) GetMany(ctx context.Context, cids []cid.Cid) <-chan *ipld.NodeOption {
+	out := make(chan *ipld.NodeOption, len(cids))
+	for _, c := range cids {
+		out <- &ipld.NodeOption{Err: ipld.ErrNotFound{Cid: c}}
+	}
+	close(out)
+
+	return out
+}
+
+// Remove is a no-op, the blocks are removed with the asset
+func (s *assetDAGService) Remove(ctx context.Context, c cid.Cid) error {
+	return nil
+}
+
+// RemoveMany is a no-op, the blocks are removed with the asset
+func (s *assetDAGService) RemoveMany(ctx context.Context, cids []cid.Cid) error {
+	return nil
+}
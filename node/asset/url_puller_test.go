@@ -0,0 +1,48 @@
+package asset
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/linguohua/titan/api/types"
+)
+
+func TestBuildUnixFS(t *testing.T) {
+	data := make([]byte, 3<<20)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	build := func(opts *types.UnixFSOptions) (cid.Cid, int) {
+		dserv := newAssetDAGService(cid.Undef, nil)
+		nd, err := buildUnixFS(bytes.NewReader(data), opts, dserv)
+		if err != nil {
+			t.Fatalf("build unixfs err:%s", err)
+		}
+
+		return nd.Cid(), dserv.count()
+	}
+
+	root, count := build(defaultUnixFSOptions())
+	if again, _ := build(defaultUnixFSOptions()); !again.Equals(root) {
+		t.Errorf("root cid %s is not deterministic, got %s", root, again)
+	}
+
+	// 3 leaves of 1MiB and the root
+	if count != 4 {
+		t.Errorf("expect 4 blocks, got %d", count)
+	}
+
+	opts, err := checkUnixFSOptions(&types.UnixFSOptions{Chunker: "size-262144", RawLeaves: true, CIDVersion: 1})
+	if err != nil {
+		t.Fatalf("check options err:%s", err)
+	}
+
+	if other, _ := build(opts); other.Equals(root) {
+		t.Errorf("root cid %s is not changed by the chunker", root)
+	}
+
+	if _, err := checkUnixFSOptions(&types.UnixFSOptions{Chunker: "unknown"}); err == nil {
+		t.Errorf("expect the unknown chunker to be rejected")
+	}
+}
@@ -2,6 +2,7 @@ package node
 
 import (
 	"errors"
+	"time"
 
 	"github.com/linguohua/titan/api"
 	"github.com/linguohua/titan/node/asset"
@@ -52,7 +53,7 @@ func ConfigCandidate(c interface{}) Option {
 		Override(new(*device.Device), modules.NewDevice(cfg.BandwidthUp, cfg.BandwidthDown)),
 		Override(new(dtypes.CarfileStorePath), dtypes.CarfileStorePath(cfg.CarfileStorePath)),
 		Override(new(*storage.Manager), modules.NewNodeStorageManager),
-		Override(new(*asset.Manager), modules.NewAssetsManager(cfg.FetchBatch)),
+		Override(new(*asset.Manager), modules.NewAssetsManager(cfg.FetchBatch, time.Duration(cfg.ResumableUploadTTL)*time.Hour)),
 		Override(new(*validation.Validation), modules.NewNodeValidation),
 		Override(new(*rate.Limiter), modules.NewRateLimiter),
 		Override(new(*asset.Asset), asset.NewAsset),
@@ -52,7 +52,7 @@ func ConfigEdge(c interface{}) Option {
 		Override(new(*device.Device), modules.NewDevice(cfg.BandwidthUp, cfg.BandwidthDown)),
 		Override(new(dtypes.CarfileStorePath), dtypes.CarfileStorePath(cfg.CarfileStorePath)),
 		Override(new(*storage.Manager), modules.NewNodeStorageManager),
-		Override(new(*asset.Manager), modules.NewAssetsManager(cfg.FetchBatch)),
+		Override(new(*asset.Manager), modules.NewAssetsManager(cfg.FetchBatch, 0)),
 		Override(new(*validation.Validation), modules.NewNodeValidation),
 		Override(new(*rate.Limiter), modules.NewRateLimiter),
 		Override(new(*asset.Asset), asset.NewAsset),
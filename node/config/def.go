@@ -69,6 +69,8 @@ func DefaultCandidateCfg() *CandidateCfg {
 		EdgeCfg:    edgeCfg,
 		TCPSrvAddr: "0.0.0.0:9000",
 		IpfsAPIURL: "http://127.0.0.1:5001",
+
+		ResumableUploadTTL: 24,
 	}
 }
 
@@ -89,19 +91,33 @@ func DefaultLocatorCfg() *LocatorCfg {
 // DefaultSchedulerCfg returns the default scheduler config
 func DefaultSchedulerCfg() *SchedulerCfg {
 	return &SchedulerCfg{
-		RPCURL:             "https://localhost:3456/rpc/v0",
-		ListenAddress:      "0.0.0.0:3456",
-		InsecureSkipVerify: true,
-		CertificatePath:    "",
-		PrivateKeyPath:     "",
-		CaCertificatePath:  "",
-		AreaID:             "CN-GD-Shenzhen",
-		DatabaseAddress:    "user01:sql001@tcp(127.0.0.1:3306)/titan",
-		EnableValidation:   true,
-		EtcdAddresses:      []string{"192.168.0.160:2379"},
-		CandidateReplicas:  0,
-		ValidatorRatio:     1,
-		ValidatorBaseBwDn:  100,
+		RPCURL:                 "https://localhost:3456/rpc/v0",
+		ListenAddress:          "0.0.0.0:3456",
+		InsecureSkipVerify:     true,
+		CertificatePath:        "",
+		PrivateKeyPath:         "",
+		CaCertificatePath:      "",
+		AreaID:                 "CN-GD-Shenzhen",
+		DatabaseAddress:        "user01:sql001@tcp(127.0.0.1:3306)/titan",
+		EnableValidation:       true,
+		EtcdAddresses:          []string{"192.168.0.160:2379"},
+		CandidateReplicas:      0,
+		ValidatorRatio:         1,
+		ValidatorBaseBwDn:      100,
+		PlacementPolicy:        "random",
+		ReplicaScalingDemand:   100,
+		MaxEdgeSourceRatio:     0,
+		EnableRebalance:        false,
+		RebalanceHighWatermark: 90,
+		RebalanceLowWatermark:  60,
+		RebalanceMovesPerRound: 5,
+		AssetDeleteGracePeriod: 0,
+		AssetPullTimeout:       60,
+		AssetRetryInterval:     60,
+		AssetMaxRetries:        3,
+		NodeSelectAttempts:     3,
+		ResumableUploadTTL:     24,
+		MaxReplicaReplacements: 10,
 	}
 }
 
@@ -46,6 +46,8 @@ type CandidateCfg struct {
 	TCPSrvAddr       string
 	IpfsAPIURL       string
 	ValidateDuration int
+	// ResumableUploadTTL the hours that an unfinished resumable upload is kept after its last write
+	ResumableUploadTTL int
 }
 
 // LocatorCfg locator config
@@ -104,4 +106,32 @@ type SchedulerCfg struct {
 	ValidatorRatio float64
 	// The base downstream bandwidth per validator window (unit : MiB)
 	ValidatorBaseBwDn int
+	// Policy to select the replica nodes, random, locality or score
+	PlacementPolicy string
+	// Download demand served by one edge replica in a scaling window (30 minutes), 0 disables the replica scaling
+	ReplicaScalingDemand int
+	// Maximum share of the pull sources that are edges (0 ~ 1), 0 disables the edges as pull sources
+	MaxEdgeSourceRatio float64
+	// config to enable moving replicas off the nodes under disk pressure, default: false
+	EnableRebalance bool
+	// Disk usage (%) above which the replicas of a node are moved to other nodes
+	RebalanceHighWatermark float64
+	// Disk usage (%) below which a node receives the moved replicas
+	RebalanceLowWatermark float64
+	// Maximum number of replica moves started in a rebalance round (10 minutes)
+	RebalanceMovesPerRound int
+	// Hours that a removed or expired asset is kept in the pending delete state before it is purged, 0 deletes the asset at once
+	AssetDeleteGracePeriod int
+	// Default seconds without pull progress before the pulls of an asset are failed
+	AssetPullTimeout int
+	// Default seconds to wait before the failed pulls of an asset are retried
+	AssetRetryInterval int
+	// Default maximum number of retries of the failed pulls of an asset
+	AssetMaxRetries int
+	// Default attempts per replica to select a random node for an asset
+	NodeSelectAttempts int
+	// Hours that an uploaded asset can be reported in after its upload ticket expires, a resumable upload must be finished in the time
+	ResumableUploadTTL int
+	// Maximum number of failed replicas replaced by other nodes in a pulling phase of an asset
+	MaxReplicaReplacements int
 }
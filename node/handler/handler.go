@@ -12,9 +12,11 @@ type (
 	RemoteAddr struct{}
 	// RemoteAddr node ID
 	NodeID struct{}
+	// UserID user ID, it is set only by the verification of the token of the user
+	UserID struct{}
 )
 
-// Handler represents an HTTP handler that also adds remote client address and node ID to the request context
+// Handler represents an HTTP handler that also adds remote client address, node ID and user ID to the request context
 type Handler struct {
 	handler *auth.Handler
 }
@@ -37,12 +39,29 @@ func GetNodeID(ctx context.Context) string {
 	return v
 }
 
+// GetUserID returns the user ID of the verified token of the client, empty if the client is not an authenticated user
+func GetUserID(ctx context.Context) string {
+	v, ok := ctx.Value(UserID{}).(*string)
+	if !ok {
+		return ""
+	}
+	return *v
+}
+
+// SetUserID sets the user ID of the verified token of the client to the request context
+func SetUserID(ctx context.Context, userID string) {
+	v, ok := ctx.Value(UserID{}).(*string)
+	if ok {
+		*v = userID
+	}
+}
+
 // New returns a new HTTP handler with the given auth handler and additional request context fields
 func New(ah *auth.Handler) http.Handler {
 	return &Handler{ah}
 }
 
-// ServeHTTP serves an HTTP request with the added client remote address and node ID in the request context
+// ServeHTTP serves an HTTP request with the added client remote address, node ID and user ID in the request context
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	remoteAddr := r.Header.Get("X-Remote-Addr")
 	if remoteAddr == "" {
@@ -54,6 +73,8 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, RemoteAddr{}, remoteAddr)
 	ctx = context.WithValue(ctx, NodeID{}, nodeID)
+	// the user id is taken from the token when it is verified, never from the request header
+	ctx = context.WithValue(ctx, UserID{}, new(string))
 
 	h.handler.ServeHTTP(w, r.WithContext(ctx))
 }
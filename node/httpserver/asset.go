@@ -3,9 +3,11 @@ package httpserver
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-libipfs/blocks"
+	"github.com/linguohua/titan/api/types"
 )
 
 // Asset represents an interface for fetching and checking asset data.
@@ -18,4 +20,28 @@ type Asset interface {
 	HasBlock(ctx context.Context, root, block cid.Cid) (bool, error)
 	// GetBlock retrieves a block with the given CID from the asset data for a given root CID.
 	GetBlock(ctx context.Context, root, block cid.Cid) (blocks.Block, error)
+	// StoreBlocks stores the blocks of the asset being uploaded.
+	StoreBlocks(ctx context.Context, root cid.Cid, blks []blocks.Block) error
+	// DeleteBlocks removes the blocks of the asset that failed to upload.
+	DeleteBlocks(root cid.Cid) error
+	// StoreAsset stores the uploaded blocks as the car file of the asset.
+	StoreAsset(ctx context.Context, root cid.Cid) error
+	// DeleteAsset removes the uploaded asset that failed to be reported.
+	DeleteAsset(root cid.Cid) error
+	// SetBlockCount sets the block count of the asset.
+	SetBlockCount(ctx context.Context, root cid.Cid, count uint32) error
+	// StoreFile builds the file into a UnixFS dag and stores it as an asset, it returns the root CID, the block count and whether the asset is created.
+	StoreFile(ctx context.Context, file io.ReadSeeker, opts *types.UnixFSOptions) (cid.Cid, int, bool, error)
+	// UseTicket records the upload ticket as used until its expiration, an error satisfying os.IsExist is returned if it is already used.
+	UseTicket(id string, expiration time.Time) error
+	// CreateUpload saves the meta of a resumable upload.
+	CreateUpload(id string, meta []byte) error
+	// GetUpload retrieves the meta of a resumable upload and the size of the data written.
+	GetUpload(id string) ([]byte, int64, error)
+	// WriteUpload appends the data to a resumable upload at the offset, the offset must be the size of the data written.
+	WriteUpload(id string, offset int64, r io.Reader) (int64, error)
+	// OpenUpload opens the data of a resumable upload.
+	OpenUpload(id string) (io.ReadSeekCloser, error)
+	// DeleteUpload removes a resumable upload.
+	DeleteUpload(id string) error
 }
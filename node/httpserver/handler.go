@@ -17,6 +17,7 @@ var log = logging.Logger("httpserver")
 
 const (
 	ipfsPathPrefix        = "/ipfs"
+	uploadPath            = "/upload"
 	immutableCacheControl = "public, max-age=29030400, immutable"
 )
 
@@ -32,6 +33,12 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch {
 	case strings.HasPrefix(r.URL.Path, ipfsPathPrefix):
 		h.hs.handler(w, r)
+	case r.URL.Path == uploadPath:
+		h.hs.uploadHandler(w, r)
+	case r.URL.Path == resumableUploadPath || strings.HasPrefix(r.URL.Path, resumableUploadPath+"/"):
+		h.hs.resumableUploadHandler(w, r)
+	case r.URL.Path == s3PathPrefix || strings.HasPrefix(r.URL.Path, s3PathPrefix+"/"):
+		h.hs.s3Handler(w, r)
 	default:
 		h.handler.ServeHTTP(w, r)
 	}
@@ -10,6 +10,7 @@ import (
 	"mime"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/linguohua/titan/api/types"
 	titanrsa "github.com/linguohua/titan/node/rsa"
@@ -41,6 +42,12 @@ func (hs *HttpServer) getHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the blocks of a private asset are opaque to the node, the client decrypts the content after download
+	if ticket.Private && respFormat != formatRaw && respFormat != formatCar {
+		http.Error(w, "private asset is only served in raw and car formats", http.StatusBadRequest)
+		return
+	}
+
 	switch respFormat {
 	case "", formatJSON, formatCbor: // The implicit response format is UnixFS
 		hs.serveUnixFS(w, r, ticket)
@@ -78,36 +85,48 @@ func (hs *HttpServer) verifyCredentials(w http.ResponseWriter, r *http.Request)
 		return nil, xerrors.Errorf("decode GatewayCredentials error %w", err)
 	}
 
-	sign, err := hex.DecodeString(gwCredentials.Sign)
+	credentials := &types.Credentials{}
+	err = hs.decryptCredentials(gwCredentials, credentials)
 	if err != nil {
 		return nil, err
 	}
 
+	// the credentials are also handed to the peer edges as pull sources, they must not be reused after expiration
+	if credentials.ValidTime > 0 && time.Now().Unix() > credentials.ValidTime {
+		return nil, xerrors.Errorf("credentials expired at %s", time.Unix(credentials.ValidTime, 0).String())
+	}
+
+	return credentials, nil
+}
+
+// decryptCredentials verifies the scheduler sign of the credentials and decrypts them into v
+func (hs *HttpServer) decryptCredentials(gwCredentials *types.GatewayCredentials, v interface{}) error {
+	if hs.schedulerPublicKey == nil {
+		return fmt.Errorf("scheduler public key not exist, can not verify sign")
+	}
+
+	sign, err := hex.DecodeString(gwCredentials.Sign)
+	if err != nil {
+		return err
+	}
+
 	ciphertext, err := hex.DecodeString(gwCredentials.Ciphertext)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	rsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
 	err = rsa.VerifySign(hs.schedulerPublicKey, sign, ciphertext)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	mgs, err := rsa.Decrypt(ciphertext, hs.privateKey)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	buffer = bytes.NewBuffer(mgs)
-	dec = gob.NewDecoder(buffer)
-
-	credentials := &types.Credentials{}
-	err = dec.Decode(credentials)
-	if err != nil {
-		return nil, err
-	}
-	return credentials, nil
+	return gob.NewDecoder(bytes.NewBuffer(mgs)).Decode(v)
 }
 
 // customResponseFormat checks the request's Accept header and query parameters to determine the desired response format
@@ -0,0 +1,276 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// resumableUploadPath is the path of the tus resumable uploads, an upload is addressed by the id of its ticket, e.g. /upload/resumable/<ticket id>
+	resumableUploadPath = "/upload/resumable"
+	tusVersion          = "1.0.0"
+	tusExtensions       = "creation,termination"
+	tusContentType      = "application/offset+octet-stream"
+	// assetCIDHeader carries the root cid of the asset when the resumable upload is finished
+	assetCIDHeader = "Titan-Asset-CID"
+)
+
+// resumableUpload is the meta of a resumable upload saved with its data
+type resumableUpload struct {
+	TicketID string
+	Length   int64
+}
+
+// resumableUploadHandler serves the tus resumable uploads with the creation and termination extensions,
+// the upload ticket is required in every request, and the file is built into a UnixFS asset when it is completely uploaded
+func (hs *HttpServer) resumableUploadHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Tus-Version", tusVersion)
+		w.Header().Set("Tus-Extension", tusExtensions)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if types.RunningNodeType != types.NodeCandidate {
+		http.Error(w, "only candidate can receive uploads", http.StatusBadRequest)
+		return
+	}
+
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		http.Error(w, fmt.Sprintf("unsupported tus version %s", r.Header.Get("Tus-Resumable")), http.StatusPreconditionFailed)
+		return
+	}
+
+	ticket, err := hs.decodeUploadTicket(r)
+	if err != nil {
+		log.Warnf("decode upload ticket error:%s", err.Error())
+		http.Error(w, fmt.Sprintf("verify upload ticket error : %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, resumableUploadPath), "/")
+	if id == "" {
+		if r.Method != http.MethodPost {
+			http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		hs.createResumableUpload(w, r, ticket)
+		return
+	}
+
+	if id != ticket.ID {
+		http.Error(w, "the ticket does not match the upload", http.StatusForbidden)
+		return
+	}
+
+	// the scheduler drops the upload reported after the ticket expiration
+	if ticket.Expiration > 0 && time.Now().Unix() > ticket.Expiration && r.Method != http.MethodDelete {
+		http.Error(w, fmt.Sprintf("upload expired at %s", time.Unix(ticket.Expiration, 0).String()), http.StatusGone)
+		return
+	}
+
+	if !hs.lockResumableUpload(id) {
+		http.Error(w, "the upload is being written", http.StatusLocked)
+		return
+	}
+	defer hs.unlockResumableUpload(id)
+
+	upload, offset, err := hs.loadResumableUpload(id)
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, "upload not found", http.StatusNotFound)
+			return
+		}
+
+		http.Error(w, fmt.Sprintf("load upload error : %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+		w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPatch:
+		hs.patchResumableUpload(w, r, upload, offset)
+	case http.MethodDelete:
+		if err := hs.asset.DeleteUpload(id); err != nil {
+			http.Error(w, fmt.Sprintf("delete upload error : %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+	}
+}
+
+// createResumableUpload creates the upload of the ticket, the ticket must not be expired or used and the length must not exceed its max size
+func (hs *HttpServer) createResumableUpload(w http.ResponseWriter, r *http.Request, ticket *types.UploadTicket) {
+	if time.Now().Unix() > ticket.ValidTime {
+		http.Error(w, fmt.Sprintf("ticket expired at %s", time.Unix(ticket.ValidTime, 0).String()), http.StatusUnauthorized)
+		return
+	}
+
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		http.Error(w, "invalid Upload-Length", http.StatusBadRequest)
+		return
+	}
+
+	if length > ticket.MaxSize {
+		http.Error(w, fmt.Sprintf("upload length %d exceeds the limit %d", length, ticket.MaxSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	meta, err := json.Marshal(&resumableUpload{TicketID: ticket.ID, Length: length})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !hs.useUploadTicket(w, ticket) {
+		return
+	}
+
+	if err := hs.asset.CreateUpload(ticket.ID, meta); err != nil {
+		if os.IsExist(err) {
+			http.Error(w, "the upload of the ticket already exists", http.StatusConflict)
+			return
+		}
+
+		http.Error(w, fmt.Sprintf("create upload error : %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", resumableUploadPath+"/"+ticket.ID)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// patchResumableUpload appends the body to the upload at the offset, the upload is finished when its length is reached.
+// A finished upload that failed to be stored or reported can be retried with an empty body at the final offset
+func (hs *HttpServer) patchResumableUpload(w http.ResponseWriter, r *http.Request, upload *resumableUpload, offset int64) {
+	if r.Header.Get("Content-Type") != tusContentType {
+		http.Error(w, fmt.Sprintf("content type must be %s", tusContentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	reqOffset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid Upload-Offset", http.StatusBadRequest)
+		return
+	}
+
+	if reqOffset != offset {
+		http.Error(w, fmt.Sprintf("offset %d does not match the upload offset %d", reqOffset, offset), http.StatusConflict)
+		return
+	}
+
+	remaining := upload.Length - offset
+	if r.ContentLength > remaining {
+		http.Error(w, fmt.Sprintf("body size %d exceeds the remaining %d", r.ContentLength, remaining), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	n, err := hs.asset.WriteUpload(upload.TicketID, offset, io.LimitReader(r.Body, remaining))
+	offset += n
+	w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+
+	if err != nil {
+		log.Errorf("write upload %s error:%s", upload.TicketID, err.Error())
+		http.Error(w, fmt.Sprintf("write upload error : %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if offset == upload.Length {
+		result, err := hs.finishResumableUpload(r.Context(), upload)
+		if err != nil {
+			log.Errorf("finish upload %s error:%s", upload.TicketID, err.Error())
+			http.Error(w, fmt.Sprintf("finish upload error : %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set(assetCIDHeader, result.CID)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// finishResumableUpload builds the uploaded file into a UnixFS asset and reports it to the scheduler, the upload is removed after it is reported
+func (hs *HttpServer) finishResumableUpload(ctx context.Context, upload *resumableUpload) (*types.UploadResult, error) {
+	file, err := hs.asset.OpenUpload(upload.TicketID)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() //nolint:errcheck
+
+	root, blockCount, created, err := hs.asset.StoreFile(ctx, file, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("store file: %w", err)
+	}
+
+	result := &types.UploadResult{TicketID: upload.TicketID, CID: root.String(), Size: upload.Length, Blocks: int64(blockCount)}
+	if err := hs.scheduler.NodeUploadResult(ctx, result); err != nil {
+		// the upload is kept to be reported again, the asset is stored again then
+		if created {
+			hs.removeUploadedAsset(root)
+		}
+		return nil, xerrors.Errorf("report upload: %w", err)
+	}
+
+	if err := hs.asset.DeleteUpload(upload.TicketID); err != nil {
+		log.Errorf("delete upload %s error:%s", upload.TicketID, err.Error())
+	}
+
+	return result, nil
+}
+
+// loadResumableUpload returns the meta of the upload and the size of the data written
+func (hs *HttpServer) loadResumableUpload(id string) (*resumableUpload, int64, error) {
+	data, offset, err := hs.asset.GetUpload(id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	upload := &resumableUpload{}
+	if err := json.Unmarshal(data, upload); err != nil {
+		return nil, 0, err
+	}
+
+	return upload, offset, nil
+}
+
+// lockResumableUpload marks the upload as being written, false if it is already marked
+func (hs *HttpServer) lockResumableUpload(id string) bool {
+	hs.resumableLock.Lock()
+	defer hs.resumableLock.Unlock()
+
+	if _, ok := hs.resumableBusy[id]; ok {
+		return false
+	}
+
+	hs.resumableBusy[id] = struct{}{}
+	return true
+}
+
+// unlockResumableUpload removes the mark of the upload
+func (hs *HttpServer) unlockResumableUpload(id string) {
+	hs.resumableLock.Lock()
+	defer hs.resumableLock.Unlock()
+
+	delete(hs.resumableBusy, id)
+}
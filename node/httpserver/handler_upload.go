@@ -0,0 +1,284 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	legacy "github.com/ipfs/go-ipld-legacy"
+	"github.com/ipfs/go-libipfs/blocks"
+	"github.com/ipld/go-car/v2"
+	"github.com/linguohua/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// uploadTicketHeader carries the upload ticket as "Ciphertext.Sign"
+	uploadTicketHeader = "Titan-Upload-Ticket"
+	// uploadBlocksBatch is the number of blocks stored in a batch when the car file is uploaded
+	uploadBlocksBatch = 64
+)
+
+// uploadResponse is the response of a succeeded upload
+type uploadResponse struct {
+	CID    string `json:"cid"`
+	Size   int64  `json:"size"`
+	Blocks int64  `json:"blocks"`
+}
+
+// uploadHandler stores the car file posted with an upload ticket, the root cid is reported to the scheduler to replicate the asset
+func (hs *HttpServer) uploadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if types.RunningNodeType != types.NodeCandidate {
+		http.Error(w, "only candidate can receive uploads", http.StatusBadRequest)
+		return
+	}
+
+	ticket, err := hs.verifyUploadTicket(r)
+	if err != nil {
+		log.Warnf("verify upload ticket error:%s", err.Error())
+		http.Error(w, fmt.Sprintf("verify upload ticket error : %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+
+	if r.ContentLength > ticket.MaxSize {
+		http.Error(w, fmt.Sprintf("car size %d exceeds the limit %d", r.ContentLength, ticket.MaxSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if !hs.useUploadTicket(w, ticket) {
+		return
+	}
+
+	body := &countReader{r: io.LimitReader(r.Body, ticket.MaxSize+1)}
+
+	root, blockCount, created, err := hs.storeUploadedCar(r.Context(), body)
+	if body.n > ticket.MaxSize {
+		http.Error(w, fmt.Sprintf("car size exceeds the limit %d", ticket.MaxSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err != nil {
+		log.Errorf("store uploaded car error:%s", err.Error())
+		http.Error(w, fmt.Sprintf("store car error : %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	result := &types.UploadResult{TicketID: ticket.ID, CID: root.String(), Size: body.n, Blocks: int64(blockCount)}
+	if err := hs.scheduler.NodeUploadResult(r.Context(), result); err != nil {
+		log.Errorf("upload result %s error:%s", root.String(), err.Error())
+		if created {
+			hs.removeUploadedAsset(root)
+		}
+		http.Error(w, fmt.Sprintf("report upload error : %s", err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&uploadResponse{CID: result.CID, Size: result.Size, Blocks: result.Blocks}); err != nil {
+		log.Errorf("write upload response error:%s", err.Error())
+	}
+}
+
+// verifyUploadTicket checks the upload ticket of the request is signed by the scheduler and not expired
+func (hs *HttpServer) verifyUploadTicket(r *http.Request) (*types.UploadTicket, error) {
+	ticket, err := hs.decodeUploadTicket(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().Unix() > ticket.ValidTime {
+		return nil, xerrors.Errorf("ticket expired at %s", time.Unix(ticket.ValidTime, 0).String())
+	}
+
+	return ticket, nil
+}
+
+// useUploadTicket records the ticket as used so that it works only once, the error is written if it fails
+func (hs *HttpServer) useUploadTicket(w http.ResponseWriter, ticket *types.UploadTicket) bool {
+	err := hs.asset.UseTicket(ticket.ID, time.Unix(ticket.ValidTime, 0))
+	if err == nil {
+		return true
+	}
+
+	if os.IsExist(err) {
+		http.Error(w, "the ticket is already used", http.StatusConflict)
+		return false
+	}
+
+	http.Error(w, fmt.Sprintf("use ticket error : %s", err.Error()), http.StatusInternalServerError)
+	return false
+}
+
+// decodeUploadTicket checks the upload ticket of the request is signed by the scheduler, the valid time is not checked
+func (hs *HttpServer) decodeUploadTicket(r *http.Request) (*types.UploadTicket, error) {
+	parts := strings.SplitN(r.Header.Get(uploadTicketHeader), ".", 2)
+	if len(parts) != 2 {
+		return nil, xerrors.Errorf("header %s not found", uploadTicketHeader)
+	}
+
+	ticket := &types.UploadTicket{}
+	err := hs.decryptCredentials(&types.GatewayCredentials{Ciphertext: parts[0], Sign: parts[1]}, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	return ticket, nil
+}
+
+// storeUploadedCar verifies the blocks of the car and stores them as an asset, the car must hold the complete dag of its single root.
+// The block reader checks every block against its cid, the links of the blocks are checked after all blocks are read,
+// the returned bool is false if the asset already exists before the upload
+func (hs *HttpServer) storeUploadedCar(ctx context.Context, r io.Reader) (cid.Cid, int, bool, error) {
+	br, err := car.NewBlockReader(r)
+	if err != nil {
+		return cid.Undef, 0, false, err
+	}
+
+	if len(br.Roots) != 1 {
+		return cid.Undef, 0, false, xerrors.Errorf("car must have 1 root, got %d", len(br.Roots))
+	}
+
+	root := br.Roots[0]
+
+	exists, err := hs.asset.AssetExists(root)
+	if err != nil {
+		return cid.Undef, 0, false, err
+	}
+
+	dag := newUploadedDAG()
+	batch := make([]blocks.Block, 0, uploadBlocksBatch)
+	for {
+		blk, err := br.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err == nil {
+			err = dag.add(ctx, blk)
+		}
+
+		if err == nil && !exists {
+			if batch = append(batch, blk); len(batch) >= uploadBlocksBatch {
+				err = hs.asset.StoreBlocks(ctx, root, batch)
+				batch = batch[:0]
+			}
+		}
+
+		if err != nil {
+			return cid.Undef, 0, false, hs.abortUpload(root, exists, err)
+		}
+	}
+
+	if err := dag.complete(root); err != nil {
+		return cid.Undef, 0, false, hs.abortUpload(root, exists, err)
+	}
+
+	if exists {
+		return root, dag.count(), false, nil
+	}
+
+	if len(batch) > 0 {
+		if err := hs.asset.StoreBlocks(ctx, root, batch); err != nil {
+			return cid.Undef, 0, false, hs.abortUpload(root, exists, err)
+		}
+	}
+
+	if err := hs.asset.SetBlockCount(ctx, root, uint32(dag.count())); err != nil {
+		return cid.Undef, 0, false, hs.abortUpload(root, exists, err)
+	}
+
+	if err := hs.asset.StoreAsset(ctx, root); err != nil {
+		return cid.Undef, 0, false, hs.abortUpload(root, exists, err)
+	}
+
+	return root, dag.count(), true, nil
+}
+
+// abortUpload removes the stored blocks of the failed upload and returns the error
+func (hs *HttpServer) abortUpload(root cid.Cid, exists bool, err error) error {
+	if exists {
+		return err
+	}
+
+	if e := hs.asset.DeleteBlocks(root); e != nil {
+		log.Errorf("remove uploaded blocks error:%s", e.Error())
+	}
+
+	return err
+}
+
+// removeUploadedAsset removes the stored asset that failed to be reported, the scheduler does not know it and it would never be replicated or deleted
+func (hs *HttpServer) removeUploadedAsset(root cid.Cid) {
+	if err := hs.asset.DeleteAsset(root); err != nil {
+		log.Errorf("remove uploaded asset %s error:%s", root.String(), err.Error())
+	}
+}
+
+// uploadedDAG records the blocks of the uploaded car and the links between them
+type uploadedDAG struct {
+	blocks map[cid.Cid]struct{}
+	links  map[cid.Cid]struct{}
+}
+
+func newUploadedDAG() *uploadedDAG {
+	return &uploadedDAG{blocks: make(map[cid.Cid]struct{}), links: make(map[cid.Cid]struct{})}
+}
+
+// add records the block and its links
+func (d *uploadedDAG) add(ctx context.Context, blk blocks.Block) error {
+	node, err := legacy.DecodeNode(ctx, blk)
+	if err != nil {
+		return xerrors.Errorf("decode block %s error %w", blk.Cid().String(), err)
+	}
+
+	d.blocks[blk.Cid()] = struct{}{}
+	for _, link := range node.Links() {
+		d.links[link.Cid] = struct{}{}
+	}
+
+	return nil
+}
+
+// complete checks the root and all the links are in the blocks
+func (d *uploadedDAG) complete(root cid.Cid) error {
+	if _, ok := d.blocks[root]; !ok {
+		return xerrors.Errorf("root block %s not found", root.String())
+	}
+
+	for c := range d.links {
+		if _, ok := d.blocks[c]; !ok {
+			return xerrors.Errorf("linked block %s not found", c.String())
+		}
+	}
+
+	return nil
+}
+
+// count returns the number of distinct blocks
+func (d *uploadedDAG) count() int {
+	return len(d.blocks)
+}
+
+// countReader counts the bytes read from the reader
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,105 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	legacy "github.com/ipfs/go-ipld-legacy"
+	"github.com/ipfs/go-libipfs/blocks"
+	"github.com/ipfs/go-merkledag"
+	carv2 "github.com/ipld/go-car/v2"
+	carstorage "github.com/ipld/go-car/v2/storage"
+	dagpb "github.com/ipld/go-codec-dagpb"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+)
+
+// memAsset keeps the uploaded blocks in memory
+type memAsset struct {
+	Asset
+	blocks map[cid.Cid]blocks.Block
+	stored bool
+}
+
+func (m *memAsset) AssetExists(root cid.Cid) (bool, error) { return m.stored, nil }
+
+func (m *memAsset) StoreBlocks(ctx context.Context, root cid.Cid, blks []blocks.Block) error {
+	for _, blk := range blks {
+		m.blocks[blk.Cid()] = blk
+	}
+	return nil
+}
+
+func (m *memAsset) DeleteBlocks(root cid.Cid) error {
+	m.blocks = make(map[cid.Cid]blocks.Block)
+	return nil
+}
+
+func (m *memAsset) StoreAsset(ctx context.Context, root cid.Cid) error {
+	m.stored = true
+	return nil
+}
+
+func (m *memAsset) SetBlockCount(ctx context.Context, root cid.Cid, count uint32) error { return nil }
+
+// writeCar writes the blocks into a car of the root
+func writeCar(t *testing.T, root cid.Cid, blks ...blocks.Block) io.Reader {
+	buf := &bytes.Buffer{}
+	w, err := carstorage.NewWritable(buf, []cid.Cid{root}, carv2.WriteAsCarV1(true))
+	if err != nil {
+		t.Fatalf("new car err:%s", err)
+	}
+
+	for _, blk := range blks {
+		if err := w.Put(context.Background(), blk.Cid().KeyString(), blk.RawData()); err != nil {
+			t.Fatalf("put block err:%s", err)
+		}
+	}
+
+	if err := w.Finalize(); err != nil {
+		t.Fatalf("finalize car err:%s", err)
+	}
+
+	return buf
+}
+
+func TestStoreUploadedCar(t *testing.T) {
+	legacy.RegisterCodec(cid.DagProtobuf, dagpb.Type.PBNode, merkledag.ProtoNodeConverter)
+	legacy.RegisterCodec(cid.Raw, basicnode.Prototype.Bytes, merkledag.RawNodeConverter)
+
+	leaf := merkledag.NewRawNode([]byte("titan"))
+	node := &merkledag.ProtoNode{}
+	if err := node.AddNodeLink("leaf", leaf); err != nil {
+		t.Fatalf("add link err:%s", err)
+	}
+
+	asset := &memAsset{blocks: make(map[cid.Cid]blocks.Block)}
+	hs := &HttpServer{asset: asset}
+
+	// the leaf linked by the root is missing
+	_, _, _, err := hs.storeUploadedCar(context.Background(), writeCar(t, node.Cid(), node))
+	if err == nil {
+		t.Errorf("expect the incomplete car to be rejected")
+	}
+
+	if len(asset.blocks) != 0 || asset.stored {
+		t.Errorf("expect the blocks of the incomplete car to be removed")
+	}
+
+	root, count, created, err := hs.storeUploadedCar(context.Background(), writeCar(t, node.Cid(), node, leaf))
+	if err != nil {
+		t.Fatalf("store car err:%s", err)
+	}
+
+	if !root.Equals(node.Cid()) || count != 2 || len(asset.blocks) != 2 || !asset.stored || !created {
+		t.Errorf("unexpected upload root %s, count %d, blocks %d, stored %v", root, count, len(asset.blocks), asset.stored)
+	}
+
+	// the asset stored before is not removed if the upload fails to be reported
+	_, _, created, err = hs.storeUploadedCar(context.Background(), writeCar(t, node.Cid(), node, leaf))
+	if err != nil || created {
+		t.Errorf("expect the existing asset to be kept, created %v, err %v", created, err)
+	}
+}
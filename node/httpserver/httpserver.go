@@ -5,6 +5,7 @@ import (
 	"crypto/rsa"
 	"fmt"
 	gopath "path"
+	"sync"
 
 	"github.com/ipfs/go-blockservice"
 	"github.com/ipfs/go-cid"
@@ -28,11 +29,25 @@ type HttpServer struct {
 	scheduler          api.Scheduler
 	privateKey         *rsa.PrivateKey
 	schedulerPublicKey *rsa.PublicKey
+
+	// s3Keys caches the access keys of the s3 api
+	s3KeysLock sync.Mutex
+	s3Keys     map[string]*s3CachedKey
+
+	// resumableBusy marks the resumable uploads being written
+	resumableLock sync.Mutex
+	resumableBusy map[string]struct{}
 }
 
 // NewHttpServer creates a new HttpServer with the given Asset, Scheduler, and RSA private key.
 func NewHttpServer(asset Asset, scheduler api.Scheduler, privateKey *rsa.PrivateKey) *HttpServer {
-	hs := &HttpServer{asset: asset, scheduler: scheduler, privateKey: privateKey}
+	hs := &HttpServer{
+		asset:         asset,
+		scheduler:     scheduler,
+		privateKey:    privateKey,
+		s3Keys:        make(map[string]*s3CachedKey),
+		resumableBusy: make(map[string]struct{}),
+	}
 
 	return hs
 }
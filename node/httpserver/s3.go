@@ -0,0 +1,518 @@
+package httpserver
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec // md5 is the etag of the s3 objects
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	files "github.com/ipfs/go-ipfs-files"
+	"github.com/ipfs/interface-go-ipfs-core/path"
+	"github.com/linguohua/titan/api/types"
+)
+
+const (
+	// s3PathPrefix is the path prefix of the S3 compatible api, the buckets are addressed in the path style, e.g. /s3/bucket/key
+	s3PathPrefix = "/s3"
+	// s3MaxObjectSize is the maximum size of an object or a part put in a single request
+	s3MaxObjectSize = 5 << 30
+	// s3MaxKeyLength is the maximum length of an object key
+	s3MaxKeyLength = 1024
+	// s3DefaultMaxKeys is the default and the maximum number of keys in a list response
+	s3DefaultMaxKeys = 1000
+	// s3ListPageSize is the number of objects loaded from the scheduler at a time
+	s3ListPageSize = 1000
+
+	s3XMLNamespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+	s3TimeFormat   = "2006-01-02T15:04:05.000Z"
+)
+
+var s3BucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// s3Error is the error response of the S3 compatible api
+type s3Error struct {
+	status   int
+	XMLName  xml.Name `xml:"Error"`
+	Code     string
+	Message  string
+	Resource string `xml:",omitempty"`
+	Endpoint string `xml:",omitempty"`
+}
+
+// s3Request is an authenticated request of the S3 compatible api
+type s3Request struct {
+	key    *types.S3AccessKey
+	bucket string
+	object string
+}
+
+// s3Handler serves the S3 compatible api, the objects are stored as UnixFS assets and indexed by the scheduler
+func (hs *HttpServer) s3Handler(w http.ResponseWriter, r *http.Request) {
+	if types.RunningNodeType != types.NodeCandidate {
+		writeS3Error(w, r, &s3Error{status: http.StatusBadRequest, Code: "InvalidRequest", Message: "only candidate serves the s3 api"})
+		return
+	}
+
+	key, serr := hs.authenticateS3(r)
+	if serr != nil {
+		writeS3Error(w, r, serr)
+		return
+	}
+
+	bucket, object := splitS3Path(r.URL.Path)
+	req := &s3Request{key: key, bucket: bucket, object: object}
+
+	if bucket == "" {
+		writeS3Error(w, r, &s3Error{status: http.StatusNotImplemented, Code: "NotImplemented", Message: "list buckets is not supported"})
+		return
+	}
+
+	if !s3BucketName.MatchString(bucket) {
+		writeS3Error(w, r, &s3Error{status: http.StatusBadRequest, Code: "InvalidBucketName", Message: "the bucket name is not valid"})
+		return
+	}
+
+	if len(object) > s3MaxKeyLength {
+		writeS3Error(w, r, &s3Error{status: http.StatusBadRequest, Code: "KeyTooLongError", Message: "the key is too long"})
+		return
+	}
+
+	query := r.URL.Query()
+	switch {
+	case object == "":
+		hs.s3BucketHandler(w, r, req)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		hs.createMultipartUpload(w, r, req)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		hs.completeMultipartUpload(w, r, req)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		hs.uploadPart(w, r, req)
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		hs.abortMultipartUpload(w, r, req)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		writeS3Error(w, r, &s3Error{status: http.StatusNotImplemented, Code: "NotImplemented", Message: "copy object is not supported"})
+	case r.Method == http.MethodPut:
+		hs.putObject(w, r, req)
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		hs.getObject(w, r, req)
+	case r.Method == http.MethodDelete:
+		hs.deleteObject(w, r, req)
+	default:
+		writeS3Error(w, r, &s3Error{status: http.StatusMethodNotAllowed, Code: "MethodNotAllowed", Message: fmt.Sprintf("method %s not allowed", r.Method)})
+	}
+}
+
+// s3BucketHandler serves the bucket requests, the buckets exist implicitly in the namespace of the tenant
+func (hs *HttpServer) s3BucketHandler(w http.ResponseWriter, r *http.Request, req *s3Request) {
+	switch r.Method {
+	case http.MethodPut, http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		if r.URL.Query().Get("list-type") != "2" {
+			writeS3Error(w, r, &s3Error{status: http.StatusNotImplemented, Code: "NotImplemented", Message: "only list objects v2 is supported"})
+			return
+		}
+		hs.listObjectsV2(w, r, req)
+	case http.MethodDelete:
+		objs, err := hs.scheduler.ListS3Objects(r.Context(), req.key.AccessKey, &types.S3ListObjectsReq{Bucket: req.bucket, Limit: 1})
+		if err != nil {
+			writeS3InternalError(w, r, err)
+			return
+		}
+
+		if len(objs) > 0 {
+			writeS3Error(w, r, &s3Error{status: http.StatusConflict, Code: "BucketNotEmpty", Message: "the bucket is not empty"})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, r, &s3Error{status: http.StatusMethodNotAllowed, Code: "MethodNotAllowed", Message: fmt.Sprintf("method %s not allowed", r.Method)})
+	}
+}
+
+// putObject stores the body as a UnixFS asset and saves the object to the scheduler
+func (hs *HttpServer) putObject(w http.ResponseWriter, r *http.Request, req *s3Request) {
+	if r.ContentLength < 0 {
+		writeS3Error(w, r, &s3Error{status: http.StatusLengthRequired, Code: "MissingContentLength", Message: "the content length is required"})
+		return
+	}
+
+	if r.ContentLength > s3MaxObjectSize {
+		writeS3Error(w, r, &s3Error{status: http.StatusBadRequest, Code: "EntityTooLarge", Message: "the object exceeds the maximum allowed size"})
+		return
+	}
+
+	file, err := os.CreateTemp("", "titan-s3-object-*")
+	if err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+	defer os.Remove(file.Name()) //nolint:errcheck
+	defer file.Close()           //nolint:errcheck
+
+	size, sum, serr := readS3Payload(r, file, s3MaxObjectSize)
+	if serr != nil {
+		writeS3Error(w, r, serr)
+		return
+	}
+
+	etag := hex.EncodeToString(sum)
+	if serr := hs.storeS3Object(r, req, file, size, etag); serr != nil {
+		writeS3Error(w, r, serr)
+		return
+	}
+
+	w.Header().Set("ETag", strconv.Quote(etag))
+	w.WriteHeader(http.StatusOK)
+}
+
+// storeS3Object stores the file as a UnixFS asset and saves the object to the scheduler
+func (hs *HttpServer) storeS3Object(r *http.Request, req *s3Request, file io.ReadSeeker, size int64, etag string) *s3Error {
+	root, _, created, err := hs.asset.StoreFile(r.Context(), file, nil)
+	if err != nil {
+		log.Errorf("store s3 object %s/%s error:%s", req.bucket, req.object, err.Error())
+		return &s3Error{status: http.StatusInternalServerError, Code: "InternalError", Message: err.Error()}
+	}
+
+	obj := &types.S3Object{
+		Bucket:      req.bucket,
+		Key:         req.object,
+		CID:         root.String(),
+		Size:        size,
+		ETag:        etag,
+		ContentType: r.Header.Get("Content-Type"),
+	}
+
+	if err := hs.scheduler.PutS3Object(r.Context(), req.key.AccessKey, obj); err != nil {
+		log.Errorf("put s3 object %s/%s error:%s", req.bucket, req.object, err.Error())
+		if created {
+			hs.removeUploadedAsset(root)
+		}
+		return &s3Error{status: http.StatusInternalServerError, Code: "InternalError", Message: err.Error()}
+	}
+
+	return nil
+}
+
+// getObject serves the content of the object, the request is redirected to the candidate with the asset if the asset is not stored here
+func (hs *HttpServer) getObject(w http.ResponseWriter, r *http.Request, req *s3Request) {
+	obj, err := hs.scheduler.GetS3Object(r.Context(), req.key.AccessKey, req.bucket, req.object)
+	if err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+
+	if obj == nil {
+		writeS3Error(w, r, &s3Error{status: http.StatusNotFound, Code: "NoSuchKey", Message: "the specified key does not exist"})
+		return
+	}
+
+	root, err := cid.Decode(obj.CID)
+	if err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+
+	exists, err := hs.asset.AssetExists(root)
+	if err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+
+	if !exists {
+		hs.redirectS3Object(w, r, obj)
+		return
+	}
+
+	node, err := hs.getUnixFsNode(r.Context(), path.IpfsPath(root), root)
+	if err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+	defer node.Close() //nolint:errcheck
+
+	file, ok := node.(files.File)
+	if !ok {
+		writeS3InternalError(w, r, fmt.Errorf("asset %s is not a file", obj.CID))
+		return
+	}
+
+	contentType := obj.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", strconv.Quote(obj.ETag))
+	w.Header().Set("Accept-Ranges", "bytes")
+
+	http.ServeContent(w, r, "", obj.LastModified, &lazySeeker{size: obj.Size, reader: file})
+}
+
+// redirectS3Object redirects the request to a candidate with the asset of the object
+func (hs *HttpServer) redirectS3Object(w http.ResponseWriter, r *http.Request, obj *types.S3Object) {
+	infos, err := hs.scheduler.GetCandidateDownloadInfos(r.Context(), obj.CID)
+	if err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+
+	if len(infos) == 0 {
+		writeS3Error(w, r, &s3Error{status: http.StatusServiceUnavailable, Code: "ServiceUnavailable", Message: "the object is not available on any candidate"})
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("https://%s%s", infos[0].URL, r.URL.RequestURI()))
+	writeS3Error(w, r, &s3Error{status: http.StatusTemporaryRedirect, Code: "TemporaryRedirect", Message: "the object is served by another candidate", Endpoint: infos[0].URL})
+}
+
+// deleteObject removes the object from the scheduler, the asset is removed when it expires
+func (hs *HttpServer) deleteObject(w http.ResponseWriter, r *http.Request, req *s3Request) {
+	if err := hs.scheduler.RemoveS3Object(r.Context(), req.key.AccessKey, req.bucket, req.object); err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// s3ListBucketResult is the response of list objects v2
+type s3ListBucketResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	Xmlns                 string   `xml:"xmlns,attr"`
+	Name                  string
+	Prefix                string
+	Delimiter             string `xml:",omitempty"`
+	MaxKeys               int
+	KeyCount              int
+	IsTruncated           bool
+	ContinuationToken     string `xml:",omitempty"`
+	NextContinuationToken string `xml:",omitempty"`
+	StartAfter            string `xml:",omitempty"`
+	Contents              []s3ObjectContent
+	CommonPrefixes        []s3CommonPrefix
+}
+
+type s3ObjectContent struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int64
+	StorageClass string
+}
+
+type s3CommonPrefix struct {
+	Prefix string
+}
+
+// listObjectsV2 lists the objects of the bucket, the keys with the delimiter after the prefix are rolled up into the common prefixes
+func (hs *HttpServer) listObjectsV2(w http.ResponseWriter, r *http.Request, req *s3Request) {
+	query := r.URL.Query()
+
+	maxKeys := s3DefaultMaxKeys
+	if v := query.Get("max-keys"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeS3Error(w, r, &s3Error{status: http.StatusBadRequest, Code: "InvalidArgument", Message: "invalid max-keys"})
+			return
+		}
+
+		if n < maxKeys {
+			maxKeys = n
+		}
+	}
+
+	result := &s3ListBucketResult{
+		Xmlns:             s3XMLNamespace,
+		Name:              req.bucket,
+		Prefix:            query.Get("prefix"),
+		Delimiter:         query.Get("delimiter"),
+		MaxKeys:           maxKeys,
+		ContinuationToken: query.Get("continuation-token"),
+		StartAfter:        query.Get("start-after"),
+	}
+
+	after := result.StartAfter
+	if result.ContinuationToken != "" {
+		token, err := base64.URLEncoding.DecodeString(result.ContinuationToken)
+		if err != nil {
+			writeS3Error(w, r, &s3Error{status: http.StatusBadRequest, Code: "InvalidArgument", Message: "invalid continuation-token"})
+			return
+		}
+
+		if string(token) > after {
+			after = string(token)
+		}
+	}
+
+	loader := func(after string) ([]*types.S3Object, error) {
+		return hs.scheduler.ListS3Objects(r.Context(), req.key.AccessKey, &types.S3ListObjectsReq{
+			Bucket: req.bucket, Prefix: result.Prefix, StartAfter: after, Limit: s3ListPageSize,
+		})
+	}
+
+	list, err := listS3Objects(loader, result.Prefix, result.Delimiter, after, maxKeys)
+	if err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+
+	for _, obj := range list.objects {
+		result.Contents = append(result.Contents, s3ObjectContent{
+			Key:          obj.Key,
+			LastModified: obj.LastModified.UTC().Format(s3TimeFormat),
+			ETag:         strconv.Quote(obj.ETag),
+			Size:         obj.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+
+	for _, prefix := range list.prefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, s3CommonPrefix{Prefix: prefix})
+	}
+
+	result.KeyCount = len(list.objects) + len(list.prefixes)
+	result.IsTruncated = list.truncated
+	if list.truncated {
+		result.NextContinuationToken = base64.URLEncoding.EncodeToString([]byte(list.next))
+	}
+
+	writeS3XML(w, result)
+}
+
+// s3ObjectList is a page of the listed objects and common prefixes
+type s3ObjectList struct {
+	objects   []*types.S3Object
+	prefixes  []string
+	truncated bool
+	// next is the key that the next page is listed after
+	next string
+}
+
+// listS3Objects lists at most maxKeys objects and common prefixes after the key, the loader loads the objects of the prefix in the order of the keys.
+// The keys are utf-8, so the keys of a common prefix are skipped by listing after the prefix ended with 0xff
+func listS3Objects(loader func(after string) ([]*types.S3Object, error), prefix, delimiter, after string, maxKeys int) (*s3ObjectList, error) {
+	list := &s3ObjectList{}
+	count := 0
+
+	for {
+		objs, err := loader(after)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(objs) == 0 {
+			return list, nil
+		}
+
+		for _, obj := range objs {
+			if count >= maxKeys {
+				list.truncated = true
+				list.next = after
+				return list, nil
+			}
+
+			rest := strings.TrimPrefix(obj.Key, prefix)
+			if i := strings.Index(rest, delimiter); delimiter != "" && i >= 0 {
+				commonPrefix := prefix + rest[:i+len(delimiter)]
+				list.prefixes = append(list.prefixes, commonPrefix)
+				count++
+				after = commonPrefix + "\xff"
+				break
+			}
+
+			list.objects = append(list.objects, obj)
+			count++
+			after = obj.Key
+		}
+	}
+}
+
+// readS3Payload copies the body to the writer and returns the size and the md5 of the body,
+// the body is checked against the signed sha256 and the Content-MD5 header
+func readS3Payload(r *http.Request, w io.Writer, limit int64) (int64, []byte, *s3Error) {
+	md5Hash := md5.New() //nolint:gosec
+	sha256Hash := sha256.New()
+
+	size, err := io.Copy(io.MultiWriter(w, md5Hash, sha256Hash), io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return 0, nil, &s3Error{status: http.StatusBadRequest, Code: "IncompleteBody", Message: err.Error()}
+	}
+
+	if size > limit {
+		return 0, nil, &s3Error{status: http.StatusBadRequest, Code: "EntityTooLarge", Message: "the body exceeds the maximum allowed size"}
+	}
+
+	if hash := s3PayloadHash(r); hash != s3UnsignedPayload && hash != hex.EncodeToString(sha256Hash.Sum(nil)) {
+		return 0, nil, &s3Error{status: http.StatusBadRequest, Code: "XAmzContentSHA256Mismatch", Message: "the body does not match the signed sha256"}
+	}
+
+	sum := md5Hash.Sum(nil)
+	if contentMD5 := r.Header.Get("Content-MD5"); contentMD5 != "" && contentMD5 != base64.StdEncoding.EncodeToString(sum) {
+		return 0, nil, &s3Error{status: http.StatusBadRequest, Code: "BadDigest", Message: "the body does not match the Content-MD5"}
+	}
+
+	return size, sum, nil
+}
+
+// splitS3Path splits the path into the bucket and the object key
+func splitS3Path(p string) (string, string) {
+	p = strings.TrimPrefix(strings.TrimPrefix(p, s3PathPrefix), "/")
+
+	parts := strings.SplitN(p, "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
+
+// writeS3XML writes the xml response
+func writeS3XML(w http.ResponseWriter, v interface{}) {
+	buf := &bytes.Buffer{}
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(buf).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write(buf.Bytes()) //nolint:errcheck
+}
+
+// writeS3Error writes the xml error response, the body is omitted for the head requests
+func writeS3Error(w http.ResponseWriter, r *http.Request, serr *s3Error) {
+	serr.Resource = r.URL.Path
+
+	if r.Method == http.MethodHead {
+		w.WriteHeader(serr.status)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(buf).Encode(serr); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(serr.status)
+	w.Write(buf.Bytes()) //nolint:errcheck
+}
+
+// writeS3InternalError writes the error as an internal error
+func writeS3InternalError(w http.ResponseWriter, r *http.Request, err error) {
+	log.Errorf("s3 %s %s error:%s", r.Method, r.URL.Path, err.Error())
+	writeS3Error(w, r, &s3Error{status: http.StatusInternalServerError, Code: "InternalError", Message: err.Error()})
+}
@@ -0,0 +1,252 @@
+package httpserver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+const (
+	s3SignAlgorithm = "AWS4-HMAC-SHA256"
+	s3AmzDateFormat = "20060102T150405Z"
+	// s3MaxClockSkew is the maximum difference between the request time and the local time
+	s3MaxClockSkew = 15 * time.Minute
+	// s3AccessKeyCacheTime is the time that the access key fetched from the scheduler is cached
+	s3AccessKeyCacheTime = 5 * time.Minute
+
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3StreamingPrefix = "STREAMING-"
+)
+
+// s3Authorization is the parsed Authorization header of the AWS signature version 4
+type s3Authorization struct {
+	accessKey     string
+	date          string
+	region        string
+	service       string
+	signedHeaders []string
+	signature     string
+}
+
+// scope returns the credential scope of the signature
+func (a *s3Authorization) scope() string {
+	return strings.Join([]string{a.date, a.region, a.service, "aws4_request"}, "/")
+}
+
+// s3CachedKey is an access key fetched from the scheduler
+type s3CachedKey struct {
+	key       *types.S3AccessKey
+	fetchTime time.Time
+}
+
+// parseS3Authorization parses the Authorization header, e.g.
+// AWS4-HMAC-SHA256 Credential=AKID/20130524/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-date, Signature=...
+func parseS3Authorization(header string) (*s3Authorization, error) {
+	if !strings.HasPrefix(header, s3SignAlgorithm+" ") {
+		return nil, xerrors.Errorf("unsupported authorization, only %s is supported", s3SignAlgorithm)
+	}
+
+	auth := &s3Authorization{}
+	for _, field := range strings.Split(strings.TrimPrefix(header, s3SignAlgorithm+" "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			return nil, xerrors.Errorf("invalid authorization field %s", field)
+		}
+
+		switch kv[0] {
+		case "Credential":
+			parts := strings.Split(kv[1], "/")
+			if len(parts) != 5 || parts[4] != "aws4_request" {
+				return nil, xerrors.Errorf("invalid credential %s", kv[1])
+			}
+			auth.accessKey, auth.date, auth.region, auth.service = parts[0], parts[1], parts[2], parts[3]
+		case "SignedHeaders":
+			auth.signedHeaders = strings.Split(kv[1], ";")
+		case "Signature":
+			auth.signature = kv[1]
+		}
+	}
+
+	if auth.accessKey == "" || len(auth.signedHeaders) == 0 || auth.signature == "" {
+		return nil, xerrors.New("incomplete authorization")
+	}
+
+	return auth, nil
+}
+
+// authenticateS3 verifies the signature of the request with the secret of the access key fetched from the scheduler
+func (hs *HttpServer) authenticateS3(r *http.Request) (*types.S3AccessKey, *s3Error) {
+	auth, err := parseS3Authorization(r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, &s3Error{status: http.StatusForbidden, Code: "AccessDenied", Message: err.Error()}
+	}
+
+	if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), s3StreamingPrefix) {
+		return nil, &s3Error{status: http.StatusNotImplemented, Code: "NotImplemented", Message: "streaming payload is not supported"}
+	}
+
+	t, err := time.Parse(s3AmzDateFormat, s3RequestDate(r))
+	if err != nil {
+		return nil, &s3Error{status: http.StatusForbidden, Code: "AccessDenied", Message: "invalid x-amz-date"}
+	}
+
+	if d := time.Since(t); d > s3MaxClockSkew || d < -s3MaxClockSkew {
+		return nil, &s3Error{status: http.StatusForbidden, Code: "RequestTimeTooSkewed", Message: "the difference between the request time and the current time is too large"}
+	}
+
+	key, err := hs.s3AccessKey(r, auth.accessKey)
+	if err != nil {
+		return nil, &s3Error{status: http.StatusInternalServerError, Code: "InternalError", Message: err.Error()}
+	}
+
+	if key == nil {
+		return nil, &s3Error{status: http.StatusForbidden, Code: "InvalidAccessKeyId", Message: "the access key does not exist"}
+	}
+
+	if !hmac.Equal([]byte(auth.signature), []byte(s3Signature(r, auth, key.SecretKey))) {
+		return nil, &s3Error{status: http.StatusForbidden, Code: "SignatureDoesNotMatch", Message: "the request signature does not match"}
+	}
+
+	return key, nil
+}
+
+// s3AccessKey returns the cached access key, the key is fetched from the scheduler if it is not cached or the cache is expired
+func (hs *HttpServer) s3AccessKey(r *http.Request, accessKey string) (*types.S3AccessKey, error) {
+	hs.s3KeysLock.Lock()
+	cached, ok := hs.s3Keys[accessKey]
+	hs.s3KeysLock.Unlock()
+
+	if ok && time.Since(cached.fetchTime) < s3AccessKeyCacheTime {
+		return cached.key, nil
+	}
+
+	key, err := hs.scheduler.GetS3AccessKey(r.Context(), accessKey)
+	if err != nil || key == nil {
+		return nil, err
+	}
+
+	hs.s3KeysLock.Lock()
+	for k, v := range hs.s3Keys {
+		if time.Since(v.fetchTime) >= s3AccessKeyCacheTime {
+			delete(hs.s3Keys, k)
+		}
+	}
+	hs.s3Keys[accessKey] = &s3CachedKey{key: key, fetchTime: time.Now()}
+	hs.s3KeysLock.Unlock()
+
+	return key, nil
+}
+
+// s3RequestDate returns the signing time of the request
+func s3RequestDate(r *http.Request) string {
+	if date := r.Header.Get("X-Amz-Date"); date != "" {
+		return date
+	}
+
+	return r.Header.Get("Date")
+}
+
+// s3Signature calculates the signature version 4 of the request
+func s3Signature(r *http.Request, auth *s3Authorization, secret string) string {
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		s3URIEncode(r.URL.Path, false),
+		s3CanonicalQuery(r.URL.Query()),
+		s3CanonicalHeaders(r, auth.signedHeaders),
+		strings.Join(auth.signedHeaders, ";"),
+		s3PayloadHash(r),
+	}, "\n")
+
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3SignAlgorithm, s3RequestDate(r), auth.scope(), hex.EncodeToString(hash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secret), auth.date)
+	key = hmacSHA256(key, auth.region)
+	key = hmacSHA256(key, auth.service)
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// s3PayloadHash returns the payload hash that the client signed
+func s3PayloadHash(r *http.Request) string {
+	if hash := r.Header.Get("X-Amz-Content-Sha256"); hash != "" {
+		return hash
+	}
+
+	return s3UnsignedPayload
+}
+
+// s3CanonicalQuery sorts the query parameters by the name and the value
+func s3CanonicalQuery(query url.Values) string {
+	params := make([]string, 0, len(query))
+	for k, values := range query {
+		for _, v := range values {
+			params = append(params, s3URIEncode(k, true)+"="+s3URIEncode(v, true))
+		}
+	}
+	sort.Strings(params)
+
+	return strings.Join(params, "&")
+}
+
+// s3CanonicalHeaders returns the lowercase signed headers with the trimmed values, each ends with a newline
+func s3CanonicalHeaders(r *http.Request, signedHeaders []string) string {
+	var b strings.Builder
+	for _, name := range signedHeaders {
+		var value string
+		switch name {
+		case "host":
+			value = r.Host
+		case "content-length":
+			value = r.Header.Get("Content-Length")
+			if value == "" && r.ContentLength >= 0 {
+				value = strconv.FormatInt(r.ContentLength, 10)
+			}
+		default:
+			values := r.Header.Values(name)
+			for i, v := range values {
+				values[i] = strings.Join(strings.Fields(v), " ")
+			}
+			value = strings.Join(values, ",")
+		}
+
+		b.WriteString(name + ":" + value + "\n")
+	}
+
+	return b.String()
+}
+
+// s3URIEncode encodes the string as the UriEncode of the signature version 4, the slash is kept if encodeSlash is false
+func s3URIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data)) //nolint:errcheck
+	return h.Sum(nil)
+}
@@ -0,0 +1,299 @@
+package httpserver
+
+import (
+	"crypto/md5" //nolint:gosec // md5 is the etag of the s3 objects
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// s3MultipartDir is the directory in the temp dir that the parts of the multipart uploads are saved in
+	s3MultipartDir = "titan-s3-multipart"
+	// s3MultipartExpiration is the time that an unfinished multipart upload is kept after its last part is uploaded
+	s3MultipartExpiration = 7 * 24 * time.Hour
+	// s3MaxPartNumber is the maximum part number of a multipart upload
+	s3MaxPartNumber = 10000
+	// s3MaxCompleteBodySize is the maximum size of the complete multipart upload request body
+	s3MaxCompleteBodySize = 1 << 20
+
+	s3UploadMetaFile = "upload.json"
+)
+
+// s3MultipartUpload is the meta of a multipart upload, it is saved with the parts
+type s3MultipartUpload struct {
+	Tenant      string
+	Bucket      string
+	Key         string
+	ContentType string
+}
+
+type s3InitiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string
+	Key      string
+	UploadID string `xml:"UploadId"`
+}
+
+type s3CompleteMultipartUpload struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []struct {
+		PartNumber int
+		ETag       string
+	} `xml:"Part"`
+}
+
+type s3CompleteMultipartUploadResult struct {
+	XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Bucket  string
+	Key     string
+	ETag    string
+}
+
+// createMultipartUpload saves the meta of a new multipart upload, the unfinished uploads that expired are removed
+func (hs *HttpServer) createMultipartUpload(w http.ResponseWriter, r *http.Request, req *s3Request) {
+	removeExpiredS3Uploads()
+
+	uploadID := uuid.NewString()
+	upload := &s3MultipartUpload{
+		Tenant:      req.key.Tenant,
+		Bucket:      req.bucket,
+		Key:         req.object,
+		ContentType: r.Header.Get("Content-Type"),
+	}
+
+	data, err := json.Marshal(upload)
+	if err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+
+	dir := s3UploadDir(uploadID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, s3UploadMetaFile), data, 0o644); err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+
+	writeS3XML(w, &s3InitiateMultipartUploadResult{Xmlns: s3XMLNamespace, Bucket: req.bucket, Key: req.object, UploadID: uploadID})
+}
+
+// uploadPart saves the body as a part of the multipart upload
+func (hs *HttpServer) uploadPart(w http.ResponseWriter, r *http.Request, req *s3Request) {
+	dir, serr := loadS3Upload(r.URL.Query().Get("uploadId"), req)
+	if serr != nil {
+		writeS3Error(w, r, serr)
+		return
+	}
+
+	partNumber, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || partNumber < 1 || partNumber > s3MaxPartNumber {
+		writeS3Error(w, r, &s3Error{status: http.StatusBadRequest, Code: "InvalidArgument", Message: fmt.Sprintf("part number must be between 1 and %d", s3MaxPartNumber)})
+		return
+	}
+
+	file, err := os.CreateTemp(dir, "part-*.tmp")
+	if err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+	defer os.Remove(file.Name()) //nolint:errcheck
+	defer file.Close()           //nolint:errcheck
+
+	_, sum, serr := readS3Payload(r, file, s3MaxObjectSize)
+	if serr != nil {
+		writeS3Error(w, r, serr)
+		return
+	}
+
+	if err := file.Close(); err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+
+	if err := os.Rename(file.Name(), s3PartPath(dir, partNumber)); err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", strconv.Quote(hex.EncodeToString(sum)))
+	w.WriteHeader(http.StatusOK)
+}
+
+// completeMultipartUpload joins the listed parts into the object, each part is checked against its etag
+func (hs *HttpServer) completeMultipartUpload(w http.ResponseWriter, r *http.Request, req *s3Request) {
+	uploadID := r.URL.Query().Get("uploadId")
+	dir, serr := loadS3Upload(uploadID, req)
+	if serr != nil {
+		writeS3Error(w, r, serr)
+		return
+	}
+
+	body := &strings.Builder{}
+	if _, _, serr := readS3Payload(r, body, s3MaxCompleteBodySize); serr != nil {
+		writeS3Error(w, r, serr)
+		return
+	}
+
+	complete := &s3CompleteMultipartUpload{}
+	if err := xml.Unmarshal([]byte(body.String()), complete); err != nil || len(complete.Parts) == 0 {
+		writeS3Error(w, r, &s3Error{status: http.StatusBadRequest, Code: "MalformedXML", Message: "the xml of the parts is not valid"})
+		return
+	}
+
+	file, err := os.CreateTemp(dir, "object-*.tmp")
+	if err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+	defer os.Remove(file.Name()) //nolint:errcheck
+	defer file.Close()           //nolint:errcheck
+
+	var size int64
+	sums := md5.New() //nolint:gosec
+	for i, part := range complete.Parts {
+		if i > 0 && part.PartNumber <= complete.Parts[i-1].PartNumber {
+			writeS3Error(w, r, &s3Error{status: http.StatusBadRequest, Code: "InvalidPartOrder", Message: "the parts must be in ascending order"})
+			return
+		}
+
+		n, sum, err := appendS3Part(file, dir, part.PartNumber)
+		if err != nil || hex.EncodeToString(sum) != strings.Trim(part.ETag, `"`) {
+			writeS3Error(w, r, &s3Error{status: http.StatusBadRequest, Code: "InvalidPart", Message: fmt.Sprintf("part %d is not found or the etag does not match", part.PartNumber)})
+			return
+		}
+
+		size += n
+		sums.Write(sum) //nolint:errcheck
+	}
+
+	upload, err := readS3UploadMeta(dir)
+	if err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+	r.Header.Set("Content-Type", upload.ContentType)
+
+	etag := fmt.Sprintf("%s-%d", hex.EncodeToString(sums.Sum(nil)), len(complete.Parts))
+	if serr := hs.storeS3Object(r, req, file, size, etag); serr != nil {
+		writeS3Error(w, r, serr)
+		return
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		log.Errorf("remove multipart upload %s error:%s", uploadID, err.Error())
+	}
+
+	writeS3XML(w, &s3CompleteMultipartUploadResult{Xmlns: s3XMLNamespace, Bucket: req.bucket, Key: req.object, ETag: strconv.Quote(etag)})
+}
+
+// abortMultipartUpload removes the parts of the multipart upload
+func (hs *HttpServer) abortMultipartUpload(w http.ResponseWriter, r *http.Request, req *s3Request) {
+	dir, serr := loadS3Upload(r.URL.Query().Get("uploadId"), req)
+	if serr != nil {
+		writeS3Error(w, r, serr)
+		return
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		writeS3InternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadS3Upload returns the directory of the multipart upload, the upload must belong to the object of the tenant
+func loadS3Upload(uploadID string, req *s3Request) (string, *s3Error) {
+	notFound := &s3Error{status: http.StatusNotFound, Code: "NoSuchUpload", Message: "the specified multipart upload does not exist"}
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return "", notFound
+	}
+
+	dir := s3UploadDir(uploadID)
+	upload, err := readS3UploadMeta(dir)
+	if err != nil {
+		return "", notFound
+	}
+
+	if upload.Tenant != req.key.Tenant || upload.Bucket != req.bucket || upload.Key != req.object {
+		return "", notFound
+	}
+
+	return dir, nil
+}
+
+// readS3UploadMeta reads the meta of the multipart upload in the directory
+func readS3UploadMeta(dir string) (*s3MultipartUpload, error) {
+	data, err := os.ReadFile(filepath.Join(dir, s3UploadMetaFile))
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &s3MultipartUpload{}
+	if err := json.Unmarshal(data, upload); err != nil {
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// appendS3Part appends the part to the writer and returns the size and the md5 of the part
+func appendS3Part(w io.Writer, dir string, partNumber int) (int64, []byte, error) {
+	part, err := os.Open(s3PartPath(dir, partNumber))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer part.Close() //nolint:errcheck
+
+	sum := md5.New() //nolint:gosec
+	n, err := io.Copy(io.MultiWriter(w, sum), part)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return n, sum.Sum(nil), nil
+}
+
+// removeExpiredS3Uploads removes the unfinished multipart uploads that expired, the directory of an upload is modified when a part is uploaded
+func removeExpiredS3Uploads() {
+	entries, err := os.ReadDir(filepath.Join(os.TempDir(), s3MultipartDir))
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < s3MultipartExpiration {
+			continue
+		}
+
+		if err := os.RemoveAll(s3UploadDir(entry.Name())); err != nil {
+			log.Errorf("remove expired multipart upload %s error:%s", entry.Name(), err.Error())
+		}
+	}
+}
+
+func s3UploadDir(uploadID string) string {
+	return filepath.Join(os.TempDir(), s3MultipartDir, uploadID)
+}
+
+func s3PartPath(dir string, partNumber int) string {
+	return filepath.Join(dir, fmt.Sprintf("part-%05d", partNumber))
+}
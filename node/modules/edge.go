@@ -1,6 +1,9 @@
 package modules
 
 import (
+	"time"
+
+	"github.com/linguohua/titan/api"
 	"github.com/linguohua/titan/node/asset"
 	"github.com/linguohua/titan/node/asset/fetcher"
 	"github.com/linguohua/titan/node/asset/storage"
@@ -29,10 +32,10 @@ func NewNodeStorageManager(path dtypes.CarfileStorePath) (*storage.Manager, erro
 	return storage.NewManager(string(path), nil)
 }
 
-// NewAssetsManager creates a function that generates new instances of asset.Manager.
-func NewAssetsManager(fetchBatch int) func(storageMgr *storage.Manager, bFetcher fetcher.BlockFetcher) (*asset.Manager, error) {
-	return func(storageMgr *storage.Manager, bFetcher fetcher.BlockFetcher) (*asset.Manager, error) {
-		opts := &asset.ManagerOptions{Storage: storageMgr, BFetcher: bFetcher, PullParallel: fetchBatch}
+// NewAssetsManager creates a function that generates new instances of asset.Manager, the unfinished resumable uploads are kept for the upload ttl.
+func NewAssetsManager(fetchBatch int, uploadTTL time.Duration) func(storageMgr *storage.Manager, bFetcher fetcher.BlockFetcher, scheduler api.Scheduler) (*asset.Manager, error) {
+	return func(storageMgr *storage.Manager, bFetcher fetcher.BlockFetcher, scheduler api.Scheduler) (*asset.Manager, error) {
+		opts := &asset.ManagerOptions{Storage: storageMgr, BFetcher: bFetcher, PullParallel: fetchBatch, UploadTTL: uploadTTL, Scheduler: scheduler}
 		return asset.NewManager(opts)
 	}
 }
@@ -118,6 +118,7 @@ func NewSetSchedulerConfigFunc(r repo.LockedRepo) func(config.SchedulerCfg) erro
 			scfg.SchedulerServer1 = cfg.SchedulerServer1
 			scfg.SchedulerServer2 = cfg.SchedulerServer2
 			scfg.EnableValidation = cfg.EnableValidation
+			scfg.EnableRebalance = cfg.EnableRebalance
 		})
 	}
 }
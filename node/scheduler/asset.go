@@ -2,14 +2,21 @@ package scheduler
 
 import (
 	"context"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/linguohua/titan/api/types"
+	"github.com/linguohua/titan/lib/erasure"
 	"github.com/linguohua/titan/node/cidutil"
 	"github.com/linguohua/titan/node/handler"
+	"github.com/linguohua/titan/node/scheduler/assets"
 	"golang.org/x/xerrors"
 )
 
+// maxPullAssetsBatch is the maximum number of assets in a batch pull
+const maxPullAssetsBatch = 1000
+
 // NodeRemoveAssetResult updates a node's disk usage and block count based on the resultInfo.
 func (s *Scheduler) NodeRemoveAssetResult(ctx context.Context, resultInfo types.RemoveAssetResult) error {
 	nodeID := handler.GetNodeID(ctx)
@@ -24,6 +31,27 @@ func (s *Scheduler) NodeRemoveAssetResult(ctx context.Context, resultInfo types.
 	return nil
 }
 
+// NodeUploadResult creates the asset pull task of the asset uploaded to the candidate.
+func (s *Scheduler) NodeUploadResult(ctx context.Context, result *types.UploadResult) error {
+	nodeID := handler.GetNodeID(ctx)
+
+	return s.AssetManager.UploadResult(nodeID, result)
+}
+
+// NodeURLPullResult creates the asset pull task of the asset that the candidate built from its origin url.
+func (s *Scheduler) NodeURLPullResult(ctx context.Context, result *types.URLPullResult) error {
+	nodeID := handler.GetNodeID(ctx)
+
+	return s.AssetManager.URLPullResult(nodeID, result)
+}
+
+// NodeShardEncodeResult saves the shards that the candidate encoded the erasure coded asset into.
+func (s *Scheduler) NodeShardEncodeResult(ctx context.Context, result *types.ShardEncodeResult) error {
+	nodeID := handler.GetNodeID(ctx)
+
+	return s.AssetManager.ShardEncodeResult(nodeID, result)
+}
+
 // RePullFailedAssets retries the pull process for a list of failed assets
 func (s *Scheduler) RePullFailedAssets(ctx context.Context, hashes []types.AssetHash) error {
 	return s.AssetManager.RestartPullAssets(hashes)
@@ -73,13 +101,20 @@ func (s *Scheduler) GetAssetRecords(ctx context.Context, limit, offset int, stat
 			continue
 		}
 
+		if cInfo.State == assets.Queued.String() {
+			cInfo.QueuePosition, err = s.NodeManager.LoadAssetQueuePosition(cInfo.Hash, cInfo.State)
+			if err != nil {
+				log.Errorf("asset %s load queue position err: %s", cInfo.CID, err.Error())
+			}
+		}
+
 		list = append(list, cInfo)
 	}
 
 	return list, nil
 }
 
-// RemoveAssetRecord removes an asset record from the system by its CID.
+// RemoveAssetRecord removes an asset record from the system by its CID, the asset is kept in the pending delete state during the grace period.
 func (s *Scheduler) RemoveAssetRecord(ctx context.Context, cid string) error {
 	if cid == "" {
 		return xerrors.Errorf("Cid Is Nil")
@@ -90,7 +125,49 @@ func (s *Scheduler) RemoveAssetRecord(ctx context.Context, cid string) error {
 		return err
 	}
 
-	return s.AssetManager.RemoveAsset(cid, hash)
+	return s.AssetManager.RemoveAssetGracefully(cid, hash)
+}
+
+// RestoreAsset restores a pending delete asset by its CID, the expiration is kept if it is zero.
+func (s *Scheduler) RestoreAsset(ctx context.Context, cid string, expiration time.Time) error {
+	if cid == "" {
+		return xerrors.Errorf("Cid Is Nil")
+	}
+
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return err
+	}
+
+	return s.AssetManager.RestoreAsset(cid, hash, expiration)
+}
+
+// PauseAsset freezes the pulling of an asset by its CID.
+func (s *Scheduler) PauseAsset(ctx context.Context, cid string) error {
+	if cid == "" {
+		return xerrors.Errorf("Cid Is Nil")
+	}
+
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return err
+	}
+
+	return s.AssetManager.PauseAsset(cid, hash)
+}
+
+// ResumeAsset resumes the pulling of a paused asset by its CID.
+func (s *Scheduler) ResumeAsset(ctx context.Context, cid string) error {
+	if cid == "" {
+		return xerrors.Errorf("Cid Is Nil")
+	}
+
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return err
+	}
+
+	return s.AssetManager.ResumeAsset(cid, hash)
 }
 
 // RemoveAssetReplica removes an asset replica from the system by its CID and nodeID.
@@ -109,18 +186,79 @@ func (s *Scheduler) RemoveAssetReplica(ctx context.Context, cid, nodeID string)
 
 // PullAsset pull an asset based on the provided PullAssetReq structure.
 func (s *Scheduler) PullAsset(ctx context.Context, info *types.PullAssetReq) error {
+	_, err := s.pullAsset(ctx, info)
+	return err
+}
+
+// pullAsset pulls the asset, the id of the url pull is returned if the asset is pulled from its origin url
+func (s *Scheduler) pullAsset(ctx context.Context, info *types.PullAssetReq) (string, error) {
+	err := checkPullAssetReq(info)
+	if err != nil {
+		return "", err
+	}
+
 	if info.CID == "" {
+		return s.AssetManager.CreateURLAssetPullTask(ctx, info)
+	}
+
+	return "", s.AssetManager.CreateAssetPullTask(info)
+}
+
+// GetURLPullStatus retrieves the status of the url pull.
+func (s *Scheduler) GetURLPullStatus(ctx context.Context, id string) (*types.URLPullStatus, error) {
+	return s.AssetManager.GetURLPullStatus(id)
+}
+
+// checkPullAssetReq validates the pull request and sets the hash of the asset, the hash is set later if the asset is pulled from the url.
+func checkPullAssetReq(info *types.PullAssetReq) error {
+	if info == nil {
+		return xerrors.New("pull asset request is nil")
+	}
+
+	if info.CID == "" && info.URL == "" {
 		return xerrors.New("Cid is Nil")
 	}
 
-	hash, err := cidutil.CIDToHash(info.CID)
-	if err != nil {
-		return xerrors.Errorf("%s cid to hash err:%s", info.CID, err.Error())
+	if info.CID != "" && info.URL != "" {
+		return xerrors.New("cid and url can not be set at the same time")
+	}
+
+	if info.URL != "" {
+		u, err := url.Parse(info.URL)
+		if err != nil {
+			return xerrors.Errorf("parse url %s err:%s", info.URL, err.Error())
+		}
+
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return xerrors.Errorf("unsupported url scheme %s", u.Scheme)
+		}
+	} else {
+		hash, err := cidutil.CIDToHash(info.CID)
+		if err != nil {
+			return xerrors.Errorf("%s cid to hash err:%s", info.CID, err.Error())
+		}
+
+		info.Hash = hash
 	}
 
-	info.Hash = hash
+	return checkAssetOptions(info)
+}
 
-	if info.Replicas < 1 {
+// checkAssetOptions validates the replica and placement options of the pull request.
+func checkAssetOptions(info *types.PullAssetReq) error {
+	if ec := info.ErasureCoding; ec != nil {
+		if ec.DataShards < 1 || ec.ParityShards < 1 {
+			return xerrors.Errorf("data shards %d and parity shards %d must greater than 1", ec.DataShards, ec.ParityShards)
+		}
+
+		if ec.DataShards+ec.ParityShards > erasure.MaxShards {
+			return xerrors.Errorf("the total number of shards %d exceeds the limit %d", ec.DataShards+ec.ParityShards, erasure.MaxShards)
+		}
+
+		if info.MaxReplicas > 0 {
+			return xerrors.New("replica scaling is not supported by an erasure coded asset")
+		}
+	} else if info.Replicas < 1 {
 		return xerrors.Errorf("replicas %d must greater than 1", info.Replicas)
 	}
 
@@ -128,7 +266,208 @@ func (s *Scheduler) PullAsset(ctx context.Context, info *types.PullAssetReq) err
 		return xerrors.Errorf("expiration %s less than now(%v)", info.Expiration.String(), time.Now())
 	}
 
-	return s.AssetManager.CreateAssetPullTask(info)
+	if info.MaxReplicas > 0 && (info.MinReplicas > info.Replicas || info.Replicas > info.MaxReplicas) {
+		return xerrors.Errorf("replicas %d must be between min replicas %d and max replicas %d", info.Replicas, info.MinReplicas, info.MaxReplicas)
+	}
+
+	if info.Labels != nil {
+		for _, label := range append(info.Labels.Include, info.Labels.Exclude...) {
+			key, value, _ := types.ParseLabel(label)
+			if err := checkNodeLabel(key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// CreateUploadTicket returns the candidate and the ticket to upload an asset of the max size to.
+func (s *Scheduler) CreateUploadTicket(ctx context.Context, maxSize int64, info *types.PullAssetReq) (*types.UploadInfo, error) {
+	if maxSize <= 0 {
+		return nil, xerrors.Errorf("max size %d must greater than 0", maxSize)
+	}
+
+	req := *info
+	req.CID, req.Hash, req.URL = "", "", ""
+
+	if err := checkAssetOptions(&req); err != nil {
+		return nil, err
+	}
+
+	return s.AssetManager.CreateUploadTicket(&req, maxSize)
+}
+
+// PlanAssetPlacement selects the replica nodes of a batch of assets without pulling them,
+// the nodes are selected for each asset independently.
+func (s *Scheduler) PlanAssetPlacement(ctx context.Context, infos []*types.PullAssetReq) (*types.AssetPlacementPlan, error) {
+	if len(infos) > maxPullAssetsBatch {
+		return nil, xerrors.Errorf("the number of assets %d exceeds the limit %d", len(infos), maxPullAssetsBatch)
+	}
+
+	plan := &types.AssetPlacementPlan{AreaReplicas: make(map[string]int)}
+	for _, info := range infos {
+		err := checkPullAssetReq(info)
+		if err != nil {
+			placement := &types.AssetPlacement{Err: err.Error()}
+			if info != nil {
+				placement.CID = info.CID
+			}
+
+			plan.Assets = append(plan.Assets, placement)
+			continue
+		}
+
+		placement, err := s.AssetManager.PlanAssetPlacement(info)
+		if err != nil {
+			plan.Assets = append(plan.Assets, &types.AssetPlacement{CID: info.CID, Err: err.Error()})
+			continue
+		}
+
+		if placement.Size > 0 {
+			edgeSize := placement.Size * int64(len(placement.EdgeNodes))
+			if info.ErasureCoding != nil && info.ErasureCoding.DataShards > 0 {
+				// an edge holds a shard of the erasure coded asset
+				edgeSize /= info.ErasureCoding.DataShards
+			}
+
+			plan.EstimatedSize += placement.Size*int64(len(placement.CandidateNodes)) + edgeSize
+		} else {
+			plan.UnknownSizeAssets++
+		}
+
+		for area, count := range placement.AreaReplicas {
+			plan.AreaReplicas[area] += count
+		}
+
+		plan.Assets = append(plan.Assets, placement)
+	}
+
+	return plan, nil
+}
+
+// PullAssets pulls a batch of assets, an asset that fails does not abort the others.
+func (s *Scheduler) PullAssets(ctx context.Context, infos []*types.PullAssetReq) ([]*types.PullAssetResult, error) {
+	if len(infos) > maxPullAssetsBatch {
+		return nil, xerrors.Errorf("the number of assets %d exceeds the limit %d", len(infos), maxPullAssetsBatch)
+	}
+
+	results := make([]*types.PullAssetResult, 0, len(infos))
+	for _, info := range infos {
+		result := &types.PullAssetResult{}
+		if info != nil {
+			result.CID = info.CID
+			result.URL = info.URL
+		}
+
+		id, err := s.pullAsset(ctx, info)
+		if err != nil {
+			log.Errorf("PullAssets %s%s err:%s", result.CID, result.URL, err.Error())
+			result.Err = err.Error()
+		}
+		result.PullID = id
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// GetAssetGroup retrieves the asset records of a group.
+func (s *Scheduler) GetAssetGroup(ctx context.Context, groupID string) (*types.AssetGroup, error) {
+	if groupID == "" {
+		return nil, xerrors.New("group id is nil")
+	}
+
+	records, err := s.NodeManager.LoadAssetRecordsOfGroup(groupID, s.ServerID)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &types.AssetGroup{GroupID: groupID, Assets: records}
+	for _, record := range records {
+		group.TotalSize += record.TotalSize
+	}
+
+	return group, nil
+}
+
+// UpdateAssetGroupExpiration resets the expiration time of all assets in a group.
+func (s *Scheduler) UpdateAssetGroupExpiration(ctx context.Context, groupID string, t time.Time) error {
+	if groupID == "" {
+		return xerrors.New("group id is nil")
+	}
+
+	if time.Now().After(t) {
+		return xerrors.Errorf("expiration:%s has passed", t.String())
+	}
+
+	return s.AssetManager.UpdateAssetGroupExpiration(groupID, t)
+}
+
+// RemoveAssetGroup removes all asset records of a group, an asset that fails does not abort the others.
+func (s *Scheduler) RemoveAssetGroup(ctx context.Context, groupID string) error {
+	if groupID == "" {
+		return xerrors.New("group id is nil")
+	}
+
+	records, err := s.NodeManager.LoadAssetRecordsOfGroup(groupID, s.ServerID)
+	if err != nil {
+		return err
+	}
+
+	failed := make([]string, 0)
+	for _, record := range records {
+		if err := s.AssetManager.RemoveAssetGracefully(record.CID, record.Hash); err != nil {
+			log.Errorf("RemoveAssetGroup %s remove asset %s err:%s", groupID, record.CID, err.Error())
+			failed = append(failed, record.CID)
+		}
+	}
+
+	if len(failed) > 0 {
+		return xerrors.Errorf("remove %d of %d assets failed: %s", len(failed), len(records), strings.Join(failed, ","))
+	}
+
+	return nil
+}
+
+// GetReplicaScalingRecords lists the edge replica scaling decisions of an asset.
+func (s *Scheduler) GetReplicaScalingRecords(ctx context.Context, cid string, limit, offset int) ([]*types.ReplicaScalingRecord, error) {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return nil, xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	return s.NodeManager.LoadReplicaScalingRecords(hash, limit, offset)
+}
+
+// GetAssetEvents lists the state machine events of an asset.
+func (s *Scheduler) GetAssetEvents(ctx context.Context, cid string, limit, offset int) ([]*types.AssetEvent, error) {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return nil, xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	return s.NodeManager.LoadAssetEvents(hash, limit, offset)
+}
+
+// GetReplicaMoveRecords lists the replica moves of the rebalancer from or to a node, all moves if the node id is empty.
+func (s *Scheduler) GetReplicaMoveRecords(ctx context.Context, nodeID string, limit, offset int) ([]*types.ReplicaMoveRecord, error) {
+	return s.NodeManager.LoadReplicaMoveRecords(nodeID, limit, offset)
+}
+
+// SetReplicaRebalance enables or disables moving replicas off the nodes under disk pressure.
+func (s *Scheduler) SetReplicaRebalance(ctx context.Context, enable bool) error {
+	cfg, err := s.GetSchedulerConfigFunc()
+	if err != nil {
+		return xerrors.Errorf("get schedulerConfig err:%s", err.Error())
+	}
+
+	cfg.EnableRebalance = enable
+
+	log.Infof("replica rebalance enabled: %v", enable)
+
+	return s.SetSchedulerConfigFunc(cfg)
 }
 
 // GetAssetReplicaInfos lists asset replicas based on a given request with startTime, endTime, cursor, and count parameters.
@@ -0,0 +1,125 @@
+package assets
+
+import (
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// deleteGracePeriod returns the grace period of the removed assets from the scheduler config
+func (m *Manager) deleteGracePeriod() time.Duration {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get schedulerConfig err:%s", err.Error())
+		return 0
+	}
+
+	if cfg.AssetDeleteGracePeriod <= 0 {
+		return 0
+	}
+
+	return time.Duration(cfg.AssetDeleteGracePeriod) * time.Hour
+}
+
+// RemoveAssetGracefully moves an asset to the pending delete state, the succeeded replicas are kept on the nodes
+// until the grace period ends. The asset is removed at once if the grace period is not set or it is already pending delete
+func (m *Manager) RemoveAssetGracefully(cid, hash string) error {
+	if m.deleteGracePeriod() == 0 {
+		return m.RemoveAsset(cid, hash)
+	}
+
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		return xerrors.Errorf("RemoveAssetGracefully %s LoadAssetRecord err:%s", cid, err.Error())
+	}
+
+	if record.State == PendingDelete.String() {
+		return m.RemoveAsset(cid, hash)
+	}
+
+	replicaInfos, err := m.LoadAssetReplicas(hash)
+	if err != nil {
+		return xerrors.Errorf("RemoveAssetGracefully %s LoadAssetReplicas err:%s", cid, err.Error())
+	}
+
+	// release the pulling slot, the unfinished replicas are dropped
+	m.removeTickerForAsset(hash)
+
+	for _, r := range replicaInfos {
+		if r.Status == types.ReplicaStatusSucceeded {
+			continue
+		}
+
+		if err := m.RemoveReplica(cid, hash, r.NodeID); err != nil {
+			log.Errorf("RemoveAssetGracefully %s RemoveReplica %s err:%s", cid, r.NodeID, err.Error())
+		}
+	}
+
+	record.ReplicaInfos = replicaInfos
+
+	err = m.assetStateMachines.Send(AssetHash(hash), AssetPendingDelete{Record: record})
+	if err != nil {
+		return xerrors.Errorf("send to state machine err: %s ", err.Error())
+	}
+
+	log.Infof("asset event %s , pending delete", cid)
+
+	return m.UpdateAssetDeleteTime(hash)
+}
+
+// RestoreAsset restores a pending delete asset, the lost replicas are pulled again.
+// The expiration is kept if it is zero, otherwise the asset expires at the given time
+func (m *Manager) RestoreAsset(cid, hash string, expiration time.Time) error {
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		return xerrors.Errorf("RestoreAsset %s LoadAssetRecord err:%s", cid, err.Error())
+	}
+
+	if record.State != PendingDelete.String() {
+		return xerrors.Errorf("asset %s state is %s, not %s", cid, record.State, PendingDelete.String())
+	}
+
+	if expiration.IsZero() {
+		expiration = record.Expiration
+	}
+
+	if expiration.Before(time.Now()) {
+		return xerrors.Errorf("asset %s expiration %s is before now", cid, expiration.String())
+	}
+
+	if !expiration.Equal(record.Expiration) {
+		err = m.UpdateAssetRecordExpiry(hash, expiration)
+		if err != nil {
+			return err
+		}
+
+		m.updateEarliestExpiration(expiration)
+		record.Expiration = expiration
+	}
+
+	record.ReplicaInfos, err = m.LoadAssetReplicas(hash)
+	if err != nil {
+		return xerrors.Errorf("RestoreAsset %s LoadAssetReplicas err:%s", cid, err.Error())
+	}
+
+	log.Infof("asset event %s , restore asset", cid)
+
+	return m.assetStateMachines.Send(AssetHash(hash), AssetRestore{Record: record})
+}
+
+// purgePendingDeleteAssets removes the pending delete assets whose grace period has ended
+func (m *Manager) purgePendingDeleteAssets() {
+	before := time.Now().Add(-m.deleteGracePeriod())
+
+	records, err := m.LoadDeletableAssetRecords(PendingDelete.String(), m.nodeMgr.ServerID, before, loadPendingDeleteAssetsLimit)
+	if err != nil {
+		log.Errorf("LoadDeletableAssetRecords err:%s", err.Error())
+		return
+	}
+
+	for _, record := range records {
+		err = m.RemoveAsset(record.CID, record.Hash)
+		log.Infof("the asset cid(%s) grace period has ended, being removed, err: %v", record.CID, err)
+	}
+}
@@ -0,0 +1,120 @@
+package assets
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/filecoin-project/go-statemachine"
+	"github.com/linguohua/titan/api/types"
+)
+
+// infoUpdateEvent is the name of the progress update event, the consecutive progress updates are coalesced into one event
+var infoUpdateEvent = reflect.TypeOf(InfoUpdate{}).Name()
+
+// recordAssetEvents saves the events processed by the planner, the state is the asset state after the events are applied
+func (m *Manager) recordAssetEvents(events []statemachine.Event, from AssetState, state *AssetPullingInfo, planErr error) {
+	if state.Hash == "" || len(events) == 0 {
+		return
+	}
+
+	hash := state.Hash.String()
+	if state.State == Remove {
+		// the events of the asset are deleted with its record
+		m.eventLock.Lock()
+		delete(m.lastEvents, hash)
+		m.eventLock.Unlock()
+		return
+	}
+
+	infos := make([]*types.AssetEvent, 0, len(events))
+	for _, evt := range events {
+		info := &types.AssetEvent{
+			Hash:        hash,
+			CID:         state.CID,
+			Event:       reflect.TypeOf(evt.User).Name(),
+			FromState:   from.String(),
+			ToState:     state.State.String(),
+			Msg:         eventMessage(evt.User),
+			CreatedTime: time.Now(),
+		}
+
+		if info.Event == infoUpdateEvent && len(infos) > 0 && infos[len(infos)-1].Event == infoUpdateEvent {
+			infos[len(infos)-1] = info
+			continue
+		}
+
+		infos = append(infos, info)
+	}
+
+	if planErr != nil {
+		// the last processed event is rejected by the planner
+		infos[len(infos)-1].Msg = planErr.Error()
+	}
+
+	m.eventLock.Lock()
+	defer m.eventLock.Unlock()
+
+	if infos[0].Event == infoUpdateEvent && m.lastEvents[hash] == infoUpdateEvent {
+		// the progress update replaces the one recorded last
+		if err := m.UpdateLatestAssetEvent(infos[0]); err != nil {
+			log.Errorf("UpdateLatestAssetEvent %s err:%s", state.CID, err.Error())
+		}
+		infos = infos[1:]
+	}
+
+	if len(infos) == 0 {
+		return
+	}
+
+	if err := m.SaveAssetEvents(infos); err != nil {
+		log.Errorf("SaveAssetEvents %s err:%s", state.CID, err.Error())
+	}
+
+	m.lastEvents[hash] = infos[len(infos)-1].Event
+}
+
+// pruneAssetEvents deletes the asset events older than the retention
+func (m *Manager) pruneAssetEvents() {
+	count, err := m.DeleteAssetEventsBefore(time.Now().Add(-assetEventRetention))
+	if err != nil {
+		log.Errorf("DeleteAssetEventsBefore err:%s", err.Error())
+		return
+	}
+
+	log.Debugf("pruned %d asset events", count)
+}
+
+// eventMessage returns the details of the event
+func eventMessage(evt interface{}) string {
+	switch e := evt.(type) {
+	case PullFailed:
+		return errorMessage(e.error)
+	case SelectFailed:
+		return errorMessage(e.error)
+	case PullAssetFatalError:
+		return errorMessage(e.error)
+	case PulledResult:
+		if e.ResultInfo == nil {
+			return ""
+		}
+		return fmt.Sprintf("node %s %s", e.ResultInfo.NodeID, types.ReplicaStatus(e.ResultInfo.Status).String())
+	case InfoUpdate:
+		return fmt.Sprintf("blocks %d, size %d", e.Blocks, e.Size)
+	case ReplicasReplaced:
+		return fmt.Sprintf("%d replicas replaced", e.Count)
+	case AssetForceState:
+		return fmt.Sprintf("force state %s", e.State)
+	}
+
+	return ""
+}
+
+// errorMessage returns the message of the error event, the error may be nil
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
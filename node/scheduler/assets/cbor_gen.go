@@ -26,7 +26,7 @@ func (t *AssetPullingInfo) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{175}); err != nil {
+	if _, err := cw.Write([]byte{184, 31}); err != nil {
 		return err
 	}
 
@@ -98,6 +98,38 @@ func (t *AssetPullingInfo) MarshalCBOR(w io.Writer) error {
 		}
 	}
 
+	// t.Areas ([]string) (slice)
+	if len("Areas") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Areas\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Areas"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Areas")); err != nil {
+		return err
+	}
+
+	if len(t.Areas) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Areas was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.Areas))); err != nil {
+		return err
+	}
+	for _, v := range t.Areas {
+		if len(v) > cbg.MaxLength {
+			return xerrors.Errorf("Value in field v was too long")
+		}
+
+		if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(v))); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, string(v)); err != nil {
+			return err
+		}
+	}
+
 	// t.State (assets.AssetState) (string)
 	if len("State") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"State\" was too long")
@@ -143,6 +175,51 @@ func (t *AssetPullingInfo) MarshalCBOR(w io.Writer) error {
 		}
 	}
 
+	// t.GroupID (string) (string)
+	if len("GroupID") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"GroupID\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("GroupID"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("GroupID")); err != nil {
+		return err
+	}
+
+	if len(t.GroupID) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.GroupID was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.GroupID))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.GroupID)); err != nil {
+		return err
+	}
+
+	// t.Priority (int64) (int64)
+	if len("Priority") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Priority\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Priority"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Priority")); err != nil {
+		return err
+	}
+
+	if t.Priority >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Priority)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.Priority-1)); err != nil {
+			return err
+		}
+	}
+
 	// t.ServerID (string) (string)
 	if len("ServerID") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"ServerID\" was too long")
@@ -188,6 +265,28 @@ func (t *AssetPullingInfo) MarshalCBOR(w io.Writer) error {
 		}
 	}
 
+	// t.DataShards (int64) (int64)
+	if len("DataShards") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"DataShards\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataShards"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("DataShards")); err != nil {
+		return err
+	}
+
+	if t.DataShards >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.DataShards)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.DataShards-1)); err != nil {
+			return err
+		}
+	}
+
 	// t.Expiration (int64) (int64)
 	if len("Expiration") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"Expiration\" was too long")
@@ -210,6 +309,51 @@ func (t *AssetPullingInfo) MarshalCBOR(w io.Writer) error {
 		}
 	}
 
+	// t.MaxRetries (int64) (int64)
+	if len("MaxRetries") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"MaxRetries\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("MaxRetries"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("MaxRetries")); err != nil {
+		return err
+	}
+
+	if t.MaxRetries >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.MaxRetries)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.MaxRetries-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.PausedFrom (assets.AssetState) (string)
+	if len("PausedFrom") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"PausedFrom\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("PausedFrom"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("PausedFrom")); err != nil {
+		return err
+	}
+
+	if len(t.PausedFrom) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.PausedFrom was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.PausedFrom))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.PausedFrom)); err != nil {
+		return err
+	}
+
 	// t.RetryCount (int64) (int64)
 	if len("RetryCount") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"RetryCount\" was too long")
@@ -232,6 +376,51 @@ func (t *AssetPullingInfo) MarshalCBOR(w io.Writer) error {
 		}
 	}
 
+	// t.SeedNodeID (string) (string)
+	if len("SeedNodeID") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"SeedNodeID\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("SeedNodeID"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("SeedNodeID")); err != nil {
+		return err
+	}
+
+	if len(t.SeedNodeID) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.SeedNodeID was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.SeedNodeID))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.SeedNodeID)); err != nil {
+		return err
+	}
+
+	// t.PullTimeout (int64) (int64)
+	if len("PullTimeout") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"PullTimeout\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("PullTimeout"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("PullTimeout")); err != nil {
+		return err
+	}
+
+	if t.PullTimeout >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.PullTimeout)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.PullTimeout-1)); err != nil {
+			return err
+		}
+	}
+
 	// t.EdgeReplicas (int64) (int64)
 	if len("EdgeReplicas") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"EdgeReplicas\" was too long")
@@ -254,6 +443,202 @@ func (t *AssetPullingInfo) MarshalCBOR(w io.Writer) error {
 		}
 	}
 
+	// t.ParityShards (int64) (int64)
+	if len("ParityShards") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ParityShards\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ParityShards"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ParityShards")); err != nil {
+		return err
+	}
+
+	if t.ParityShards >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.ParityShards)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.ParityShards-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.ExcludeLabels ([]string) (slice)
+	if len("ExcludeLabels") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ExcludeLabels\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ExcludeLabels"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ExcludeLabels")); err != nil {
+		return err
+	}
+
+	if len(t.ExcludeLabels) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.ExcludeLabels was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.ExcludeLabels))); err != nil {
+		return err
+	}
+	for _, v := range t.ExcludeLabels {
+		if len(v) > cbg.MaxLength {
+			return xerrors.Errorf("Value in field v was too long")
+		}
+
+		if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(v))); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, string(v)); err != nil {
+			return err
+		}
+	}
+
+	// t.IncludeLabels ([]string) (slice)
+	if len("IncludeLabels") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"IncludeLabels\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("IncludeLabels"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("IncludeLabels")); err != nil {
+		return err
+	}
+
+	if len(t.IncludeLabels) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.IncludeLabels was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.IncludeLabels))); err != nil {
+		return err
+	}
+	for _, v := range t.IncludeLabels {
+		if len(v) > cbg.MaxLength {
+			return xerrors.Errorf("Value in field v was too long")
+		}
+
+		if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(v))); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, string(v)); err != nil {
+			return err
+		}
+	}
+
+	// t.RetryInterval (int64) (int64)
+	if len("RetryInterval") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RetryInterval\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RetryInterval"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("RetryInterval")); err != nil {
+		return err
+	}
+
+	if t.RetryInterval >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.RetryInterval)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.RetryInterval-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.SelectAttempts (int64) (int64)
+	if len("SelectAttempts") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"SelectAttempts\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("SelectAttempts"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("SelectAttempts")); err != nil {
+		return err
+	}
+
+	if t.SelectAttempts >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.SelectAttempts)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.SelectAttempts-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.MaxEdgeReplicas (int64) (int64)
+	if len("MaxEdgeReplicas") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"MaxEdgeReplicas\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("MaxEdgeReplicas"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("MaxEdgeReplicas")); err != nil {
+		return err
+	}
+
+	if t.MaxEdgeReplicas >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.MaxEdgeReplicas)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.MaxEdgeReplicas-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.MinEdgeReplicas (int64) (int64)
+	if len("MinEdgeReplicas") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"MinEdgeReplicas\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("MinEdgeReplicas"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("MinEdgeReplicas")); err != nil {
+		return err
+	}
+
+	if t.MinEdgeReplicas >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.MinEdgeReplicas)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.MinEdgeReplicas-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.ReplacedReplicas (int64) (int64)
+	if len("ReplacedReplicas") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ReplacedReplicas\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ReplacedReplicas"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ReplacedReplicas")); err != nil {
+		return err
+	}
+
+	if t.ReplacedReplicas >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.ReplacedReplicas)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.ReplacedReplicas-1)); err != nil {
+			return err
+		}
+	}
+
 	// t.CandidateReplicas (int64) (int64)
 	if len("CandidateReplicas") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"CandidateReplicas\" was too long")
@@ -492,12 +877,44 @@ func (t *AssetPullingInfo) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.Size = int64(extraI)
 			}
-			// t.State (assets.AssetState) (string)
-		case "State":
+			// t.Areas ([]string) (slice)
+		case "Areas":
 
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.Areas: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.Areas = make([]string, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				{
+					sval, err := cbg.ReadString(cr)
+					if err != nil {
+						return err
+					}
+
+					t.Areas[i] = string(sval)
+				}
+			}
+
+			// t.State (assets.AssetState) (string)
+		case "State":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
 					return err
 				}
 
@@ -529,6 +946,43 @@ func (t *AssetPullingInfo) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.Blocks = int64(extraI)
 			}
+			// t.GroupID (string) (string)
+		case "GroupID":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.GroupID = string(sval)
+			}
+			// t.Priority (int64) (int64)
+		case "Priority":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.Priority = int64(extraI)
+			}
 			// t.ServerID (string) (string)
 		case "ServerID":
 
@@ -566,6 +1020,32 @@ func (t *AssetPullingInfo) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.CreatedAt = int64(extraI)
 			}
+			// t.DataShards (int64) (int64)
+		case "DataShards":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.DataShards = int64(extraI)
+			}
 			// t.Expiration (int64) (int64)
 		case "Expiration":
 			{
@@ -592,6 +1072,43 @@ func (t *AssetPullingInfo) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.Expiration = int64(extraI)
 			}
+			// t.MaxRetries (int64) (int64)
+		case "MaxRetries":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.MaxRetries = int64(extraI)
+			}
+			// t.PausedFrom (assets.AssetState) (string)
+		case "PausedFrom":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.PausedFrom = AssetState(sval)
+			}
 			// t.RetryCount (int64) (int64)
 		case "RetryCount":
 			{
@@ -618,6 +1135,43 @@ func (t *AssetPullingInfo) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.RetryCount = int64(extraI)
 			}
+			// t.SeedNodeID (string) (string)
+		case "SeedNodeID":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.SeedNodeID = string(sval)
+			}
+			// t.PullTimeout (int64) (int64)
+		case "PullTimeout":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.PullTimeout = int64(extraI)
+			}
 			// t.EdgeReplicas (int64) (int64)
 		case "EdgeReplicas":
 			{
@@ -644,6 +1198,226 @@ func (t *AssetPullingInfo) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.EdgeReplicas = int64(extraI)
 			}
+			// t.ParityShards (int64) (int64)
+		case "ParityShards":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.ParityShards = int64(extraI)
+			}
+			// t.ExcludeLabels ([]string) (slice)
+		case "ExcludeLabels":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.ExcludeLabels: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.ExcludeLabels = make([]string, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				{
+					sval, err := cbg.ReadString(cr)
+					if err != nil {
+						return err
+					}
+
+					t.ExcludeLabels[i] = string(sval)
+				}
+			}
+
+			// t.IncludeLabels ([]string) (slice)
+		case "IncludeLabels":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.IncludeLabels: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.IncludeLabels = make([]string, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				{
+					sval, err := cbg.ReadString(cr)
+					if err != nil {
+						return err
+					}
+
+					t.IncludeLabels[i] = string(sval)
+				}
+			}
+
+			// t.RetryInterval (int64) (int64)
+		case "RetryInterval":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.RetryInterval = int64(extraI)
+			}
+			// t.SelectAttempts (int64) (int64)
+		case "SelectAttempts":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.SelectAttempts = int64(extraI)
+			}
+			// t.MaxEdgeReplicas (int64) (int64)
+		case "MaxEdgeReplicas":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.MaxEdgeReplicas = int64(extraI)
+			}
+			// t.MinEdgeReplicas (int64) (int64)
+		case "MinEdgeReplicas":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.MinEdgeReplicas = int64(extraI)
+			}
+			// t.ReplacedReplicas (int64) (int64)
+		case "ReplacedReplicas":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.ReplacedReplicas = int64(extraI)
+			}
 			// t.CandidateReplicas (int64) (int64)
 		case "CandidateReplicas":
 			{
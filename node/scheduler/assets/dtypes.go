@@ -1,6 +1,7 @@
 package assets
 
 import (
+	"strings"
 	"time"
 
 	"github.com/linguohua/titan/api/types"
@@ -41,6 +42,50 @@ type AssetPullingInfo struct {
 	CandidateReplicaFailures []string
 
 	RetryCount int64
+
+	ReplacedReplicas int64 // number of failed replicas replaced by other nodes in the pulling phase
+
+	Areas []string // preferred areas of the replicas
+
+	// bounds of the edge replicas scaled by download demand, scaling is disabled if MaxEdgeReplicas is 0
+	MinEdgeReplicas int64
+	MaxEdgeReplicas int64
+
+	Priority int64  // priority in the pull queue, higher is first
+	GroupID  string // the asset group of the batch pull
+
+	// label selector of the replica nodes
+	IncludeLabels []string
+	ExcludeLabels []string
+
+	PausedFrom AssetState // the state to return to when the paused asset is resumed
+
+	// effective pull policy, fixed when the asset is created
+	PullTimeout    int64 // seconds without pull progress before the node pulls are failed
+	RetryInterval  int64 // seconds to wait before the failed pulls are retried
+	MaxRetries     int64 // maximum number of retries of the failed pulls
+	SelectAttempts int64 // attempts per replica to select a random node
+
+	SeedNodeID string // the candidate that already holds the asset, e.g. the candidate that built it from the origin url
+
+	// Reed-Solomon coding of the erasure coded asset, the edges hold one shard each instead of full replicas
+	DataShards   int64
+	ParityShards int64
+}
+
+// isErasureCoded checks if the edges hold the shards of the asset instead of full replicas
+func (state *AssetPullingInfo) isErasureCoded() bool {
+	return state.DataShards > 0
+}
+
+// placementRequest returns the placement request of the asset, the filter nodes will not be selected
+func (state *AssetPullingInfo) placementRequest(filterNodes []string) *PlacementRequest {
+	return &PlacementRequest{
+		Areas:       state.Areas,
+		FilterNodes: filterNodes,
+		Labels:      labelSelector(state.IncludeLabels, state.ExcludeLabels),
+		Attempts:    int(state.SelectAttempts),
+	}
 }
 
 // ToAssetRecord converts AssetPullingInfo to types.AssetRecord
@@ -54,6 +99,20 @@ func (state *AssetPullingInfo) ToAssetRecord() *types.AssetRecord {
 		State:                 state.State.String(),
 		NeedCandidateReplicas: state.CandidateReplicas,
 		Expiration:            time.Unix(state.Expiration, 0),
+		Areas:                 strings.Join(state.Areas, areasSeparator),
+		MinEdgeReplicas:       state.MinEdgeReplicas,
+		MaxEdgeReplicas:       state.MaxEdgeReplicas,
+		Priority:              state.Priority,
+		GroupID:               state.GroupID,
+		IncludeLabels:         strings.Join(state.IncludeLabels, labelsSeparator),
+		ExcludeLabels:         strings.Join(state.ExcludeLabels, labelsSeparator),
+		PausedFrom:            state.PausedFrom.String(),
+		PullTimeout:           state.PullTimeout,
+		RetryInterval:         state.RetryInterval,
+		MaxRetries:            state.MaxRetries,
+		SelectAttempts:        state.SelectAttempts,
+		DataShards:            state.DataShards,
+		ParityShards:          state.ParityShards,
 	}
 }
 
@@ -68,6 +127,20 @@ func assetPullingInfoFrom(info *types.AssetRecord) *AssetPullingInfo {
 		Blocks:            info.TotalBlocks,
 		CandidateReplicas: info.NeedCandidateReplicas,
 		Expiration:        info.Expiration.Unix(),
+		Areas:             splitAreas(info.Areas),
+		MinEdgeReplicas:   info.MinEdgeReplicas,
+		MaxEdgeReplicas:   info.MaxEdgeReplicas,
+		Priority:          info.Priority,
+		GroupID:           info.GroupID,
+		IncludeLabels:     splitLabels(info.IncludeLabels),
+		ExcludeLabels:     splitLabels(info.ExcludeLabels),
+		PausedFrom:        AssetState(info.PausedFrom),
+		PullTimeout:       info.PullTimeout,
+		RetryInterval:     info.RetryInterval,
+		MaxRetries:        info.MaxRetries,
+		SelectAttempts:    info.SelectAttempts,
+		DataShards:        info.DataShards,
+		ParityShards:      info.ParityShards,
 	}
 
 	for _, r := range info.ReplicaInfos {
@@ -84,3 +157,50 @@ func assetPullingInfoFrom(info *types.AssetRecord) *AssetPullingInfo {
 
 	return cInfo
 }
+
+// areasSeparator separates the areas in the asset record
+const areasSeparator = ","
+
+// splitAreas splits the areas of the asset record
+func splitAreas(areas string) []string {
+	if areas == "" {
+		return nil
+	}
+
+	return strings.Split(areas, areasSeparator)
+}
+
+// labelsSeparator separates the labels in the asset record
+const labelsSeparator = ","
+
+// splitLabels splits the labels of the asset record
+func splitLabels(labels string) []string {
+	if labels == "" {
+		return nil
+	}
+
+	return strings.Split(labels, labelsSeparator)
+}
+
+// labelSelector returns the label selector of the include and exclude labels, nil if there is no label
+func labelSelector(include, exclude []string) *types.LabelSelector {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+
+	return &types.LabelSelector{Include: include, Exclude: exclude}
+}
+
+// recordLabelSelector returns the label selector of the asset record
+func recordLabelSelector(record *types.AssetRecord) *types.LabelSelector {
+	return labelSelector(splitLabels(record.IncludeLabels), splitLabels(record.ExcludeLabels))
+}
+
+// selectorLabels returns the include and exclude labels of the label selector
+func selectorLabels(selector *types.LabelSelector) (include, exclude []string) {
+	if selector == nil {
+		return nil, nil
+	}
+
+	return selector.Include, selector.Exclude
+}
@@ -0,0 +1,567 @@
+package assets
+
+import (
+	"crypto"
+	"time"
+
+	"github.com/filecoin-project/go-statemachine"
+	"github.com/linguohua/titan/api/types"
+	"github.com/linguohua/titan/node/cidutil"
+	titanrsa "github.com/linguohua/titan/node/rsa"
+	"github.com/linguohua/titan/node/scheduler/node"
+	"golang.org/x/xerrors"
+)
+
+// shardsEncodeTimeout is the minimum time to wait for a candidate to encode an asset into shards,
+// the candidate reports no progress while encoding
+const shardsEncodeTimeout = 30 * time.Minute
+
+// shardReplicas represents the nodes holding a shard of an erasure coded asset
+type shardReplicas struct {
+	shard      *types.AssetShard
+	candidates []string // the candidates holding the shard
+	edges      []string // the edges holding the shard, the draining edges are not included
+	pulling    int      // number of edges pulling the shard
+}
+
+// placed checks if the shard is held or being pulled by an edge
+func (r *shardReplicas) placed() bool {
+	return len(r.edges) > 0 || r.pulling > 0
+}
+
+// encodeTimeout returns the time to wait for the candidate to encode the asset into shards
+func (state *AssetPullingInfo) encodeTimeout() time.Duration {
+	if timeout := state.pullTimeout(); timeout > shardsEncodeTimeout {
+		return timeout
+	}
+
+	return shardsEncodeTimeout
+}
+
+// loadShardReplicas loads the nodes holding each of the shards, the filter nodes are all nodes that have a replica
+// of any shard, so that every edge holds one shard of the asset at most
+func (m *Manager) loadShardReplicas(hash string, shards []*types.AssetShard) ([]*shardReplicas, []string, error) {
+	replicaInfos, err := m.LoadShardReplicas(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := make([]*shardReplicas, 0, len(shards))
+	shardMap := make(map[string]*shardReplicas, len(shards))
+	for _, shard := range shards {
+		r := &shardReplicas{shard: shard}
+		out = append(out, r)
+		shardMap[shard.ShardHash] = r
+	}
+
+	filterNodes := make([]string, 0, len(replicaInfos))
+	for _, info := range replicaInfos {
+		filterNodes = append(filterNodes, info.NodeID)
+
+		r, ok := shardMap[info.Hash]
+		if !ok {
+			continue
+		}
+
+		switch info.Status {
+		case types.ReplicaStatusSucceeded:
+			if info.IsCandidate {
+				r.candidates = append(r.candidates, info.NodeID)
+				continue
+			}
+
+			if n := m.nodeMgr.GetNode(info.NodeID); n != nil && n.Maintenance == types.NodeMaintenanceDraining {
+				continue
+			}
+
+			r.edges = append(r.edges, info.NodeID)
+		case types.ReplicaStatusPulling, types.ReplicaStatusWaiting:
+			if !info.IsCandidate {
+				r.pulling++
+			}
+		}
+	}
+
+	return out, filterNodes, nil
+}
+
+// missingShards returns the shards that are not held or being pulled by any edge, and the number of edges pulling a shard
+func missingShards(replicas []*shardReplicas) ([]*shardReplicas, int) {
+	var missing []*shardReplicas
+	pulling := 0
+	for _, r := range replicas {
+		pulling += r.pulling
+		if !r.placed() {
+			missing = append(missing, r)
+		}
+	}
+
+	return missing, pulling
+}
+
+// shardSources gets the download sources of a shard, all online holders are used because every shard is held by few nodes
+func (m *Manager) shardSources(cid string, candidates, edges []string) []*types.CandidateDownloadInfo {
+	titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
+	sources := make([]*types.CandidateDownloadInfo, 0)
+	for _, nodeID := range candidates {
+		cNode := m.nodeMgr.GetCandidateNode(nodeID)
+		if cNode == nil {
+			continue
+		}
+
+		credentials, err := cNode.Credentials(cid, false, titanRsa, m.nodeMgr.PrivateKey)
+		if err != nil {
+			continue
+		}
+
+		sources = append(sources, &types.CandidateDownloadInfo{
+			URL:         cNode.DownloadAddr(),
+			Credentials: credentials,
+		})
+	}
+
+	for _, nodeID := range edges {
+		eNode := m.nodeMgr.GetEdgeNode(nodeID)
+		if eNode == nil || !isEdgeSourceNAT(eNode) {
+			continue
+		}
+
+		credentials, err := eNode.Credentials(cid, false, titanRsa, m.nodeMgr.PrivateKey)
+		if err != nil {
+			continue
+		}
+
+		sources = append(sources, &types.CandidateDownloadInfo{
+			URL:         eNode.DownloadAddr(),
+			Credentials: credentials,
+		})
+	}
+
+	return sources
+}
+
+// pullShards places the missing shards on the edges that hold no shard of the asset, one shard per edge,
+// the shards without online sources are skipped. Returns the number of shards placed
+func (m *Manager) pullShards(ctx statemachine.Context, info AssetPullingInfo, missing []*shardReplicas, filterNodes []string) int {
+	type shardPull struct {
+		shard   *types.AssetShard
+		sources []*types.CandidateDownloadInfo
+	}
+
+	pulls := make([]*shardPull, 0, len(missing))
+	for _, r := range missing {
+		sources := m.shardSources(r.shard.CID, r.candidates, r.edges)
+		if len(sources) == 0 {
+			log.Warnf("asset %s shard %d has no source", info.CID, r.shard.Index)
+			continue
+		}
+
+		pulls = append(pulls, &shardPull{shard: r.shard, sources: sources})
+	}
+
+	if len(pulls) == 0 {
+		return 0
+	}
+
+	nodes := m.chooseEdgeNodesForAssetReplica(len(pulls), info.placementRequest(filterNodes))
+	if len(nodes) == 0 {
+		return 0
+	}
+
+	assigned := make(map[*node.Node]*shardPull, len(nodes))
+	replicaInfos := make([]*types.ReplicaInfo, 0, len(nodes))
+	for _, n := range nodes {
+		pull := pulls[len(assigned)]
+		assigned[n] = pull
+
+		replicaInfos = append(replicaInfos, &types.ReplicaInfo{
+			NodeID: n.NodeID,
+			Status: types.ReplicaStatusWaiting,
+			Hash:   pull.shard.ShardHash,
+		})
+	}
+
+	if err := m.BatchSaveReplicas(replicaInfos); err != nil {
+		log.Errorf("pullShards %s BatchSaveReplicas err:%s", info.CID, err.Error())
+		return 0
+	}
+
+	// send a pull request to the node
+	go func() {
+		for n, pull := range assigned {
+			err := n.PullAsset(ctx.Context(), pull.shard.CID, pull.sources)
+			if err != nil {
+				log.Errorf("%s pull shard %s err:%s", n.NodeID, pull.shard.CID, err.Error())
+				continue
+			}
+
+			n.IncrCurPullingCount(1)
+		}
+	}()
+
+	return len(assigned)
+}
+
+// selectShardEdges handles the edges select of an erasure coded asset, a candidate is requested to encode
+// the asset if it has no shards yet, otherwise the shards that no edge holds are placed on the edges
+func (m *Manager) selectShardEdges(ctx statemachine.Context, info AssetPullingInfo) error {
+	hash := info.Hash.String()
+
+	shards, err := m.LoadAssetShards(hash)
+	if err != nil {
+		return ctx.Send(SelectFailed{error: err})
+	}
+
+	if len(shards) == 0 {
+		return m.requestShardsEncode(ctx, info)
+	}
+
+	replicas, filterNodes, err := m.loadShardReplicas(hash, shards)
+	if err != nil {
+		return ctx.Send(SelectFailed{error: err})
+	}
+
+	missing, pulling := missingShards(replicas)
+	if len(missing) == 0 {
+		if pulling == 0 {
+			// every shard is held by an edge
+			return ctx.Send(SkipStep{})
+		}
+
+		return ctx.Send(PullRequestSent{})
+	}
+
+	if m.pullShards(ctx, info, missing, filterNodes) == 0 {
+		if pulling == 0 {
+			return ctx.Send(SelectFailed{error: xerrors.New("node not found")})
+		}
+	}
+
+	return ctx.Send(PullRequestSent{})
+}
+
+// requestShardsEncode requests a candidate holding the asset to encode it into shards
+func (m *Manager) requestShardsEncode(ctx statemachine.Context, info AssetPullingInfo) error {
+	var cNode *node.Node
+	for _, nodeID := range info.CandidateReplicaSucceeds {
+		if cNode = m.nodeMgr.GetCandidateNode(nodeID); cNode != nil {
+			break
+		}
+	}
+
+	if cNode == nil {
+		return ctx.Send(SelectFailed{error: xerrors.New("candidate node not found")})
+	}
+
+	// the slot is taken by the edges select, the ticker waits for the encoding
+	m.addOrResetAssetTicker(info.Hash.String(), info.encodeTimeout())
+
+	req := &types.ShardEncodeReq{
+		CID:          info.CID,
+		DataShards:   info.DataShards,
+		ParityShards: info.ParityShards,
+	}
+	if err := cNode.EncodeAssetShards(ctx.Context(), req); err != nil {
+		return ctx.Send(SelectFailed{error: xerrors.Errorf("%s encode asset shards err:%s", cNode.NodeID, err.Error())})
+	}
+
+	log.Infof("asset event %s, encode into %d+%d shards on %s", info.CID, info.DataShards, info.ParityShards, cNode.NodeID)
+
+	return ctx.Send(ShardsEncodeRequestSent{})
+}
+
+// handleShardsEncoding waits for the candidate to report the shards of the asset
+func (m *Manager) handleShardsEncoding(ctx statemachine.Context, info AssetPullingInfo) error {
+	log.Debugf("handle shards encoding, %s", info.CID)
+
+	shards, err := m.LoadAssetShards(info.Hash.String())
+	if err != nil {
+		log.Errorf("handleShardsEncoding %s LoadAssetShards err:%s", info.CID, err.Error())
+		return nil
+	}
+
+	if len(shards) > 0 {
+		return ctx.Send(ShardsEncoded{})
+	}
+
+	// the ticker may be reset to the pull timeout after a restart
+	if !m.addOrResetAssetTicker(info.Hash.String(), info.encodeTimeout()) {
+		return ctx.Send(PullAssetRequeue{})
+	}
+
+	return nil
+}
+
+// replaceFailedShards places the shards whose edges failed to pull them on other edges,
+// the phase fails only if no shards are pulling and no eligible edges remain
+func (m *Manager) replaceFailedShards(ctx statemachine.Context, info AssetPullingInfo) error {
+	hash := info.Hash.String()
+
+	shards, err := m.LoadAssetShards(hash)
+	if err != nil {
+		log.Errorf("replaceFailedShards %s LoadAssetShards err:%s", info.CID, err.Error())
+		return nil
+	}
+
+	replicas, filterNodes, err := m.loadShardReplicas(hash, shards)
+	if err != nil {
+		log.Errorf("replaceFailedShards %s loadShardReplicas err:%s", info.CID, err.Error())
+		return nil
+	}
+
+	missing, pulling := missingShards(replicas)
+	if len(missing) == 0 && pulling == 0 {
+		return ctx.Send(PullSucceed{})
+	}
+
+	if len(info.EdgeReplicaFailures) == 0 || len(missing) == 0 {
+		return nil
+	}
+
+	maxReplacements := m.maxReplicaReplacements()
+	budget := maxReplacements - info.ReplacedReplicas
+	if int64(len(missing)) > budget {
+		if budget < 0 {
+			budget = 0
+		}
+		missing = missing[:budget]
+	}
+
+	if len(missing) > 0 && !m.addOrResetAssetTicker(hash, info.pullTimeout()) {
+		return ctx.Send(PullAssetRequeue{})
+	}
+
+	placed := 0
+	if len(missing) > 0 {
+		placed = m.pullShards(ctx, info, missing, filterNodes)
+	}
+
+	if placed == 0 {
+		if pulling > 0 {
+			// wait for the shards being pulled
+			return nil
+		}
+
+		if len(missing) == 0 {
+			return ctx.Send(PullFailed{error: xerrors.Errorf("%d shards failed, %d replacements reached the limit", len(info.EdgeReplicaFailures), info.ReplacedReplicas)})
+		}
+
+		return ctx.Send(PullFailed{error: xerrors.Errorf("%d shards failed, no node to replace them", len(info.EdgeReplicaFailures))})
+	}
+
+	log.Infof("asset event %s, replace %d failed shards, replaced: %d/%d", info.CID, placed, info.ReplacedReplicas+int64(placed), maxReplacements)
+
+	return ctx.Send(ReplicasReplaced{Count: int64(placed)})
+}
+
+// ShardEncodeResult saves the shards that the candidate encoded the asset into, the candidate holds all shards
+// and serves them as the source of the edges
+func (m *Manager) ShardEncodeResult(nodeID string, result *types.ShardEncodeResult) error {
+	hash, err := cidutil.CIDToHash(result.CID)
+	if err != nil {
+		return xerrors.Errorf("%s cid to hash err:%s", result.CID, err.Error())
+	}
+
+	if exist, _ := m.assetStateMachines.Has(AssetHash(hash)); !exist {
+		return xerrors.Errorf("asset %s not found", result.CID)
+	}
+
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		return xerrors.Errorf("ShardEncodeResult %s LoadAssetRecord err:%s", result.CID, err.Error())
+	}
+
+	if result.Err != "" {
+		log.Errorf("asset %s node %s encode shards err:%s", result.CID, nodeID, result.Err)
+
+		if record.State != ShardsEncoding.String() {
+			return nil
+		}
+
+		return m.assetStateMachines.Send(AssetHash(hash), PullFailed{error: xerrors.New(result.Err)})
+	}
+
+	if err := m.checkShardEncodeResult(nodeID, record, result); err != nil {
+		return err
+	}
+
+	existing, err := m.LoadAssetShards(hash)
+	if err != nil {
+		return xerrors.Errorf("ShardEncodeResult %s LoadAssetShards err:%s", result.CID, err.Error())
+	}
+
+	if len(existing) > 0 {
+		// the asset is encoded again after a timeout, the shards that are not saved are dropped
+		saved := make(map[string]struct{}, len(existing))
+		for _, shard := range existing {
+			saved[shard.CID] = struct{}{}
+		}
+
+		for _, shard := range result.Shards {
+			if _, ok := saved[shard.CID]; !ok {
+				go m.requestAssetDeletion(nodeID, shard.CID)
+			}
+		}
+
+		return m.assetStateMachines.Send(AssetHash(hash), ShardsEncoded{})
+	}
+
+	replicaInfos := make([]*types.ReplicaInfo, 0, len(result.Shards))
+	for _, shard := range result.Shards {
+		shard.Hash = hash
+		shard.ShardHash, err = cidutil.CIDToHash(shard.CID)
+		if err != nil {
+			return xerrors.Errorf("%s cid to hash err:%s", shard.CID, err.Error())
+		}
+
+		replicaInfos = append(replicaInfos, &types.ReplicaInfo{
+			NodeID:      nodeID,
+			Status:      types.ReplicaStatusSucceeded,
+			Hash:        shard.ShardHash,
+			IsCandidate: true,
+		})
+	}
+
+	if err := m.SaveAssetShards(result.Shards); err != nil {
+		return xerrors.Errorf("ShardEncodeResult %s SaveAssetShards err:%s", result.CID, err.Error())
+	}
+
+	if err := m.BatchSaveReplicas(replicaInfos); err != nil {
+		return xerrors.Errorf("ShardEncodeResult %s BatchSaveReplicas err:%s", result.CID, err.Error())
+	}
+
+	for _, shard := range result.Shards {
+		if err := m.addAssetToView(nodeID, shard.CID); err != nil {
+			log.Errorf("ShardEncodeResult %s addAssetToView err:%s", nodeID, err.Error())
+		}
+	}
+
+	log.Infof("asset event %s, encoded into %d shards on %s", result.CID, len(result.Shards), nodeID)
+
+	return m.assetStateMachines.Send(AssetHash(hash), ShardsEncoded{})
+}
+
+// checkShardEncodeResult checks that the shards match the coding of the asset and the node holds the asset
+func (m *Manager) checkShardEncodeResult(nodeID string, record *types.AssetRecord, result *types.ShardEncodeResult) error {
+	if record.DataShards == 0 {
+		return xerrors.Errorf("asset %s is not erasure coded", result.CID)
+	}
+
+	if total := record.DataShards + record.ParityShards; int64(len(result.Shards)) != total {
+		return xerrors.Errorf("asset %s expects %d shards, got %d", result.CID, total, len(result.Shards))
+	}
+
+	for i, shard := range result.Shards {
+		if shard.Index != int64(i) {
+			return xerrors.Errorf("asset %s shard %d has index %d", result.CID, i, shard.Index)
+		}
+	}
+
+	replicaInfos, err := m.LoadAssetReplicas(record.Hash)
+	if err != nil {
+		return xerrors.Errorf("ShardEncodeResult %s LoadAssetReplicas err:%s", result.CID, err.Error())
+	}
+
+	for _, r := range replicaInfos {
+		if r.NodeID == nodeID && r.IsCandidate && r.Status == types.ReplicaStatusSucceeded {
+			return nil
+		}
+	}
+
+	return xerrors.Errorf("node %s does not hold asset %s", nodeID, result.CID)
+}
+
+// shardReconstructReq returns the request to rebuild the erasure coded asset from the shards held by the nodes,
+// nil if the asset is not erasure coded or fewer than data shards can be downloaded
+func (m *Manager) shardReconstructReq(info AssetPullingInfo) *types.ShardReconstructReq {
+	if !info.isErasureCoded() {
+		return nil
+	}
+
+	shards, err := m.LoadAssetShards(info.Hash.String())
+	if err != nil || len(shards) == 0 {
+		return nil
+	}
+
+	replicas, _, err := m.loadShardReplicas(info.Hash.String(), shards)
+	if err != nil {
+		log.Errorf("shardReconstructReq %s loadShardReplicas err:%s", info.CID, err.Error())
+		return nil
+	}
+
+	req := &types.ShardReconstructReq{
+		CID:          info.CID,
+		DataShards:   info.DataShards,
+		ParityShards: info.ParityShards,
+	}
+
+	for _, r := range replicas {
+		sources := m.shardSources(r.shard.CID, r.candidates, r.edges)
+		if len(sources) == 0 {
+			continue
+		}
+
+		req.Shards = append(req.Shards, &types.ShardSource{
+			Index:   r.shard.Index,
+			CID:     r.shard.CID,
+			Sources: sources,
+		})
+	}
+
+	if int64(len(req.Shards)) < info.DataShards {
+		return nil
+	}
+
+	return req
+}
+
+// drainShardReplica removes the shard replica of the draining node if another edge holds the shard, otherwise
+// the erasure coded asset is repaired to place the shard on another edge. Returns true if the asset is repaired
+func (m *Manager) drainShardReplica(shard *types.AssetShard, nodeID string, repair bool) bool {
+	record, err := m.LoadAssetRecord(shard.Hash)
+	if err != nil {
+		log.Errorf("drain node %s LoadAssetRecord %s err:%s", nodeID, shard.Hash, err.Error())
+		return false
+	}
+
+	if record.State != Servicing.String() {
+		// wait for the pulling of the asset
+		return false
+	}
+
+	replicas, _, err := m.loadShardReplicas(record.Hash, []*types.AssetShard{shard})
+	if err != nil {
+		log.Errorf("drain node %s loadShardReplicas %s err:%s", nodeID, record.CID, err.Error())
+		return false
+	}
+
+	for _, edge := range replicas[0].edges {
+		if edge != nodeID {
+			log.Infof("asset event %s, drain node %s, remove shard %d", record.CID, nodeID, shard.Index)
+
+			if err := m.RemoveReplica(shard.CID, shard.ShardHash, nodeID); err != nil {
+				log.Errorf("drain node %s RemoveReplica %s err:%s", nodeID, shard.CID, err.Error())
+			}
+			return false
+		}
+	}
+
+	for _, candidate := range replicas[0].candidates {
+		if candidate == nodeID {
+			// the candidate keeps the shard as a source until an edge holds it
+			return false
+		}
+	}
+
+	if !repair {
+		return false
+	}
+
+	log.Infof("asset event %s, drain node %s, re-create shard %d", record.CID, nodeID, shard.Index)
+
+	if err := m.repairReplicas(record, map[string]struct{}{nodeID: {}}); err != nil {
+		log.Errorf("drain node %s repair asset %s replicas err:%s", nodeID, record.CID, err.Error())
+	}
+
+	return true
+}
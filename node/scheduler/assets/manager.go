@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto"
 	"database/sql"
+	"math"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -24,7 +26,6 @@ import (
 var log = logging.Logger("asset")
 
 const (
-	nodePullAssetTimeout         = 60 * time.Second      // Timeout for pulling assets (Unit:Second)
 	assetExpirationCheckInterval = 60 * 30 * time.Second // Interval for checking expired assets (Unit:Second)
 	seedReplicaCount             = 1                     // The number of pull replica in the first stage
 	pullProgressInterval         = 20 * time.Second      // Interval to get asset pull progress from node (Unit:Second)
@@ -32,10 +33,34 @@ const (
 	maxConcurrentPulls = 10  // Maximum number of concurrent asset pulls
 	maxAssetReplicas   = 100 // Maximum number of replicas per asset
 
-	maxRetryCount    = 3    // TODO Select
 	maxNodeDiskUsage = 95.0 // If the node disk size is greater than this value, pulling will not continue
 
 	numAssetBuckets = 128 // Number of asset buckets in assets view
+
+	replicaRepairInterval     = 5 * time.Minute // Interval for checking assets that lack replicas
+	maxReplicaRepairsPerRound = 5               // Maximum number of assets repaired in one round
+
+	pullQueueInterval = time.Minute // Interval for starting the queued assets
+
+	nodeDrainInterval      = 5 * time.Minute // Interval for moving the replicas off the draining nodes
+	maxDrainAssetsPerRound = 20              // Maximum number of replicas of a draining node checked in one round
+
+	unhealthySourceErrorRate = 0.5 // Error rate above which a download source is reported as unhealthy
+	maxEdgeSourcesPerPull    = 10  // Maximum number of edges used as the sources of a pull
+
+	replicaScalingInterval = 30 * time.Minute // Interval for scaling edge replicas by download demand
+	loadColdAssetsLimit    = 100              // Maximum number of cold assets shrunk in one round
+
+	replicaRebalanceInterval  = 10 * time.Minute // Interval for moving replicas off the nodes under disk pressure
+	replicaMoveTimeout        = time.Hour        // Timeout for the new replica of a move to be pulled
+	maxConcurrentReplicaMoves = 20               // Maximum number of replica moves in progress
+	maxRebalanceAssetsPerNode = 20               // Maximum number of replicas of a node checked in one round
+
+	loadPendingDeleteAssetsLimit = 100 // Maximum number of pending delete assets purged in one round
+
+	assetEventRetention = 30 * 24 * time.Hour // Time the state machine events of the assets are kept
+
+	removedReplicaTTL = 10 * time.Minute // Time the failed pulls of a replica removed by the scheduler are not counted for the node
 )
 
 // Manager manages asset replicas
@@ -47,12 +72,24 @@ type Manager struct {
 	lock               sync.Mutex
 	apTickers          map[string]*assetTicker       // timeout timer for asset pulling
 	config             dtypes.GetSchedulerConfigFunc // scheduler config
+	placementPolicies  map[string]PlacementPolicy    // registered replica placement policies
+	demandLock         sync.Mutex
+	demands            map[string]*assetDemand // download demand of assets in the current scaling window
+	pullQueueNotify    chan struct{}           // notifies the pull queue of a free pulling slot or a new queued asset
+	moveLock           sync.Mutex
+	moves              map[string]*types.ReplicaMoveRecord // replica moves in progress, keyed by the asset hash
+	moveChecks         map[string]struct{}                 // nodes whose replica moves are being checked
+	eventLock          sync.Mutex
+	lastEvents         map[string]string // last recorded state machine event of assets, keyed by the asset hash
+	removedLock        sync.Mutex
+	removedReplicas    map[string]time.Time // replicas removed by the scheduler, keyed by the hash and node id
 	*db.SQLDB
 }
 
 type assetTicker struct {
-	ticker *time.Ticker
-	close  chan struct{}
+	ticker  *time.Ticker
+	timeout time.Duration // time without pull progress before the node pulls are failed
+	close   chan struct{}
 }
 
 func (t *assetTicker) run(job func() error) {
@@ -77,10 +114,22 @@ func NewManager(nodeManager *node.Manager, ds datastore.Batching, configFunc dty
 		nodeMgr:            nodeManager,
 		earliestExpiration: time.Now(),
 		apTickers:          make(map[string]*assetTicker),
+		demands:            make(map[string]*assetDemand),
+		pullQueueNotify:    make(chan struct{}, 1),
+		moves:              make(map[string]*types.ReplicaMoveRecord),
+		moveChecks:         make(map[string]struct{}),
+		lastEvents:         make(map[string]string),
+		removedReplicas:    make(map[string]time.Time),
 		config:             configFunc,
 		SQLDB:              sdb,
 	}
 
+	m.placementPolicies = map[string]PlacementPolicy{
+		PlacementRandom:   &randomPlacement{nodeMgr: nodeManager},
+		PlacementLocality: newLocalityPlacement(nodeManager),
+		PlacementScore:    newScorePlacement(nodeManager),
+	}
+
 	m.stateMachineWait.Add(1)
 	m.assetStateMachines = statemachine.New(ds, m, AssetPullingInfo{})
 
@@ -94,6 +143,11 @@ func (m *Manager) Start(ctx context.Context) {
 	}
 	go m.assetExpirationCheck(ctx)
 	go m.assetPullProgressCheck(ctx)
+	go m.pullQueueCheck(ctx)
+	go m.replicaRepairCheck(ctx)
+	go m.nodeDrainCheck(ctx)
+	go m.replicaScalingCheck(ctx)
+	go m.replicaRebalanceCheck(ctx)
 }
 
 // Terminate stops the asset state machine
@@ -110,6 +164,8 @@ func (m *Manager) assetExpirationCheck(ctx context.Context) {
 		select {
 		case <-ticker.C:
 			m.processExpiredAssets()
+			m.purgePendingDeleteAssets()
+			m.pruneAssetEvents()
 		case <-ctx.Done():
 			return
 		}
@@ -152,6 +208,16 @@ func (m *Manager) retrieveNodePullProgresses() {
 			list := nodePulls[nodeID]
 			nodePulls[nodeID] = append(list, cid)
 		}
+
+		shardPulls, err := m.LoadUnfinishedShardPulls(hash)
+		if err != nil {
+			log.Errorf("retrieveNodePullProgresses %s LoadUnfinishedShardPulls err:%s", hash, err.Error())
+			continue
+		}
+
+		for nodeID, cids := range shardPulls {
+			nodePulls[nodeID] = append(nodePulls[nodeID], cids...)
+		}
 	}
 
 	getCP := func(nodeID string, cids []string) {
@@ -184,10 +250,24 @@ func (m *Manager) requestNodePullProgresses(nodeID string, cids []string) (resul
 
 // CreateAssetPullTask creates a new asset pull task
 func (m *Manager) CreateAssetPullTask(info *types.PullAssetReq) error {
+	return m.createAssetPullTask(info, "")
+}
+
+// CreateSeededAssetPullTask creates a pull task for the asset that the candidate already holds, the candidate is selected as the seed
+func (m *Manager) CreateSeededAssetPullTask(info *types.PullAssetReq, seedNodeID string) error {
+	return m.createAssetPullTask(info, seedNodeID)
+}
+
+// createAssetPullTask creates a pull task for the asset, the seed node is selected if the seed node id is empty
+func (m *Manager) createAssetPullTask(info *types.PullAssetReq, seedNodeID string) error {
 	m.stateMachineWait.Wait()
 
-	if len(m.apTickers) >= maxConcurrentPulls {
-		return xerrors.Errorf("The asset in the pulling exceeds the limit %d, please wait", maxConcurrentPulls)
+	var dataShards, parityShards int64
+	if info.ErasureCoding != nil {
+		// every edge holds one of the shards
+		dataShards, parityShards = info.ErasureCoding.DataShards, info.ErasureCoding.ParityShards
+		info.Replicas = dataShards + parityShards
+		info.MinReplicas, info.MaxReplicas = 0, 0
 	}
 
 	if info.Replicas > maxAssetReplicas {
@@ -202,6 +282,8 @@ func (m *Manager) CreateAssetPullTask(info *types.PullAssetReq) error {
 	}
 
 	if assetRecord == nil {
+		include, exclude := selectorLabels(info.Labels)
+
 		// create asset task
 		return m.assetStateMachines.Send(AssetHash(info.Hash), AssetStartPulls{
 			ID:                info.CID,
@@ -211,6 +293,17 @@ func (m *Manager) CreateAssetPullTask(info *types.PullAssetReq) error {
 			CreatedAt:         time.Now().Unix(),
 			Expiration:        info.Expiration.Unix(),
 			CandidateReplicas: m.GetCandidateReplicaCount(),
+			Areas:             info.Areas,
+			MinEdgeReplicas:   info.MinReplicas,
+			MaxEdgeReplicas:   info.MaxReplicas,
+			Priority:          info.Priority,
+			GroupID:           info.GroupID,
+			IncludeLabels:     include,
+			ExcludeLabels:     exclude,
+			Policy:            m.requestPullPolicy(info),
+			SeedNodeID:        seedNodeID,
+			DataShards:        dataShards,
+			ParityShards:      parityShards,
 		})
 	}
 
@@ -224,12 +317,33 @@ func (m *Manager) replenishAssetReplicas(assetRecord *types.AssetRecord, info *t
 		return xerrors.Errorf("asset state is %s", assetRecord.State)
 	}
 
+	rInfo, err := m.newReplenishReplicas(assetRecord, info, SeedSelect)
+	if err != nil {
+		return err
+	}
+
+	// Check if there is a need to update replicas and send the update request if needed
+	if len(rInfo.EdgeReplicaSucceeds) < int(info.Replicas) || len(rInfo.CandidateReplicaSucceeds) < m.GetCandidateReplicaCount()+seedReplicaCount {
+		return m.assetStateMachines.Send(AssetHash(info.Hash), rInfo)
+	}
+
+	return xerrors.New("Asset do not need to be replenish")
+}
+
+// newReplenishReplicas creates the event that updates the replicas of the existing asset and moves it to the state
+func (m *Manager) newReplenishReplicas(assetRecord *types.AssetRecord, info *types.PullAssetReq, state AssetState) (ReplenishReplicas, error) {
+	if assetRecord.DataShards > 0 || info.ErasureCoding != nil {
+		return ReplenishReplicas{}, xerrors.New("the replicas of an erasure coded asset can not be replenished")
+	}
+
 	// get the existing asset replicas
 	replicaInfos, err := m.LoadAssetReplicas(assetRecord.Hash)
 	if err != nil {
-		return xerrors.Errorf("asset %s load replicas err: %s", assetRecord.CID, err.Error())
+		return ReplenishReplicas{}, xerrors.Errorf("asset %s load replicas err: %s", assetRecord.CID, err.Error())
 	}
 
+	include, exclude := selectorLabels(info.Labels)
+
 	rInfo := ReplenishReplicas{
 		ID:                info.CID,
 		Hash:              AssetHash(info.Hash),
@@ -240,7 +354,13 @@ func (m *Manager) replenishAssetReplicas(assetRecord *types.AssetRecord, info *t
 		CandidateReplicas: m.GetCandidateReplicaCount(),
 		Size:              assetRecord.TotalSize,
 		Blocks:            assetRecord.TotalBlocks,
-		State:             SeedSelect,
+		State:             state,
+		Areas:             info.Areas,
+		MinEdgeReplicas:   info.MinReplicas,
+		MaxEdgeReplicas:   info.MaxReplicas,
+		IncludeLabels:     include,
+		ExcludeLabels:     exclude,
+		Policy:            m.requestPullPolicy(info),
 	}
 
 	for _, r := range replicaInfos {
@@ -255,12 +375,7 @@ func (m *Manager) replenishAssetReplicas(assetRecord *types.AssetRecord, info *t
 		}
 	}
 
-	// Check if there is a need to update replicas and send the update request if needed
-	if len(rInfo.EdgeReplicaSucceeds) < int(info.Replicas) || len(rInfo.CandidateReplicaSucceeds) < m.GetCandidateReplicaCount()+seedReplicaCount {
-		return m.assetStateMachines.Send(AssetHash(info.Hash), rInfo)
-	}
-
-	return xerrors.New("Asset do not need to be replenish")
+	return rInfo, nil
 }
 
 // RestartPullAssets restarts asset pulls
@@ -282,6 +397,7 @@ func (m *Manager) RemoveReplica(cid, hash, nodeID string) error {
 		return err
 	}
 
+	m.markReplicaRemoved(hash, nodeID)
 	go m.requestAssetDeletion(nodeID, cid)
 
 	return nil
@@ -294,6 +410,16 @@ func (m *Manager) RemoveAsset(cid, hash string) error {
 		return xerrors.Errorf("RemoveAsset %s LoadAssetReplicas err:%s", cid, err.Error())
 	}
 
+	shards, err := m.LoadAssetShards(hash)
+	if err != nil {
+		return xerrors.Errorf("RemoveAsset %s LoadAssetShards err:%s", cid, err.Error())
+	}
+
+	shardReplicas, err := m.LoadShardReplicas(hash)
+	if err != nil {
+		return xerrors.Errorf("RemoveAsset %s LoadShardReplicas err:%s", cid, err.Error())
+	}
+
 	err = m.DeleteAssetRecord(hash)
 	if err != nil {
 		return xerrors.Errorf("RemoveAsset %s DeleteAssetRecord err: %s", hash, err.Error())
@@ -307,6 +433,9 @@ func (m *Manager) RemoveAsset(cid, hash string) error {
 		}
 	}
 
+	// release the pulling slot, the nodes cancel the pulling when deleting the asset
+	m.removeTickerForAsset(hash)
+
 	log.Infof("asset event %s , remove asset", cid)
 
 	for _, cInfo := range cInfos {
@@ -319,9 +448,81 @@ func (m *Manager) RemoveAsset(cid, hash string) error {
 		go m.requestAssetDeletion(cInfo.NodeID, cid)
 	}
 
+	shardCIDs := make(map[string]string, len(shards))
+	for _, shard := range shards {
+		shardCIDs[shard.ShardHash] = shard.CID
+	}
+
+	for _, r := range shardReplicas {
+		shardCID, ok := shardCIDs[r.Hash]
+		if !ok {
+			continue
+		}
+
+		err = m.removeAssetFromView(r.NodeID, shardCID)
+		if err != nil {
+			log.Errorf("RemoveAsset %s removeAssetFromView err:%s", r.NodeID, err.Error())
+		}
+
+		go m.requestAssetDeletion(r.NodeID, shardCID)
+	}
+
 	return nil
 }
 
+// PauseAsset freezes the pulling of an asset, the pulling slot is released and no more pull requests are sent until it is resumed
+func (m *Manager) PauseAsset(cid, hash string) error {
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		return xerrors.Errorf("PauseAsset %s LoadAssetRecord err:%s", cid, err.Error())
+	}
+
+	if !isPausable(record.State) {
+		return xerrors.Errorf("asset %s in state %s can not be paused", cid, record.State)
+	}
+
+	// stop the timeout of the pulling, the replicas being pulled are checked again after resuming
+	m.removeTickerForAsset(hash)
+
+	log.Infof("asset event %s , pause asset in state %s", cid, record.State)
+
+	return m.assetStateMachines.Send(AssetHash(hash), AssetPause{})
+}
+
+// ResumeAsset returns a paused asset to the state before it was paused
+func (m *Manager) ResumeAsset(cid, hash string) error {
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		return xerrors.Errorf("ResumeAsset %s LoadAssetRecord err:%s", cid, err.Error())
+	}
+
+	if record.State != Paused.String() {
+		return xerrors.Errorf("asset %s state is %s, not %s", cid, record.State, Paused.String())
+	}
+
+	log.Infof("asset event %s , resume asset to state %s", cid, record.PausedFrom)
+
+	if isPulledState(AssetState(record.PausedFrom)) {
+		// the pull results of the nodes are checked again
+		if !m.addOrResetAssetTicker(hash, assetPullingInfoFrom(record).pullTimeout()) {
+			return xerrors.Errorf("asset %s can not be resumed, no pulling slot is free", cid)
+		}
+	}
+
+	return m.assetStateMachines.Send(AssetHash(hash), AssetResume{})
+}
+
+// isPausable checks if the asset in the state can be paused
+func isPausable(state string) bool {
+	for _, s := range PausableStates {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
+
 // updateAssetPullResults updates asset pull results
 func (m *Manager) updateAssetPullResults(nodeID string, result *types.PullResult) {
 	isCandidate := false
@@ -344,16 +545,22 @@ func (m *Manager) updateAssetPullResults(nodeID string, result *types.PullResult
 			continue
 		}
 
-		exist, _ := m.assetStateMachines.Has(AssetHash(hash))
+		// the pull result of a shard is the result of its erasure coded asset
+		assetHash := hash
+		if shard, err := m.LoadShardOfHash(hash); err == nil {
+			assetHash = shard.Hash
+		}
+
+		exist, _ := m.assetStateMachines.Has(AssetHash(assetHash))
 		if !exist {
 			continue
 		}
 
 		{
 			m.lock.Lock()
-			tickerC, ok := m.apTickers[hash]
+			tickerC, ok := m.apTickers[assetHash]
 			if ok {
-				tickerC.ticker.Reset(nodePullAssetTimeout)
+				tickerC.ticker.Reset(tickerC.timeout)
 			}
 			m.lock.Unlock()
 		}
@@ -369,6 +576,7 @@ func (m *Manager) updateAssetPullResults(nodeID string, result *types.PullResult
 			DoneSize: progress.DoneSize,
 			Hash:     hash,
 			NodeID:   nodeID,
+			FailMsg:  progress.Msg,
 		}
 
 		err = m.UpdateUnfinishedReplica(cInfo)
@@ -377,8 +585,17 @@ func (m *Manager) updateAssetPullResults(nodeID string, result *types.PullResult
 			continue
 		}
 
+		if progress.Status == types.ReplicaStatusFailed {
+			m.recordPullFailure(nodeID, hash, progress)
+		}
+
 		if progress.Status == types.ReplicaStatusPulling {
 			pullingCount++
+			m.checkDownloadSources(nodeID, progress)
+
+			if assetHash != hash {
+				continue
+			}
 
 			err = m.assetStateMachines.Send(AssetHash(hash), InfoUpdate{
 				Blocks: int64(progress.BlocksCount),
@@ -398,7 +615,7 @@ func (m *Manager) updateAssetPullResults(nodeID string, result *types.PullResult
 			continue
 		}
 
-		err = m.assetStateMachines.Send(AssetHash(hash), PulledResult{
+		err = m.assetStateMachines.Send(AssetHash(assetHash), PulledResult{
 			ResultInfo: &NodePulledResult{
 				NodeID:      nodeID,
 				Status:      int64(progress.Status),
@@ -414,8 +631,73 @@ func (m *Manager) updateAssetPullResults(nodeID string, result *types.PullResult
 	}
 }
 
-// addOrResetAssetTicker adds or resets the asset ticker with a given hash
-func (m *Manager) addOrResetAssetTicker(hash string) {
+// checkDownloadSources reports the unhealthy download sources of the node pulling
+func (m *Manager) checkDownloadSources(nodeID string, progress *types.AssetPullProgress) {
+	for _, source := range progress.Sources {
+		if source.ErrorRate >= unhealthySourceErrorRate {
+			log.Warnf("asset %s node %s pulls from unhealthy source %s, error rate: %.2f, requests: %d, failures: %d, latency: %dms",
+				progress.CID, nodeID, source.URL, source.ErrorRate, source.Requests, source.Failures, source.Latency)
+		}
+	}
+}
+
+// recordPullFailure counts the failed pull of the node for the quarantine, the pulls of the replicas removed by the scheduler are not counted,
+// and the pulls failed by the unhealthy download sources are counted as source failures
+func (m *Manager) recordPullFailure(nodeID, hash string, progress *types.AssetPullProgress) {
+	if m.isReplicaRemoved(hash, nodeID) {
+		log.Debugf("asset %s node %s pull failed after the replica was removed", progress.CID, nodeID)
+		return
+	}
+
+	kind := types.NodeFailurePull
+	if isSourceFailure(progress) {
+		kind = types.NodeFailureSource
+	}
+
+	m.nodeMgr.RecordNodeFailure(nodeID, kind)
+}
+
+// isSourceFailure checks if all the download sources of the failed pull are unhealthy
+func isSourceFailure(progress *types.AssetPullProgress) bool {
+	if len(progress.Sources) == 0 {
+		return false
+	}
+
+	for _, source := range progress.Sources {
+		if source.ErrorRate < unhealthySourceErrorRate {
+			return false
+		}
+	}
+
+	return true
+}
+
+// markReplicaRemoved records the replica removed by the scheduler, e.g. drained, moved or soft deleted, the pull it cancels is not a failure of the node
+func (m *Manager) markReplicaRemoved(hash, nodeID string) {
+	m.removedLock.Lock()
+	defer m.removedLock.Unlock()
+
+	for key, t := range m.removedReplicas {
+		if time.Since(t) > removedReplicaTTL {
+			delete(m.removedReplicas, key)
+		}
+	}
+
+	m.removedReplicas[hash+"/"+nodeID] = time.Now()
+}
+
+// isReplicaRemoved checks if the replica was removed by the scheduler recently
+func (m *Manager) isReplicaRemoved(hash, nodeID string) bool {
+	m.removedLock.Lock()
+	defer m.removedLock.Unlock()
+
+	t, ok := m.removedReplicas[hash+"/"+nodeID]
+	return ok && time.Since(t) <= removedReplicaTTL
+}
+
+// addOrResetAssetTicker adds or resets the asset ticker with a given hash and pull timeout,
+// a new ticker takes a pulling slot, it returns false if the asset has no ticker and no slot is free
+func (m *Manager) addOrResetAssetTicker(hash string, timeout time.Duration) bool {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
@@ -436,31 +718,48 @@ func (m *Manager) addOrResetAssetTicker(hash string) {
 
 	t, ok := m.apTickers[hash]
 	if ok {
-		t.ticker.Reset(nodePullAssetTimeout)
-		return
+		t.timeout = timeout
+		t.ticker.Reset(timeout)
+		return true
+	}
+
+	if len(m.apTickers) >= maxConcurrentPulls {
+		return false
 	}
 
 	m.apTickers[hash] = &assetTicker{
-		ticker: time.NewTicker(nodePullAssetTimeout),
-		close:  make(chan struct{}),
+		ticker:  time.NewTicker(timeout),
+		timeout: timeout,
+		close:   make(chan struct{}),
 	}
 
 	go m.apTickers[hash].run(fn)
+
+	return true
 }
 
-// removeTickerForAsset removes the asset ticker for a given key
+// removeTickerForAsset removes the asset ticker for a given key, the freed pulling slot is given to the pull queue
 func (m *Manager) removeTickerForAsset(key string) {
+	if m.deleteAssetTicker(key) {
+		m.notifyPullQueue()
+	}
+}
+
+// deleteAssetTicker stops and deletes the asset ticker, returns false if the ticker does not exist
+func (m *Manager) deleteAssetTicker(key string) bool {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
 	t, ok := m.apTickers[key]
 	if !ok {
-		return
+		return false
 	}
 
 	t.ticker.Stop()
 	close(t.close)
 	delete(m.apTickers, key)
+
+	return true
 }
 
 // UpdateAssetExpiration updates the asset expiration for a given CID
@@ -482,13 +781,27 @@ func (m *Manager) UpdateAssetExpiration(cid string, t time.Time) error {
 	return nil
 }
 
-// processExpiredAssets checks for expired assets and removes them
+// UpdateAssetGroupExpiration updates the expiration of all assets in the group
+func (m *Manager) UpdateAssetGroupExpiration(groupID string, t time.Time) error {
+	log.Infof("asset group event %s, reset group expiration:%s", groupID, t.String())
+
+	err := m.UpdateAssetGroupExpiry(groupID, m.nodeMgr.ServerID, t)
+	if err != nil {
+		return err
+	}
+
+	m.updateEarliestExpiration(t)
+
+	return nil
+}
+
+// processExpiredAssets checks for expired assets and removes them, the assets are kept in the pending delete state during the grace period
 func (m *Manager) processExpiredAssets() {
 	if m.earliestExpiration.After(time.Now()) {
 		return
 	}
 
-	records, err := m.LoadExpiredAssetRecords(m.nodeMgr.ServerID)
+	records, err := m.LoadExpiredAssetRecords(m.nodeMgr.ServerID, PendingDelete.String())
 	if err != nil {
 		log.Errorf("LoadExpiredAssetRecords err:%s", err.Error())
 		return
@@ -496,12 +809,12 @@ func (m *Manager) processExpiredAssets() {
 
 	for _, record := range records {
 		// do remove
-		err = m.RemoveAsset(record.CID, record.Hash)
+		err = m.RemoveAssetGracefully(record.CID, record.Hash)
 		log.Infof("the asset cid(%s) has expired, being removed, err: %v", record.CID, err)
 	}
 
 	// reset expiration
-	expiration, err := m.LoadMinExpiryOfAssetRecords(m.nodeMgr.ServerID)
+	expiration, err := m.LoadMinExpiryOfAssetRecords(m.nodeMgr.ServerID, PendingDelete.String())
 	if err != nil {
 		return
 	}
@@ -549,12 +862,31 @@ func (m *Manager) GetAssetRecordInfo(cid string) (*types.AssetRecord, error) {
 		return nil, err
 	}
 
+	if dInfo.State == Queued.String() {
+		dInfo.QueuePosition, err = m.LoadAssetQueuePosition(hash, Queued.String())
+		if err != nil {
+			log.Errorf("GetAssetRecordInfo hash:%s, LoadAssetQueuePosition err:%s", hash, err.Error())
+		}
+	}
+
 	dInfo.ReplicaInfos, err = m.LoadAssetReplicas(hash)
 	if err != nil {
 		log.Errorf("GetAssetRecordInfo hash:%s, LoadAssetReplicas err:%s", hash, err.Error())
+		return dInfo, err
+	}
+
+	if dInfo.DataShards > 0 {
+		// the hash of a shard replica is the hash of its shard
+		shardReplicas, err := m.LoadShardReplicas(hash)
+		if err != nil {
+			log.Errorf("GetAssetRecordInfo hash:%s, LoadShardReplicas err:%s", hash, err.Error())
+			return dInfo, err
+		}
+
+		dInfo.ReplicaInfos = append(dInfo.ReplicaInfos, shardReplicas...)
 	}
 
-	return dInfo, err
+	return dInfo, nil
 }
 
 // saveReplicaInformation stores replica information for nodes
@@ -574,17 +906,19 @@ func (m *Manager) saveReplicaInformation(nodes map[string]*node.Node, hash strin
 	return m.BatchSaveReplicas(replicaInfos)
 }
 
-// getDownloadSources gets download sources for a given CID
-func (m *Manager) getDownloadSources(cid string, nodes []string) []*types.CandidateDownloadInfo {
+// getDownloadSources gets download sources for a given CID, the succeeded edges with suitable NAT types
+// are added as sources up to the max edge source ratio, the nodes pull the blocks in raw format so the
+// credentials are not marked private
+func (m *Manager) getDownloadSources(cid string, candidates, edges []string) []*types.CandidateDownloadInfo {
 	titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
 	sources := make([]*types.CandidateDownloadInfo, 0)
-	for _, nodeID := range nodes {
+	for _, nodeID := range candidates {
 		cNode := m.nodeMgr.GetCandidateNode(nodeID)
 		if cNode == nil {
 			continue
 		}
 
-		credentials, err := cNode.Credentials(cid, titanRsa, m.nodeMgr.PrivateKey)
+		credentials, err := cNode.Credentials(cid, false, titanRsa, m.nodeMgr.PrivateKey)
 		if err != nil {
 			continue
 		}
@@ -596,87 +930,112 @@ func (m *Manager) getDownloadSources(cid string, nodes []string) []*types.Candid
 		sources = append(sources, source)
 	}
 
-	return sources
-}
-
-// chooseCandidateNodesForAssetReplica selects candidate nodes to pull asset replicas
-func (m *Manager) chooseCandidateNodesForAssetReplica(count int, filterNodes []string) map[string]*node.Node {
-	selectMap := make(map[string]*node.Node)
-	if count <= 0 {
-		return selectMap
-	}
-
-	if len(filterNodes) >= m.nodeMgr.Candidates {
-		return selectMap
+	maxEdges := m.maxEdgeSources(len(sources))
+	if maxEdges <= 0 || len(edges) == 0 {
+		return sources
 	}
 
-	filterMap := make(map[string]struct{})
-	for _, nodeID := range filterNodes {
-		filterMap[nodeID] = struct{}{}
-	}
+	// spread the traffic over the succeeded edges
+	list := append([]string(nil), edges...)
+	rand.Shuffle(len(list), func(i, j int) { list[i], list[j] = list[j], list[i] })
 
-	num := count * maxRetryCount
+	edgeSources := 0
+	for _, nodeID := range list {
+		if edgeSources >= maxEdges {
+			break
+		}
 
-	for i := 0; i < num; i++ {
-		node := m.nodeMgr.GetRandomCandidate()
-		if node == nil {
+		eNode := m.nodeMgr.GetEdgeNode(nodeID)
+		if eNode == nil || !isEdgeSourceNAT(eNode) {
 			continue
 		}
-		nodeID := node.NodeID
 
-		if _, exist := filterMap[nodeID]; exist {
+		credentials, err := eNode.Credentials(cid, false, titanRsa, m.nodeMgr.PrivateKey)
+		if err != nil {
 			continue
 		}
 
-		if node.DiskUsage > maxNodeDiskUsage {
+		sources = append(sources, &types.CandidateDownloadInfo{
+			URL:         eNode.DownloadAddr(),
+			Credentials: credentials,
+		})
+		edgeSources++
+	}
+
+	return sources
+}
+
+// GetPullSources returns the download sources of the asset or shard for the pulling nodes, e.g. to refresh the expired sources,
+// the sources are chosen from the succeeded replicas in the same way as the sources of the pull requests
+func (m *Manager) GetPullSources(cid string) ([]*types.CandidateDownloadInfo, error) {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return nil, xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	replicaInfos, err := m.LoadAssetReplicas(hash)
+	if err != nil {
+		return nil, xerrors.Errorf("%s load replicas err:%s", cid, err.Error())
+	}
+
+	candidates := make([]string, 0)
+	edges := make([]string, 0)
+	for _, r := range replicaInfos {
+		if r.Status != types.ReplicaStatusSucceeded {
 			continue
 		}
 
-		selectMap[nodeID] = node
-		if len(selectMap) >= count {
-			break
+		if r.IsCandidate {
+			candidates = append(candidates, r.NodeID)
+		} else {
+			edges = append(edges, r.NodeID)
 		}
 	}
 
-	return selectMap
+	if _, err := m.LoadShardOfHash(hash); err == nil {
+		return m.shardSources(cid, candidates, edges), nil
+	}
+
+	return m.getDownloadSources(cid, candidates, edges), nil
 }
 
-// chooseEdgeNodesForAssetReplica selects edge nodes to pull asset replicas
-func (m *Manager) chooseEdgeNodesForAssetReplica(count int, filterNodes []string) map[string]*node.Node {
-	selectMap := make(map[string]*node.Node)
-	if count <= 0 {
-		return selectMap
+// maxEdgeSources returns the maximum number of edge sources, so that the edges take at most
+// the max edge source ratio of the sources
+func (m *Manager) maxEdgeSources(candidateSources int) int {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get schedulerConfig err:%s", err.Error())
+		return 0
 	}
 
-	if len(filterNodes) >= m.nodeMgr.Edges {
-		return selectMap
+	ratio := cfg.MaxEdgeSourceRatio
+	if ratio <= 0 {
+		return 0
 	}
 
-	filterMap := make(map[string]struct{})
-	for _, nodeID := range filterNodes {
-		filterMap[nodeID] = struct{}{}
+	if ratio >= 1 {
+		return maxEdgeSourcesPerPull
 	}
 
-	for i := 0; i < count*maxRetryCount; i++ {
-		node := m.nodeMgr.GetRandomEdge()
-		if node == nil {
-			continue
-		}
-		nodeID := node.NodeID
+	count := int(math.Floor(ratio * float64(candidateSources) / (1 - ratio)))
+	if count > maxEdgeSourcesPerPull {
+		count = maxEdgeSourcesPerPull
+	}
 
-		if _, exist := filterMap[nodeID]; exist {
-			continue
-		}
+	return count
+}
 
-		if node.DiskUsage > maxNodeDiskUsage {
-			continue
-		}
+// isEdgeSourceNAT checks if the edge can be reached by the peer edges
+func isEdgeSourceNAT(n *node.Node) bool {
+	return n.NATType == types.NatTypeNo.String() || n.NATType == types.NatTypeFullCone.String()
+}
 
-		selectMap[nodeID] = node
-		if len(selectMap) >= count {
-			break
-		}
-	}
+// chooseCandidateNodesForAssetReplica selects candidate nodes to pull asset replicas
+func (m *Manager) chooseCandidateNodesForAssetReplica(count int, req *PlacementRequest) map[string]*node.Node {
+	return m.placementPolicy().SelectCandidates(count, req)
+}
 
-	return selectMap
+// chooseEdgeNodesForAssetReplica selects edge nodes to pull asset replicas
+func (m *Manager) chooseEdgeNodesForAssetReplica(count int, req *PlacementRequest) map[string]*node.Node {
+	return m.placementPolicy().SelectEdges(count, req)
 }
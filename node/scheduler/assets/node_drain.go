@@ -0,0 +1,148 @@
+package assets
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+)
+
+// nodeDrainCheck Periodically re-creates the replicas of the draining nodes on other nodes
+func (m *Manager) nodeDrainCheck(ctx context.Context) {
+	ticker := time.NewTicker(nodeDrainInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.drainNodes()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// drainNodes moves the replicas off the draining nodes, the number of repairs is limited by the free pulling slots
+func (m *Manager) drainNodes() {
+	nodeIDs, err := m.LoadMaintenanceNodes(types.NodeMaintenanceDraining, m.nodeMgr.ServerID)
+	if err != nil {
+		log.Errorf("LoadMaintenanceNodes err:%s", err.Error())
+		return
+	}
+
+	for _, nodeID := range nodeIDs {
+		m.lock.Lock()
+		limit := maxConcurrentPulls - len(m.apTickers)
+		m.lock.Unlock()
+
+		if limit > maxReplicaRepairsPerRound {
+			limit = maxReplicaRepairsPerRound
+		}
+
+		m.drainNode(nodeID, limit)
+	}
+}
+
+// drainNode removes the replicas of the node that have enough replicas on other nodes,
+// and re-creates the others on other nodes, up to limit assets
+func (m *Manager) drainNode(nodeID string, limit int) {
+	hashes, err := m.LoadReplicaHashesOfNode(nodeID, types.ReplicaStatusSucceeded, maxDrainAssetsPerRound)
+	if err != nil {
+		log.Errorf("drain node %s LoadReplicaHashesOfNode err:%s", nodeID, err.Error())
+		return
+	}
+
+	excludes := map[string]struct{}{nodeID: {}}
+
+	for _, hash := range hashes {
+		record, err := m.LoadAssetRecord(hash)
+		if err == sql.ErrNoRows {
+			if shard, err := m.LoadShardOfHash(hash); err == nil {
+				// the replica is a shard of an erasure coded asset
+				if m.drainShardReplica(shard, nodeID, limit > 0) {
+					limit--
+				}
+				continue
+			}
+		}
+
+		if err != nil {
+			if err == sql.ErrNoRows {
+				// the asset is removed, the replica is left
+				if err = m.DeleteAssetReplica(hash, nodeID); err != nil {
+					log.Errorf("drain node %s DeleteAssetReplica %s err:%s", nodeID, hash, err.Error())
+				}
+			}
+			continue
+		}
+
+		if record.State != Servicing.String() {
+			// wait for the pulling of the asset
+			continue
+		}
+
+		enough, err := m.hasReplicasWithout(record, nodeID)
+		if err != nil {
+			log.Errorf("drain node %s check asset %s replicas err:%s", nodeID, record.CID, err.Error())
+			continue
+		}
+
+		if enough {
+			log.Infof("asset event %s, drain node %s, remove replica", record.CID, nodeID)
+
+			if err := m.RemoveReplica(record.CID, record.Hash, nodeID); err != nil {
+				log.Errorf("drain node %s RemoveReplica %s err:%s", nodeID, record.CID, err.Error())
+			}
+			continue
+		}
+
+		if limit <= 0 {
+			continue
+		}
+		limit--
+
+		log.Infof("asset event %s, drain node %s, re-create replica", record.CID, nodeID)
+
+		if err := m.repairReplicas(record, excludes); err != nil {
+			log.Errorf("drain node %s repair asset %s replicas err:%s", nodeID, record.CID, err.Error())
+		}
+	}
+}
+
+// hasReplicasWithout checks if the asset has the required replicas of the node type without the node,
+// only the replicas on the online nodes that are not draining are counted
+func (m *Manager) hasReplicasWithout(record *types.AssetRecord, nodeID string) (bool, error) {
+	replicaInfos, err := m.LoadAssetReplicas(record.Hash)
+	if err != nil {
+		return false, err
+	}
+
+	isCandidate := false
+	for _, r := range replicaInfos {
+		if r.NodeID == nodeID {
+			isCandidate = r.IsCandidate
+			break
+		}
+	}
+
+	count := int64(0)
+	for _, r := range replicaInfos {
+		if r.NodeID == nodeID || r.Status != types.ReplicaStatusSucceeded || r.IsCandidate != isCandidate {
+			continue
+		}
+
+		// the offline node is not in the node manager
+		if n := m.nodeMgr.GetNode(r.NodeID); n == nil || n.Maintenance == types.NodeMaintenanceDraining {
+			continue
+		}
+
+		count++
+	}
+
+	if isCandidate {
+		return count >= record.NeedCandidateReplicas, nil
+	}
+
+	return count >= record.NeedEdgeReplica, nil
+}
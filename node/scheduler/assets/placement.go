@@ -0,0 +1,397 @@
+package assets
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+	"github.com/linguohua/titan/node/scheduler/node"
+)
+
+const (
+	// PlacementRandom selects the replica nodes randomly
+	PlacementRandom = "random"
+	// PlacementLocality spreads the replicas across areas and NAT types
+	PlacementLocality = "locality"
+	// PlacementScore samples the replica nodes in proportion to their scores
+	PlacementScore = "score"
+)
+
+// PlacementRequest describes the constraints on the nodes of asset replicas
+type PlacementRequest struct {
+	// Areas preferred by the asset, matched as a prefix of the node area
+	Areas []string
+	// Nodes that can not be selected, e.g. nodes already holding the replica
+	FilterNodes []string
+	// Labels selects the nodes by their labels, all nodes are selected if it is nil
+	Labels *types.LabelSelector
+	// Attempts per replica to select a random node, the default is used if it is 0
+	Attempts int
+}
+
+// attempts returns the attempts per replica to select a random node
+func (req *PlacementRequest) attempts() int {
+	if req.Attempts <= 0 {
+		return defaultSelectAttempts
+	}
+
+	return req.Attempts
+}
+
+// filterMap returns the filter nodes as a set
+func (req *PlacementRequest) filterMap() map[string]struct{} {
+	filterMap := make(map[string]struct{})
+	for _, nodeID := range req.FilterNodes {
+		filterMap[nodeID] = struct{}{}
+	}
+
+	return filterMap
+}
+
+// selectable checks if the node can pull a new replica and matches the labels of the request
+func (req *PlacementRequest) selectable(n *node.Node, filterMap map[string]struct{}) bool {
+	return isNodeSelectable(n, filterMap) && req.Labels.Matches(n.Labels)
+}
+
+// inPreferredAreas checks if the area matches one of the preferred areas
+func (req *PlacementRequest) inPreferredAreas(area string) bool {
+	for _, a := range req.Areas {
+		if a != "" && strings.HasPrefix(area, a) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PlacementPolicy selects the nodes that pull the asset replicas
+type PlacementPolicy interface {
+	// SelectCandidates selects up to count candidate nodes
+	SelectCandidates(count int, req *PlacementRequest) map[string]*node.Node
+	// SelectEdges selects up to count edge nodes
+	SelectEdges(count int, req *PlacementRequest) map[string]*node.Node
+}
+
+// RegisterPlacementPolicy registers a placement policy, it can be enabled by the scheduler config
+func (m *Manager) RegisterPlacementPolicy(name string, policy PlacementPolicy) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.placementPolicies[name] = policy
+}
+
+// placementPolicy returns the placement policy from the scheduler config, the random policy is the default
+func (m *Manager) placementPolicy() PlacementPolicy {
+	name := PlacementRandom
+
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get schedulerConfig err:%s", err.Error())
+	} else if cfg.PlacementPolicy != "" {
+		name = cfg.PlacementPolicy
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	policy, ok := m.placementPolicies[name]
+	if !ok {
+		log.Warnf("placement policy %s not found, use %s", name, PlacementRandom)
+		return m.placementPolicies[PlacementRandom]
+	}
+
+	return policy
+}
+
+// isNodeSelectable checks if the node can pull a new replica, the nodes in maintenance or quarantine are skipped
+func isNodeSelectable(n *node.Node, filterMap map[string]struct{}) bool {
+	if _, exist := filterMap[n.NodeID]; exist {
+		return false
+	}
+
+	if n.InMaintenance() || n.InQuarantine() {
+		return false
+	}
+
+	return n.DiskUsage <= maxNodeDiskUsage
+}
+
+// randomPlacement selects nodes by the random node numbers
+type randomPlacement struct {
+	nodeMgr *node.Manager
+}
+
+// SelectCandidates selects candidate nodes randomly
+func (p *randomPlacement) SelectCandidates(count int, req *PlacementRequest) map[string]*node.Node {
+	if req.Labels != nil {
+		return selectLabeledNodes(p.nodeMgr.GetCandidateNodeList(), count, req)
+	}
+
+	return p.selectNodes(count, req, p.nodeMgr.Candidates, p.nodeMgr.GetRandomCandidate)
+}
+
+// SelectEdges selects edge nodes randomly
+func (p *randomPlacement) SelectEdges(count int, req *PlacementRequest) map[string]*node.Node {
+	if req.Labels != nil {
+		return selectLabeledNodes(p.nodeMgr.GetEdgeNodeList(), count, req)
+	}
+
+	return p.selectNodes(count, req, p.nodeMgr.Edges, p.nodeMgr.GetRandomEdge)
+}
+
+// selectLabeledNodes selects nodes randomly from the nodes matching the labels of the request,
+// so that a small labeled pool is not missed by the random draws over all nodes
+func selectLabeledNodes(nodes []*node.Node, count int, req *PlacementRequest) map[string]*node.Node {
+	selectMap := make(map[string]*node.Node)
+	if count <= 0 {
+		return selectMap
+	}
+
+	filterMap := req.filterMap()
+	matched := make([]*node.Node, 0)
+	for _, n := range nodes {
+		if req.selectable(n, filterMap) {
+			matched = append(matched, n)
+		}
+	}
+
+	rand.Shuffle(len(matched), func(i, j int) { matched[i], matched[j] = matched[j], matched[i] })
+
+	for _, n := range matched {
+		if len(selectMap) >= count {
+			break
+		}
+
+		selectMap[n.NodeID] = n
+	}
+
+	return selectMap
+}
+
+func (p *randomPlacement) selectNodes(count int, req *PlacementRequest, online int, random func() *node.Node) map[string]*node.Node {
+	selectMap := make(map[string]*node.Node)
+	if count <= 0 {
+		return selectMap
+	}
+
+	if len(req.FilterNodes) >= online {
+		return selectMap
+	}
+
+	filterMap := req.filterMap()
+
+	for i := 0; i < count*req.attempts(); i++ {
+		n := random()
+		if n == nil {
+			continue
+		}
+
+		if !req.selectable(n, filterMap) {
+			continue
+		}
+
+		selectMap[n.NodeID] = n
+		if len(selectMap) >= count {
+			break
+		}
+	}
+
+	return selectMap
+}
+
+// localityPlacement spreads the replicas across areas, prefers the areas of the request
+// and avoids placing all replicas behind symmetric NAT
+type localityPlacement struct {
+	nodeMgr *node.Manager
+	lock    sync.Mutex
+	rand    *rand.Rand
+}
+
+func newLocalityPlacement(nodeMgr *node.Manager) *localityPlacement {
+	return &localityPlacement{
+		nodeMgr: nodeMgr,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// SelectCandidates selects candidate nodes by locality
+func (p *localityPlacement) SelectCandidates(count int, req *PlacementRequest) map[string]*node.Node {
+	return p.selectNodes(p.nodeMgr.GetCandidateNodeList(), count, req)
+}
+
+// SelectEdges selects edge nodes by locality
+func (p *localityPlacement) SelectEdges(count int, req *PlacementRequest) map[string]*node.Node {
+	return p.selectNodes(p.nodeMgr.GetEdgeNodeList(), count, req)
+}
+
+func (p *localityPlacement) selectNodes(nodes []*node.Node, count int, req *PlacementRequest) map[string]*node.Node {
+	selectMap := make(map[string]*node.Node)
+	if count <= 0 {
+		return selectMap
+	}
+
+	// the replicas already placed in each area
+	areaReplicas := make(map[string]int)
+	openNAT := false
+	for _, nodeID := range req.FilterNodes {
+		n := p.nodeMgr.GetNode(nodeID)
+		if n == nil {
+			continue
+		}
+
+		areaReplicas[n.Area()]++
+		if !isSymmetricNAT(n) {
+			openNAT = true
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	filterMap := req.filterMap()
+	preferred := make(map[string][]*node.Node)
+	others := make(map[string][]*node.Node)
+
+	p.rand.Shuffle(len(nodes), func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] })
+	for _, n := range nodes {
+		if !req.selectable(n, filterMap) {
+			continue
+		}
+
+		area := n.Area()
+		if req.inPreferredAreas(area) {
+			preferred[area] = append(preferred[area], n)
+		} else {
+			others[area] = append(others[area], n)
+		}
+	}
+
+	openNAT = p.pick(preferred, areaReplicas, openNAT, count, selectMap)
+	p.pick(others, areaReplicas, openNAT, count, selectMap)
+
+	return selectMap
+}
+
+// pick selects nodes from the area with the fewest replicas in turn, until count nodes are selected
+func (p *localityPlacement) pick(areaNodes map[string][]*node.Node, areaReplicas map[string]int, openNAT bool, count int, selectMap map[string]*node.Node) bool {
+	areas := make([]string, 0, len(areaNodes))
+	for area := range areaNodes {
+		areas = append(areas, area)
+	}
+	p.rand.Shuffle(len(areas), func(i, j int) { areas[i], areas[j] = areas[j], areas[i] })
+
+	for len(selectMap) < count && len(areas) > 0 {
+		sort.SliceStable(areas, func(i, j int) bool {
+			return areaReplicas[areas[i]] < areaReplicas[areas[j]]
+		})
+
+		area := areas[0]
+		list := areaNodes[area]
+
+		index := 0
+		if !openNAT {
+			// until a replica can be reached without symmetric NAT, prefer such nodes
+			for i, n := range list {
+				if !isSymmetricNAT(n) {
+					index = i
+					break
+				}
+			}
+		}
+
+		n := list[index]
+		list = append(list[:index], list[index+1:]...)
+
+		selectMap[n.NodeID] = n
+		areaReplicas[area]++
+		if !isSymmetricNAT(n) {
+			openNAT = true
+		}
+
+		if len(list) == 0 {
+			delete(areaNodes, area)
+			areas = areas[1:]
+		} else {
+			areaNodes[area] = list
+		}
+	}
+
+	return openNAT
+}
+
+// scorePlacement samples nodes in proportion to their scores
+type scorePlacement struct {
+	nodeMgr *node.Manager
+	lock    sync.Mutex
+	rand    *rand.Rand
+}
+
+func newScorePlacement(nodeMgr *node.Manager) *scorePlacement {
+	return &scorePlacement{
+		nodeMgr: nodeMgr,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// SelectCandidates selects candidate nodes by score
+func (p *scorePlacement) SelectCandidates(count int, req *PlacementRequest) map[string]*node.Node {
+	return p.selectNodes(p.nodeMgr.GetCandidateNodeList(), count, req)
+}
+
+// SelectEdges selects edge nodes by score
+func (p *scorePlacement) SelectEdges(count int, req *PlacementRequest) map[string]*node.Node {
+	return p.selectNodes(p.nodeMgr.GetEdgeNodeList(), count, req)
+}
+
+// selectNodes samples nodes without replacement, each node gets the key rand^(1/score)
+// and the nodes with the largest keys are selected
+func (p *scorePlacement) selectNodes(nodes []*node.Node, count int, req *PlacementRequest) map[string]*node.Node {
+	selectMap := make(map[string]*node.Node)
+	if count <= 0 {
+		return selectMap
+	}
+
+	filterMap := req.filterMap()
+	list := make([]*node.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if req.selectable(n, filterMap) {
+			list = append(list, n)
+		}
+	}
+
+	scores := p.nodeMgr.ScoreNodes(list)
+	keys := make([]float64, len(list))
+
+	p.lock.Lock()
+	for i, score := range scores {
+		keys[i] = math.Pow(p.rand.Float64(), 1/score.Score)
+	}
+	p.lock.Unlock()
+
+	indexes := make([]int, len(list))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return keys[indexes[i]] > keys[indexes[j]]
+	})
+
+	for _, i := range indexes {
+		if len(selectMap) >= count {
+			break
+		}
+
+		selectMap[list[i].NodeID] = list[i]
+	}
+
+	return selectMap
+}
+
+// isSymmetricNAT checks if the node is behind symmetric NAT
+func isSymmetricNAT(n *node.Node) bool {
+	return n.NATType == types.NatTypeSymmetric.String()
+}
@@ -0,0 +1,138 @@
+package assets
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/linguohua/titan/api/types"
+	"github.com/linguohua/titan/node/scheduler/node"
+	"golang.org/x/xerrors"
+)
+
+// unknownArea is the area of the nodes without location
+const unknownArea = "unknown"
+
+// PlanAssetPlacement selects the replica nodes of the asset like the asset pulling does, an edge is selected for each shard
+// of an erasure coded asset. It neither saves the replicas nor sends the pull requests
+func (m *Manager) PlanAssetPlacement(info *types.PullAssetReq) (*types.AssetPlacement, error) {
+	placement := &types.AssetPlacement{
+		CID:          info.CID,
+		AreaReplicas: make(map[string]int),
+	}
+
+	record, err := m.LoadAssetRecord(info.Hash)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	var candidates, edges []string
+	if record != nil {
+		if record.State != Servicing.String() {
+			return nil, xerrors.Errorf("asset state is %s", record.State)
+		}
+
+		if record.DataShards > 0 || info.ErasureCoding != nil {
+			return nil, xerrors.New("the replicas of an erasure coded asset can not be replenished")
+		}
+
+		placement.Size = record.TotalSize
+
+		replicaInfos, err := m.LoadAssetReplicas(record.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range replicaInfos {
+			if r.Status != types.ReplicaStatusSucceeded {
+				continue
+			}
+
+			if r.IsCandidate {
+				candidates = append(candidates, r.NodeID)
+			} else {
+				edges = append(edges, r.NodeID)
+			}
+		}
+	}
+
+	attempts := int(m.requestPullPolicy(info).SelectAttempts)
+
+	// the seed and the other candidates are selected in turn
+	candidateReplicas := seedReplicaCount + m.GetCandidateReplicaCount()
+	for _, need := range []int{seedReplicaCount, candidateReplicas} {
+		count := need - len(candidates)
+		if count < 1 {
+			continue
+		}
+
+		req := &PlacementRequest{Areas: info.Areas, FilterNodes: candidates, Labels: info.Labels, Attempts: attempts}
+		nodes := m.chooseCandidateNodesForAssetReplica(count, req)
+		if len(nodes) < count {
+			available := countSelectableNodes(m.nodeMgr.GetCandidateNodeList(), req)
+			placement.Shortfalls = append(placement.Shortfalls, shortfall("candidates", count, len(nodes), available))
+		}
+
+		for _, n := range nodes {
+			candidates = append(candidates, n.NodeID)
+			placement.CandidateNodes = append(placement.CandidateNodes, n.NodeID)
+			placement.AreaReplicas[nodeArea(n)]++
+		}
+
+		if len(nodes) < count {
+			// the asset pulling stops at the candidate that can not be selected
+			return placement, nil
+		}
+	}
+
+	edgeReplicas := int(info.Replicas)
+	if info.ErasureCoding != nil {
+		// every edge holds one of the shards, like the asset pulling does
+		edgeReplicas = int(info.ErasureCoding.DataShards + info.ErasureCoding.ParityShards)
+	}
+
+	count := edgeReplicas - len(edges)
+	if count > 0 {
+		req := &PlacementRequest{Areas: info.Areas, FilterNodes: edges, Labels: info.Labels, Attempts: attempts}
+		nodes := m.chooseEdgeNodesForAssetReplica(count, req)
+		if len(nodes) < count {
+			available := countSelectableNodes(m.nodeMgr.GetEdgeNodeList(), req)
+			placement.Shortfalls = append(placement.Shortfalls, shortfall("edges", count, len(nodes), available))
+		}
+
+		for _, n := range nodes {
+			placement.EdgeNodes = append(placement.EdgeNodes, n.NodeID)
+			placement.AreaReplicas[nodeArea(n)]++
+		}
+	}
+
+	return placement, nil
+}
+
+// countSelectableNodes returns the number of nodes that can be selected by the placement request
+func countSelectableNodes(nodes []*node.Node, req *PlacementRequest) int {
+	filterMap := req.filterMap()
+
+	count := 0
+	for _, n := range nodes {
+		if req.selectable(n, filterMap) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// shortfall describes the replicas of the node type that can not be placed
+func shortfall(nodeType string, need, chosen, available int) string {
+	return fmt.Sprintf("need %d %s, chosen %d, only %d %s available with disk <= %.0f%%, not in maintenance and matching the labels",
+		need, nodeType, chosen, available, nodeType, maxNodeDiskUsage)
+}
+
+// nodeArea returns the area of the node, the nodes without location are in the unknown area
+func nodeArea(n *node.Node) string {
+	if area := n.Area(); area != "" {
+		return area
+	}
+
+	return unknownArea
+}
@@ -0,0 +1,137 @@
+package assets
+
+import (
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+)
+
+// The pull policy used if neither the request nor the scheduler config sets it
+const (
+	defaultPullTimeout    = 60 // seconds without pull progress before the node pulls are failed
+	defaultRetryInterval  = 60 // seconds to wait before the failed pulls are retried
+	defaultMaxRetries     = 3  // maximum number of retries of the failed pulls
+	defaultSelectAttempts = 3  // attempts per replica to select a random node
+
+	defaultMaxReplicaReplacements = 10 // maximum number of failed replicas replaced by other nodes per pulling phase
+)
+
+// pullPolicy represents the timeout and retry policy of an asset pulling
+type pullPolicy struct {
+	PullTimeout    int64
+	RetryInterval  int64
+	MaxRetries     int64
+	SelectAttempts int64
+}
+
+// defaultPullPolicy returns the pull policy of the scheduler config
+func (m *Manager) defaultPullPolicy() pullPolicy {
+	policy := pullPolicy{
+		PullTimeout:    defaultPullTimeout,
+		RetryInterval:  defaultRetryInterval,
+		MaxRetries:     defaultMaxRetries,
+		SelectAttempts: defaultSelectAttempts,
+	}
+
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get schedulerConfig err:%s", err.Error())
+		return policy
+	}
+
+	if cfg.AssetPullTimeout > 0 {
+		policy.PullTimeout = int64(cfg.AssetPullTimeout)
+	}
+	if cfg.AssetRetryInterval > 0 {
+		policy.RetryInterval = int64(cfg.AssetRetryInterval)
+	}
+	if cfg.AssetMaxRetries > 0 {
+		policy.MaxRetries = int64(cfg.AssetMaxRetries)
+	}
+	if cfg.NodeSelectAttempts > 0 {
+		policy.SelectAttempts = int64(cfg.NodeSelectAttempts)
+	}
+
+	return policy
+}
+
+// maxReplicaReplacements returns the maximum number of failed replicas of a pulling phase that are replaced by other nodes
+func (m *Manager) maxReplicaReplacements() int64 {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get schedulerConfig err:%s", err.Error())
+		return defaultMaxReplicaReplacements
+	}
+
+	if cfg.MaxReplicaReplacements <= 0 {
+		return defaultMaxReplicaReplacements
+	}
+
+	return int64(cfg.MaxReplicaReplacements)
+}
+
+// requestPullPolicy returns the effective pull policy of the request, the scheduler defaults are used for the zero values
+func (m *Manager) requestPullPolicy(req *types.PullAssetReq) pullPolicy {
+	policy := m.defaultPullPolicy()
+
+	if req.PullTimeout > 0 {
+		policy.PullTimeout = req.PullTimeout
+	}
+	if req.RetryInterval > 0 {
+		policy.RetryInterval = req.RetryInterval
+	}
+	if req.MaxRetries > 0 {
+		policy.MaxRetries = req.MaxRetries
+	}
+	if req.SelectAttempts > 0 {
+		policy.SelectAttempts = req.SelectAttempts
+	}
+
+	return policy
+}
+
+// recordPullPolicy returns the pull policy saved in the asset record
+func recordPullPolicy(record *types.AssetRecord) pullPolicy {
+	return pullPolicy{
+		PullTimeout:    record.PullTimeout,
+		RetryInterval:  record.RetryInterval,
+		MaxRetries:     record.MaxRetries,
+		SelectAttempts: record.SelectAttempts,
+	}
+}
+
+// setPullPolicy sets the pull policy of the asset
+func (state *AssetPullingInfo) setPullPolicy(policy pullPolicy) {
+	state.PullTimeout = policy.PullTimeout
+	state.RetryInterval = policy.RetryInterval
+	state.MaxRetries = policy.MaxRetries
+	state.SelectAttempts = policy.SelectAttempts
+}
+
+// pullTimeout returns the time without pull progress before the node pulls are failed
+func (state *AssetPullingInfo) pullTimeout() time.Duration {
+	if state.PullTimeout <= 0 {
+		// the asset is created before the pull policy is saved
+		return defaultPullTimeout * time.Second
+	}
+
+	return time.Duration(state.PullTimeout) * time.Second
+}
+
+// retryInterval returns the time to wait before the failed pulls are retried
+func (state *AssetPullingInfo) retryInterval() time.Duration {
+	if state.RetryInterval <= 0 {
+		return defaultRetryInterval * time.Second
+	}
+
+	return time.Duration(state.RetryInterval) * time.Second
+}
+
+// maxRetries returns the maximum number of retries of the failed pulls
+func (state *AssetPullingInfo) maxRetries() int64 {
+	if state.MaxRetries <= 0 {
+		return defaultMaxRetries
+	}
+
+	return state.MaxRetries
+}
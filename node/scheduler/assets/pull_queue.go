@@ -0,0 +1,69 @@
+package assets
+
+import (
+	"context"
+	"time"
+)
+
+// notifyPullQueue notifies the pull queue to start the queued assets
+func (m *Manager) notifyPullQueue() {
+	select {
+	case m.pullQueueNotify <- struct{}{}:
+	default:
+	}
+}
+
+// pullQueueCheck starts the queued assets when the pulling slots are free
+func (m *Manager) pullQueueCheck(ctx context.Context) {
+	ticker := time.NewTicker(pullQueueInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.pullQueueNotify:
+		case <-ctx.Done():
+			return
+		}
+
+		m.startQueuedPulls()
+	}
+}
+
+// startQueuedPulls dequeues the assets by priority and creation time, up to the free pulling slots
+func (m *Manager) startQueuedPulls() {
+	m.lock.Lock()
+	free := maxConcurrentPulls - len(m.apTickers)
+	m.lock.Unlock()
+
+	if free <= 0 {
+		return
+	}
+
+	hashes, err := m.LoadQueuedAssetHashes(Queued.String(), m.nodeMgr.ServerID, free)
+	if err != nil {
+		log.Errorf("LoadQueuedAssetHashes err:%s", err.Error())
+		return
+	}
+
+	for _, hash := range hashes {
+		m.lock.Lock()
+		_, started := m.apTickers[hash]
+		m.lock.Unlock()
+		if started {
+			continue
+		}
+
+		// the ticker takes the pulling slot until the asset leaves the pulling states,
+		// its timeout is reset to the asset pull timeout when the seed is selected
+		if !m.addOrResetAssetTicker(hash, time.Duration(m.defaultPullPolicy().PullTimeout)*time.Second) {
+			// the slots are taken by the assets that started pulling outside the queue
+			return
+		}
+
+		if err := m.assetStateMachines.Send(AssetHash(hash), PullAssetDequeue{}); err != nil {
+			log.Errorf("dequeue asset %s err:%s", hash, err.Error())
+			m.deleteAssetTicker(hash)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package assets
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linguohua/titan/api/types"
+	"github.com/linguohua/titan/node/config"
+	"github.com/linguohua/titan/node/scheduler/node"
+	"golang.org/x/xerrors"
+)
+
+// replicaRebalanceCheck Periodically moves replicas from the nodes above the disk high watermark to the under-used nodes,
+// and checks the progress of the moves
+func (m *Manager) replicaRebalanceCheck(ctx context.Context) {
+	m.restoreReplicaMoves()
+
+	rebalanceTicker := time.NewTicker(replicaRebalanceInterval)
+	defer rebalanceTicker.Stop()
+
+	progressTicker := time.NewTicker(pullProgressInterval)
+	defer progressTicker.Stop()
+
+	for {
+		select {
+		case <-rebalanceTicker.C:
+			m.rebalanceReplicas()
+		case <-progressTicker.C:
+			m.checkReplicaMoves()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// restoreReplicaMoves loads the moves that were in progress before the scheduler restarted
+func (m *Manager) restoreReplicaMoves() {
+	records, err := m.LoadUnfinishedReplicaMoveRecords(m.nodeMgr.ServerID)
+	if err != nil {
+		log.Errorf("LoadUnfinishedReplicaMoveRecords err:%s", err.Error())
+		return
+	}
+
+	m.moveLock.Lock()
+	defer m.moveLock.Unlock()
+
+	for _, record := range records {
+		m.moves[record.Hash] = record
+	}
+}
+
+// rebalanceReplicas starts the replica moves of this round, limited by the config and the moves in progress
+func (m *Manager) rebalanceReplicas() {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get schedulerConfig err:%s", err.Error())
+		return
+	}
+
+	if !cfg.EnableRebalance {
+		return
+	}
+
+	if cfg.RebalanceLowWatermark >= cfg.RebalanceHighWatermark {
+		log.Warnf("rebalance low watermark %.2f is not below the high watermark %.2f", cfg.RebalanceLowWatermark, cfg.RebalanceHighWatermark)
+		return
+	}
+
+	m.moveLock.Lock()
+	limit := maxConcurrentReplicaMoves - len(m.moves)
+	m.moveLock.Unlock()
+
+	if limit > cfg.RebalanceMovesPerRound {
+		limit = cfg.RebalanceMovesPerRound
+	}
+
+	if limit <= 0 {
+		return
+	}
+
+	// the replicas are moved between the nodes of the same type
+	limit -= m.rebalanceNodes(m.nodeMgr.GetCandidateNodeList(), &cfg, limit)
+	m.rebalanceNodes(m.nodeMgr.GetEdgeNodeList(), &cfg, limit)
+}
+
+// rebalanceNodes moves the replicas of the nodes above the high watermark to the nodes below the low watermark,
+// returns the number of moves started
+func (m *Manager) rebalanceNodes(nodes []*node.Node, cfg *config.SchedulerCfg, limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+
+	sources := make([]*node.Node, 0)
+	targets := make([]*node.Node, 0)
+	for _, n := range nodes {
+		if n.DiskUsage >= cfg.RebalanceHighWatermark {
+			sources = append(sources, n)
+		} else if n.DiskUsage <= cfg.RebalanceLowWatermark && !n.InMaintenance() && !n.InQuarantine() {
+			targets = append(targets, n)
+		}
+	}
+
+	if len(sources) == 0 || len(targets) == 0 {
+		return 0
+	}
+
+	// the fullest nodes are relieved first, and the emptiest nodes receive first
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].DiskUsage > sources[j].DiskUsage
+	})
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].DiskUsage < targets[j].DiskUsage
+	})
+
+	started := 0
+	for _, source := range sources {
+		hashes, err := m.LoadReplicaHashesOfNode(source.NodeID, types.ReplicaStatusSucceeded, maxRebalanceAssetsPerNode)
+		if err != nil {
+			log.Errorf("rebalance node %s LoadReplicaHashesOfNode err:%s", source.NodeID, err.Error())
+			continue
+		}
+
+		for _, hash := range hashes {
+			if started >= limit {
+				return started
+			}
+
+			err := m.startReplicaMove(hash, source, targets)
+			if err != nil {
+				log.Debugf("rebalance node %s asset %s: %s", source.NodeID, hash, err.Error())
+				continue
+			}
+
+			started++
+			// spread the moves over the targets
+			targets = append(targets[1:], targets[0])
+		}
+	}
+
+	return started
+}
+
+// startReplicaMove creates a new replica of the asset on one of the targets, the replica on the source node
+// is removed after the new replica is pulled
+func (m *Manager) startReplicaMove(hash string, source *node.Node, targets []*node.Node) error {
+	m.moveLock.Lock()
+	_, moving := m.moves[hash]
+	m.moveLock.Unlock()
+
+	if moving {
+		return xerrors.New("asset replica is moving")
+	}
+
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		return err
+	}
+
+	if record.State != Servicing.String() {
+		return xerrors.Errorf("asset state is %s", record.State)
+	}
+
+	replicaInfos, err := m.LoadAssetReplicas(hash)
+	if err != nil {
+		return err
+	}
+
+	holders := make(map[string]struct{})
+	var candidates, edges []string
+	for _, r := range replicaInfos {
+		holders[r.NodeID] = struct{}{}
+
+		if r.Status != types.ReplicaStatusSucceeded {
+			continue
+		}
+
+		if r.IsCandidate {
+			candidates = append(candidates, r.NodeID)
+		} else {
+			edges = append(edges, r.NodeID)
+		}
+	}
+
+	// the new replica keeps to the nodes of the asset labels
+	req := &PlacementRequest{Labels: recordLabelSelector(record)}
+
+	var target *node.Node
+	for _, n := range targets {
+		if req.selectable(n, holders) {
+			target = n
+			break
+		}
+	}
+
+	if target == nil {
+		return xerrors.New("target node not found")
+	}
+
+	sources := m.getDownloadSources(record.CID, candidates, edges)
+	if len(sources) < 1 {
+		return xerrors.New("source node not found")
+	}
+
+	move := &types.ReplicaMoveRecord{
+		ID:          uuid.NewString(),
+		Hash:        hash,
+		CID:         record.CID,
+		FromNodeID:  source.NodeID,
+		ToNodeID:    target.NodeID,
+		Size:        record.TotalSize,
+		Status:      types.ReplicaStatusPulling,
+		ServerID:    string(m.nodeMgr.ServerID),
+		CreatedTime: time.Now(),
+	}
+
+	err = m.SaveReplicaMoveRecord(move)
+	if err != nil {
+		return err
+	}
+
+	m.moveLock.Lock()
+	m.moves[hash] = move
+	m.moveLock.Unlock()
+
+	log.Infof("asset event %s, move replica %s -> %s", record.CID, source.NodeID, target.NodeID)
+
+	err = m.saveReplicaInformation(map[string]*node.Node{target.NodeID: target}, hash, target.Type == types.NodeCandidate)
+	if err != nil {
+		m.finishReplicaMove(move, types.ReplicaStatusFailed, err.Error())
+		return err
+	}
+
+	err = target.PullAsset(context.Background(), record.CID, sources)
+	if err != nil {
+		m.failReplicaMove(move, err.Error())
+		return err
+	}
+
+	target.IncrCurPullingCount(1)
+
+	return nil
+}
+
+// checkReplicaMoves gets the pull progresses of the new replicas and completes the moves,
+// the nodes whose moves of the last round are still being checked are skipped
+func (m *Manager) checkReplicaMoves() {
+	m.moveLock.Lock()
+	nodeMoves := make(map[string][]*types.ReplicaMoveRecord)
+	for _, move := range m.moves {
+		if _, ok := m.moveChecks[move.ToNodeID]; ok {
+			continue
+		}
+		nodeMoves[move.ToNodeID] = append(nodeMoves[move.ToNodeID], move)
+	}
+
+	for nodeID := range nodeMoves {
+		m.moveChecks[nodeID] = struct{}{}
+	}
+	m.moveLock.Unlock()
+
+	for nodeID, moves := range nodeMoves {
+		go func(nodeID string, moves []*types.ReplicaMoveRecord) {
+			defer func() {
+				m.moveLock.Lock()
+				delete(m.moveChecks, nodeID)
+				m.moveLock.Unlock()
+			}()
+
+			m.checkNodeReplicaMoves(nodeID, moves)
+		}(nodeID, moves)
+	}
+}
+
+// checkNodeReplicaMoves checks the moves to the node
+func (m *Manager) checkNodeReplicaMoves(nodeID string, moves []*types.ReplicaMoveRecord) {
+	cids := make([]string, 0, len(moves))
+	for _, move := range moves {
+		cids = append(cids, move.CID)
+	}
+
+	progresses := make(map[string]*types.AssetPullProgress)
+
+	result, err := m.requestNodePullProgresses(nodeID, cids)
+	if err != nil {
+		log.Errorf("checkNodeReplicaMoves %s requestNodePullProgresses err:%s", nodeID, err.Error())
+	} else {
+		for _, progress := range result.Progresses {
+			progresses[progress.CID] = progress
+		}
+	}
+
+	for _, move := range moves {
+		progress, ok := progresses[move.CID]
+		if !ok || (progress.Status != types.ReplicaStatusSucceeded && progress.Status != types.ReplicaStatusFailed) {
+			if time.Since(move.CreatedTime) > replicaMoveTimeout {
+				m.failReplicaMove(move, "timeout")
+			}
+			continue
+		}
+
+		err = m.UpdateUnfinishedReplica(&types.ReplicaInfo{
+			Status:   progress.Status,
+			DoneSize: progress.DoneSize,
+			Hash:     move.Hash,
+			NodeID:   move.ToNodeID,
+			FailMsg:  progress.Msg,
+		})
+		if err != nil {
+			log.Errorf("checkNodeReplicaMoves %s UpdateUnfinishedReplica err:%s", nodeID, err.Error())
+		}
+
+		if progress.Status == types.ReplicaStatusFailed {
+			m.failReplicaMove(move, progress.Msg)
+			continue
+		}
+
+		m.completeReplicaMove(move)
+	}
+}
+
+// completeReplicaMove removes the replica on the source node after the new replica is pulled
+func (m *Manager) completeReplicaMove(move *types.ReplicaMoveRecord) {
+	if _, err := m.LoadAssetRecord(move.Hash); err != nil {
+		if err == sql.ErrNoRows {
+			m.finishReplicaMove(move, types.ReplicaStatusFailed, "asset removed")
+		}
+		return
+	}
+
+	if err := m.addAssetToView(move.ToNodeID, move.CID); err != nil {
+		log.Errorf("completeReplicaMove %s addAssetToView err:%s", move.ToNodeID, err.Error())
+	}
+
+	if err := m.removeAssetFromView(move.FromNodeID, move.CID); err != nil {
+		log.Errorf("completeReplicaMove %s removeAssetFromView err:%s", move.FromNodeID, err.Error())
+	}
+
+	if err := m.RemoveReplica(move.CID, move.Hash, move.FromNodeID); err != nil {
+		m.finishReplicaMove(move, types.ReplicaStatusFailed, err.Error())
+		return
+	}
+
+	m.finishReplicaMove(move, types.ReplicaStatusSucceeded, "")
+}
+
+// failReplicaMove removes the new replica of a failed move, the replica on the source node is kept
+func (m *Manager) failReplicaMove(move *types.ReplicaMoveRecord, msg string) {
+	if err := m.DeleteAssetReplica(move.Hash, move.ToNodeID); err != nil {
+		log.Errorf("failReplicaMove %s DeleteAssetReplica err:%s", move.ToNodeID, err.Error())
+	}
+
+	m.markReplicaRemoved(move.Hash, move.ToNodeID)
+	go m.requestAssetDeletion(move.ToNodeID, move.CID)
+
+	m.finishReplicaMove(move, types.ReplicaStatusFailed, msg)
+}
+
+// finishReplicaMove records the result of the move
+func (m *Manager) finishReplicaMove(move *types.ReplicaMoveRecord, status types.ReplicaStatus, msg string) {
+	m.moveLock.Lock()
+	delete(m.moves, move.Hash)
+	m.moveLock.Unlock()
+
+	log.Infof("asset event %s, move replica %s -> %s %s %s", move.CID, move.FromNodeID, move.ToNodeID, status.String(), msg)
+
+	if err := m.UpdateReplicaMoveRecord(move.ID, status, msg); err != nil {
+		log.Errorf("UpdateReplicaMoveRecord %s err:%s", move.ID, err.Error())
+	}
+}
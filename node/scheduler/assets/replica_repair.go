@@ -0,0 +1,115 @@
+package assets
+
+import (
+	"context"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+)
+
+// replicaRepairCheck Periodically checks the servicing assets that lack replicas and repairs them
+func (m *Manager) replicaRepairCheck(ctx context.Context) {
+	ticker := time.NewTicker(replicaRepairInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.repairAssetReplicas()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// repairAssetReplicas sends the replica repair event to the assets whose succeeded replicas are fewer than required,
+// the number of repairs is limited by the free pulling slots
+func (m *Manager) repairAssetReplicas() {
+	m.lock.Lock()
+	limit := maxConcurrentPulls - len(m.apTickers)
+	m.lock.Unlock()
+
+	if limit > maxReplicaRepairsPerRound {
+		limit = maxReplicaRepairsPerRound
+	}
+
+	if limit <= 0 {
+		return
+	}
+
+	records, err := m.LoadAssetRecordsLackingReplicas(Servicing.String(), m.nodeMgr.ServerID, limit)
+	if err != nil {
+		log.Errorf("LoadAssetRecordsLackingReplicas err:%s", err.Error())
+		return
+	}
+
+	for _, record := range records {
+		if err := m.repairReplicas(record, nil); err != nil {
+			log.Errorf("repair asset %s replicas err:%s", record.CID, err.Error())
+		}
+	}
+}
+
+// repairReplicas restarts the pulling of an asset to re-create its lost replicas,
+// the replicas on the exclude nodes are re-created as if they were lost
+func (m *Manager) repairReplicas(record *types.AssetRecord, excludes map[string]struct{}) error {
+	replicaInfos, err := m.LoadAssetReplicas(record.Hash)
+	if err != nil {
+		return err
+	}
+
+	evt := ReplicaRepair{
+		ID:                record.CID,
+		Hash:              AssetHash(record.Hash),
+		Replicas:          record.NeedEdgeReplica,
+		ServerID:          string(record.ServerID),
+		CreatedAt:         record.CreateTime.Unix(),
+		Expiration:        record.Expiration.Unix(),
+		CandidateReplicas: record.NeedCandidateReplicas,
+		Size:              record.TotalSize,
+		Blocks:            record.TotalBlocks,
+		Areas:             splitAreas(record.Areas),
+		MinEdgeReplicas:   record.MinEdgeReplicas,
+		MaxEdgeReplicas:   record.MaxEdgeReplicas,
+		IncludeLabels:     splitLabels(record.IncludeLabels),
+		ExcludeLabels:     splitLabels(record.ExcludeLabels),
+		Policy:            recordPullPolicy(record),
+		DataShards:        record.DataShards,
+		ParityShards:      record.ParityShards,
+	}
+
+	if record.DataShards > 0 {
+		// the edges of an erasure coded asset hold the shards
+		shardReplicas, err := m.LoadShardReplicas(record.Hash)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range shardReplicas {
+			if !r.IsCandidate {
+				replicaInfos = append(replicaInfos, r)
+			}
+		}
+	}
+
+	for _, r := range replicaInfos {
+		if r.Status != types.ReplicaStatusSucceeded {
+			continue
+		}
+
+		if _, exclude := excludes[r.NodeID]; exclude {
+			continue
+		}
+
+		if r.IsCandidate {
+			evt.CandidateReplicaSucceeds = append(evt.CandidateReplicaSucceeds, r.NodeID)
+		} else {
+			evt.EdgeReplicaSucceeds = append(evt.EdgeReplicaSucceeds, r.NodeID)
+		}
+	}
+
+	log.Infof("asset event %s, repair replicas, edge: %d/%d, candidate: %d/%d", record.CID, len(evt.EdgeReplicaSucceeds), evt.Replicas,
+		len(evt.CandidateReplicaSucceeds), evt.CandidateReplicas)
+
+	return m.assetStateMachines.Send(AssetHash(record.Hash), evt)
+}
@@ -0,0 +1,203 @@
+package assets
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// assetDemand is the download demand of an asset within a scaling window
+type assetDemand struct {
+	requests  int64 // number of download info requests
+	downloads int64 // number of submitted user proofs of work
+	size      int64 // downloaded bytes of the user proofs of work
+}
+
+// value returns the demand value used for scaling, the user proofs of work confirm the downloads of the requests.
+// The proofs are submitted by the clients without verification, so they count up to the requests counted by the scheduler
+func (d *assetDemand) value() int64 {
+	downloads := d.downloads
+	if downloads > d.requests {
+		downloads = d.requests
+	}
+
+	return d.requests + downloads
+}
+
+// RecordDownloadRequest records a download info request of the asset
+func (m *Manager) RecordDownloadRequest(hash string) {
+	m.demandLock.Lock()
+	defer m.demandLock.Unlock()
+
+	d, ok := m.demands[hash]
+	if !ok {
+		d = &assetDemand{}
+		m.demands[hash] = d
+	}
+
+	d.requests++
+}
+
+// RecordDownload records a user download of the asset
+func (m *Manager) RecordDownload(hash string, size int64) {
+	m.demandLock.Lock()
+	defer m.demandLock.Unlock()
+
+	d, ok := m.demands[hash]
+	if !ok {
+		d = &assetDemand{}
+		m.demands[hash] = d
+	}
+
+	d.downloads++
+	d.size += size
+}
+
+// replicaScalingCheck Periodically scales the edge replicas of assets by their download demand
+func (m *Manager) replicaScalingCheck(ctx context.Context) {
+	ticker := time.NewTicker(replicaScalingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.scaleAssetReplicas()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// scaleAssetReplicas grows the edge replicas of hot assets and shrinks the edge replicas of cold assets
+func (m *Manager) scaleAssetReplicas() {
+	m.demandLock.Lock()
+	demands := m.demands
+	m.demands = make(map[string]*assetDemand)
+	m.demandLock.Unlock()
+
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get schedulerConfig err:%s", err.Error())
+		return
+	}
+
+	if cfg.ReplicaScalingDemand <= 0 {
+		return
+	}
+
+	for hash, demand := range demands {
+		record, err := m.LoadAssetRecord(hash)
+		if err != nil {
+			continue
+		}
+
+		if record.MaxEdgeReplicas <= 0 || record.State != Servicing.String() {
+			continue
+		}
+
+		replicas := int64(math.Ceil(float64(demand.value()) / float64(cfg.ReplicaScalingDemand)))
+		m.scaleReplicas(record, replicas, demand)
+	}
+
+	// assets without demand shrink to their minimum
+	records, err := m.LoadScalableAssetRecords(Servicing.String(), m.nodeMgr.ServerID, loadColdAssetsLimit)
+	if err != nil {
+		log.Errorf("LoadScalableAssetRecords err:%s", err.Error())
+		return
+	}
+
+	for _, record := range records {
+		if _, ok := demands[record.Hash]; ok {
+			continue
+		}
+
+		m.scaleReplicas(record, record.MinEdgeReplicas, &assetDemand{})
+	}
+}
+
+// scaleReplicas scales the edge replicas of the asset to the target within its bounds,
+// the replicas shrink one by one to avoid flapping
+func (m *Manager) scaleReplicas(record *types.AssetRecord, target int64, demand *assetDemand) {
+	if target < record.MinEdgeReplicas {
+		target = record.MinEdgeReplicas
+	}
+
+	if target > record.MaxEdgeReplicas {
+		target = record.MaxEdgeReplicas
+	}
+
+	if target < record.NeedEdgeReplica {
+		target = record.NeedEdgeReplica - 1
+	}
+
+	if target == record.NeedEdgeReplica {
+		return
+	}
+
+	var err error
+	if target > record.NeedEdgeReplica {
+		err = m.growReplicas(record, target)
+	} else {
+		err = m.shrinkReplicas(record, target)
+	}
+
+	scalingRecord := &types.ReplicaScalingRecord{
+		Hash:         record.Hash,
+		CID:          record.CID,
+		FromReplicas: record.NeedEdgeReplica,
+		ToReplicas:   target,
+		Requests:     demand.requests,
+		Downloads:    demand.downloads,
+		DownloadSize: demand.size,
+	}
+	if err != nil {
+		scalingRecord.Msg = err.Error()
+	}
+
+	log.Infof("asset event %s, scale edge replicas %d -> %d, demand: %d, err: %v", record.CID, record.NeedEdgeReplica, target, demand.value(), err)
+
+	if err = m.SaveReplicaScalingRecord(scalingRecord); err != nil {
+		log.Errorf("SaveReplicaScalingRecord %s err:%s", record.CID, err.Error())
+	}
+}
+
+// growReplicas replenishes the edge replicas of the asset
+func (m *Manager) growReplicas(record *types.AssetRecord, replicas int64) error {
+	m.lock.Lock()
+	pulls := len(m.apTickers)
+	m.lock.Unlock()
+
+	if pulls >= maxConcurrentPulls {
+		return xerrors.Errorf("The asset in the pulling exceeds the limit %d", maxConcurrentPulls)
+	}
+
+	return m.replenishAssetReplicas(record, scalingPullAssetReq(record, replicas))
+}
+
+// shrinkReplicas reduces the edge replicas of the asset, the surplus replicas are removed by the state machine
+func (m *Manager) shrinkReplicas(record *types.AssetRecord, replicas int64) error {
+	rInfo, err := m.newReplenishReplicas(record, scalingPullAssetReq(record, replicas), EdgesShrink)
+	if err != nil {
+		return err
+	}
+
+	return m.assetStateMachines.Send(AssetHash(record.Hash), rInfo)
+}
+
+// scalingPullAssetReq returns the pull request of the asset record with the scaled edge replicas
+func scalingPullAssetReq(record *types.AssetRecord, replicas int64) *types.PullAssetReq {
+	return &types.PullAssetReq{
+		CID:         record.CID,
+		Hash:        record.Hash,
+		Replicas:    replicas,
+		ServerID:    string(record.ServerID),
+		Expiration:  record.Expiration,
+		Areas:       splitAreas(record.Areas),
+		MinReplicas: record.MinEdgeReplicas,
+		MaxReplicas: record.MaxEdgeReplicas,
+		Labels:      recordLabelSelector(record),
+	}
+}
@@ -0,0 +1,92 @@
+package assets
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+	"github.com/linguohua/titan/node/cidutil"
+	"golang.org/x/xerrors"
+)
+
+// seedTaskExpiration is the time to wait for the result of a url pull, the later result is dropped
+const seedTaskExpiration = 24 * time.Hour
+
+// addSeedTask saves the seed task of the asset being put on the seed candidate outside of the pulling, e.g. built from its origin url or uploaded,
+// the task is kept in the db so that the result reported after a restart of the scheduler is not lost, the seed tasks are dropped after the expiration
+func (m *Manager) addSeedTask(id, nodeID string, req *types.PullAssetReq, expiration time.Time) error {
+	if err := m.DeleteSeedTasksBefore(time.Now()); err != nil {
+		log.Errorf("delete expired seed tasks err:%s", err.Error())
+	}
+
+	return m.SaveSeedTask(&types.SeedTask{ID: id, NodeID: nodeID, Request: req, StartTime: time.Now(), Expiration: expiration, Status: types.ReplicaStatusPulling})
+}
+
+// loadPendingSeedTask loads the seed task of the node waiting for its result
+func (m *Manager) loadPendingSeedTask(id, nodeID string) (*types.SeedTask, error) {
+	task, err := m.LoadSeedTask(id)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, xerrors.Errorf("load seed task %s err:%s", id, err.Error())
+	}
+
+	if task == nil || task.NodeID != nodeID || task.Status != types.ReplicaStatusPulling || time.Now().After(task.Expiration) {
+		return nil, xerrors.Errorf("seed task %s of node %s not found", id, nodeID)
+	}
+
+	return task, nil
+}
+
+// removeSeedTask removes the seed task
+func (m *Manager) removeSeedTask(id string) {
+	if _, err := m.DeleteSeedTask(id); err != nil {
+		log.Errorf("delete seed task %s err:%s", id, err.Error())
+	}
+}
+
+// failSeedTask records the error that the seed candidate reported for the seed task
+func (m *Manager) failSeedTask(id, nodeID, msg string) error {
+	if _, err := m.loadPendingSeedTask(id, nodeID); err != nil {
+		return err
+	}
+
+	if ok, err := m.UpdateSeedTaskResult(id, types.ReplicaStatusPulling, types.ReplicaStatusFailed, "", msg); err != nil {
+		return xerrors.Errorf("update seed task %s err:%s", id, err.Error())
+	} else if !ok {
+		return xerrors.Errorf("seed task %s of node %s not found", id, nodeID)
+	}
+
+	return nil
+}
+
+// finishSeedTask creates the asset pull task of the root cid that the seed candidate reported
+func (m *Manager) finishSeedTask(id, nodeID, root string) error {
+	task, err := m.loadPendingSeedTask(id, nodeID)
+	if err != nil {
+		return err
+	}
+
+	hash, err := cidutil.CIDToHash(root)
+	if err != nil {
+		return xerrors.Errorf("%s cid to hash err:%s", root, err.Error())
+	}
+
+	// the task is finished by the report that updates its result
+	if ok, err := m.UpdateSeedTaskResult(id, types.ReplicaStatusPulling, types.ReplicaStatusSucceeded, root, ""); err != nil {
+		return xerrors.Errorf("update seed task %s err:%s", id, err.Error())
+	} else if !ok {
+		return xerrors.Errorf("seed task %s of node %s not found", id, nodeID)
+	}
+
+	info := *task.Request
+	info.CID = root
+	info.Hash = hash
+
+	if err = m.CreateSeededAssetPullTask(&info, nodeID); err != nil {
+		if _, e := m.UpdateSeedTaskResult(id, types.ReplicaStatusSucceeded, types.ReplicaStatusFailed, root, err.Error()); e != nil {
+			log.Errorf("update seed task %s err:%s", id, e.Error())
+		}
+		return err
+	}
+
+	return nil
+}
@@ -7,6 +7,8 @@ type AssetState string
 const (
 	// UndefinedState represents an undefined state.
 	UndefinedState AssetState = ""
+	// Queued waiting for a free pulling slot
+	Queued AssetState = "Queued"
 	// SeedSelect select first candidate to pull seed asset
 	SeedSelect AssetState = "SeedSelect"
 	// SeedPulling Waiting for candidate nodes to pull seed asset
@@ -17,8 +19,12 @@ const (
 	CandidatesPulling AssetState = "CandidatesPulling"
 	// EdgesSelect select edges to pull asset
 	EdgesSelect AssetState = "EdgesSelect"
+	// ShardsEncoding waiting for a candidate to encode the erasure coded asset into shards
+	ShardsEncoding AssetState = "ShardsEncoding"
 	// EdgesPulling edge nodes pulling asset
 	EdgesPulling AssetState = "EdgesPulling"
+	// EdgesShrink removing the surplus edge replicas of an asset scaled down
+	EdgesShrink AssetState = "EdgesShrink"
 	// Servicing Asset cache completed and in service
 	Servicing AssetState = "Servicing"
 	// SeedFailed Unable to select candidate nodes or failed to pull seed asset
@@ -29,6 +35,10 @@ const (
 	EdgesFailed AssetState = "EdgesFailed"
 	// Remove remove
 	Remove AssetState = "Remove"
+	// PendingDelete removed or expired, the replicas are kept but not served until the grace period ends
+	PendingDelete AssetState = "PendingDelete"
+	// Paused the pulling is frozen until the asset is resumed
+	Paused AssetState = "Paused"
 )
 
 // String returns the string representation of the AssetState.
@@ -51,6 +61,24 @@ var (
 		CandidatesSelect.String(),
 		CandidatesPulling.String(),
 		EdgesSelect.String(),
+		ShardsEncoding.String(),
 		EdgesPulling.String(),
 	}
+
+	// PausableStates contains a list of asset states that can be paused.
+	PausableStates = append(append([]string{Queued.String()}, FailedStates...), PullingStates...)
+
+	// pulledStates contains the pulling states that wait for the pull results of the nodes.
+	pulledStates = []AssetState{SeedPulling, CandidatesPulling, ShardsEncoding, EdgesPulling}
 )
+
+// isPulledState checks if the asset in the state waits for the pull results of the nodes
+func isPulledState(state AssetState) bool {
+	for _, s := range pulledStates {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
@@ -10,7 +10,11 @@ import (
 
 // Plan prepares a plan for asset pulling
 func (m *Manager) Plan(events []statemachine.Event, user interface{}) (interface{}, uint64, error) {
-	next, processed, err := m.plan(events, user.(*AssetPullingInfo))
+	info := user.(*AssetPullingInfo)
+	from := info.State
+
+	next, processed, err := m.plan(events, info)
+	m.recordAssetEvents(events[:processed], from, info, err)
 	if err != nil || next == nil {
 		return nil, processed, nil
 	}
@@ -30,37 +34,63 @@ func (m *Manager) Plan(events []statemachine.Event, user interface{}) (interface
 var planners = map[AssetState]func(events []statemachine.Event, state *AssetPullingInfo) (uint64, error){
 	// external import
 	UndefinedState: planOne(
-		on(AssetStartPulls{}, SeedSelect),
+		on(AssetStartPulls{}, Queued),
+		on(ReplicaRepair{}, SeedSelect), // servicing asset that is not loaded in the state machine
+		on(AssetRestore{}, SeedSelect),  // pending delete asset that is not loaded in the state machine
 	),
+	Queued: planIgnoring(planOne(
+		on(PullAssetDequeue{}, SeedSelect),
+	), PullAssetRequeue{}, PulledResult{}, ReplicasReplaced{}, PullFailed{}), // the results of the pulls before the requeue
 	SeedSelect: planOne(
 		on(PullRequestSent{}, SeedPulling),
+		on(PullAssetRequeue{}, Queued),
 		on(SelectFailed{}, SeedFailed),
 		on(SkipStep{}, CandidatesSelect),
 	),
 	SeedPulling: planOne(
 		on(PullSucceed{}, CandidatesSelect),
 		on(PullFailed{}, SeedFailed),
+		on(PullAssetRequeue{}, Queued),
 		apply(PulledResult{}),
+		apply(ReplicasReplaced{}),
 	),
 	CandidatesSelect: planOne(
 		on(PullRequestSent{}, CandidatesPulling),
 		on(SkipStep{}, EdgesSelect),
 		on(SelectFailed{}, CandidatesFailed),
+		on(PullAssetRequeue{}, Queued),
 	),
 	CandidatesPulling: planOne(
 		on(PullFailed{}, CandidatesFailed),
 		on(PullSucceed{}, EdgesSelect),
+		on(PullAssetRequeue{}, Queued),
 		apply(PulledResult{}),
+		apply(ReplicasReplaced{}),
 	),
 	EdgesSelect: planOne(
 		on(PullRequestSent{}, EdgesPulling),
+		on(ShardsEncodeRequestSent{}, ShardsEncoding),
 		on(SelectFailed{}, EdgesFailed),
 		on(SkipStep{}, Servicing),
+		on(PullAssetRequeue{}, Queued),
+	),
+	ShardsEncoding: planOne(
+		on(ShardsEncoded{}, EdgesSelect),
+		on(PullFailed{}, EdgesFailed),
+		on(PullAssetRequeue{}, Queued),
 	),
 	EdgesPulling: planOne(
 		on(PullFailed{}, EdgesFailed),
 		on(PullSucceed{}, Servicing),
+		on(PullAssetRequeue{}, Queued),
 		apply(PulledResult{}),
+		apply(ReplicasReplaced{}),
+	),
+	EdgesShrink: planOne(
+		on(ReplicasShrunk{}, Servicing),
+	),
+	Servicing: planOne(
+		on(ReplicaRepair{}, SeedSelect),
 	),
 	SeedFailed: planOne(
 		on(AssetRePull{}, SeedSelect),
@@ -72,8 +102,49 @@ var planners = map[AssetState]func(events []statemachine.Event, state *AssetPull
 		on(AssetRePull{}, EdgesSelect),
 	),
 	Remove: planOne(
-		on(AssetStartPulls{}, SeedSelect),
+		on(AssetStartPulls{}, Queued),
 	),
+	PendingDelete: planIgnoring(planOne(
+		on(AssetRestore{}, SeedSelect),
+	), pipelineEvents...), // the asset can be soft deleted while a handler is in flight
+}
+
+// pipelineEvents are sent by the state handlers and the node pull results, they are stale once the asset leaves the pulling
+var pipelineEvents = []mutator{
+	PullAssetDequeue{}, PullRequestSent{}, SelectFailed{}, SkipStep{}, PullSucceed{}, PullFailed{},
+	PulledResult{}, ReplicasReplaced{}, AssetRePull{}, ShardsEncodeRequestSent{}, ShardsEncoded{}, ReplicasShrunk{},
+	PullAssetRequeue{},
+}
+
+func init() {
+	// the paused asset is planned with the planner of the state it is paused from
+	planners[Paused] = planPaused
+}
+
+// planPaused plans the events of a paused asset, e.g. the retry after the failed cool-down or the events sent by the handler in flight,
+// with the planner of the state it is paused from. The asset stays paused in the state they lead to, and is resumed to it by the global event
+func planPaused(events []statemachine.Event, state *AssetPullingInfo) (uint64, error) {
+	for i, event := range events {
+		if gm, ok := event.User.(globalMutator); ok {
+			gm.applyGlobal(state)
+			return uint64(i + 1), nil
+		}
+
+		p := planners[state.PausedFrom]
+		if p == nil {
+			return uint64(i + 1), xerrors.Errorf("planner for paused state %s not found", state.PausedFrom)
+		}
+
+		state.State = state.PausedFrom
+		_, err := p(events[i:i+1], state)
+		state.PausedFrom = state.State
+		state.State = Paused
+		if err != nil {
+			return uint64(i + 1), err
+		}
+	}
+
+	return uint64(len(events)), nil
 }
 
 // plan creates a plan for the next asset pulling action based on the given events and asset state
@@ -100,6 +171,8 @@ func (m *Manager) plan(events []statemachine.Event, state *AssetPullingInfo) (fu
 
 	switch state.State {
 	// Happy path
+	case Queued:
+		return m.handleQueued, processed, nil
 	case SeedSelect:
 		return m.handleSeedSelect, processed, nil
 	case SeedPulling:
@@ -110,13 +183,19 @@ func (m *Manager) plan(events []statemachine.Event, state *AssetPullingInfo) (fu
 		return m.handleEdgesSelect, processed, nil
 	case CandidatesPulling:
 		return m.handleCandidatesPulling, processed, nil
+	case ShardsEncoding:
+		return m.handleShardsEncoding, processed, nil
 	case EdgesPulling:
 		return m.handleEdgesPulling, processed, nil
+	case EdgesShrink:
+		return m.handleEdgesShrink, processed, nil
 	case Servicing:
 		return m.handleServicing, processed, nil
 	case SeedFailed, CandidatesFailed, EdgesFailed:
 		return m.handlePullsFailed, processed, nil
-	case Remove:
+	case PendingDelete:
+		return m.handlePendingDelete, processed, nil
+	case Remove, Paused:
 		return nil, processed, nil
 	// Fatal errors
 	case UndefinedState:
@@ -170,6 +249,33 @@ func planOne(ts ...func() (mut mutator, next func(info *AssetPullingInfo) (more
 	}
 }
 
+// planIgnoring drops the stale events without applying them, the other events are planned with the planner
+func planIgnoring(p func(events []statemachine.Event, state *AssetPullingInfo) (uint64, error), stale ...mutator) func(events []statemachine.Event, state *AssetPullingInfo) (uint64, error) {
+	return func(events []statemachine.Event, state *AssetPullingInfo) (uint64, error) {
+		for i, event := range events {
+			if !isEventOf(event.User, stale) {
+				processed, err := p(events[i:], state)
+				return uint64(i) + processed, err
+			}
+
+			log.Debugf("asset %s drops stale event %T in state %s", state.CID, event.User, state.State)
+		}
+
+		return uint64(len(events)), nil
+	}
+}
+
+// isEventOf checks if the event is of the type of any mutator
+func isEventOf(event interface{}, muts []mutator) bool {
+	for _, mut := range muts {
+		if reflect.TypeOf(event) == reflect.TypeOf(mut) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // on is a utility function to handle state transitions
 func on(mut mutator, next AssetState) func() (mutator, func(*AssetPullingInfo) (bool, error)) {
 	return func() (mutator, func(*AssetPullingInfo) (bool, error)) {
@@ -199,12 +305,23 @@ func (m *Manager) restartStateMachines(ctx context.Context) error {
 	}
 
 	for _, asset := range list {
+		if asset.State == Paused {
+			// the paused asset does not take a pulling slot
+			continue
+		}
+
 		if err := m.assetStateMachines.Send(asset.Hash, PullAssetRestart{}); err != nil {
 			log.Errorf("restartStateMachines asset send %s , err %s", asset.CID, err.Error())
 			continue
 		}
 
-		m.addOrResetAssetTicker(asset.Hash.String())
+		// the queued asset takes a pulling slot when it is dequeued, the select states take a slot before the pull requests are sent,
+		// the pulling asset that gets no slot goes back to the queue
+		if isPulledState(asset.State) && !m.addOrResetAssetTicker(asset.Hash.String(), asset.pullTimeout()) {
+			if err := m.assetStateMachines.Send(asset.Hash, PullAssetRequeue{}); err != nil {
+				log.Errorf("restartStateMachines asset requeue %s , err %s", asset.CID, err.Error())
+			}
+		}
 	}
 
 	return nil
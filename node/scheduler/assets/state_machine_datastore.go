@@ -66,7 +66,7 @@ func (d *Datastore) Query(ctx context.Context, q query.Query) (query.Results, er
 	var rows *sqlx.Rows
 	var err error
 
-	state := append(FailedStates, PullingStates...)
+	state := append(append([]string{Queued.String(), Paused.String(), EdgesShrink.String()}, FailedStates...), PullingStates...)
 
 	rows, err = d.assetDB.LoadAssetRecords(state, q.Limit, q.Offset, d.ServerID)
 	if err != nil {
@@ -28,6 +28,7 @@ type PullAssetRestart struct{}
 
 func (evt PullAssetRestart) applyGlobal(state *AssetPullingInfo) bool {
 	state.RetryCount = 0
+	state.ReplacedReplicas = 0
 	return false
 }
 
@@ -60,6 +61,53 @@ func (evt AssetRemove) applyGlobal(state *AssetPullingInfo) bool {
 	return true
 }
 
+// AssetPendingDelete moves an asset to the pending delete state, the replicas are kept until the asset is purged
+type AssetPendingDelete struct {
+	Record *types.AssetRecord
+}
+
+func (evt AssetPendingDelete) applyGlobal(state *AssetPullingInfo) bool {
+	*state = *assetPullingInfoFrom(evt.Record)
+	state.State = PendingDelete
+	state.PausedFrom = ""
+	return true
+}
+
+// AssetPause freezes the asset pulling, the current state is kept to be resumed
+type AssetPause struct{}
+
+func (evt AssetPause) applyGlobal(state *AssetPullingInfo) bool {
+	if state.State == Paused {
+		return true
+	}
+
+	state.PausedFrom = state.State
+	state.State = Paused
+	return true
+}
+
+// AssetResume returns a paused asset to the state before it was paused
+type AssetResume struct{}
+
+func (evt AssetResume) applyGlobal(state *AssetPullingInfo) bool {
+	if state.State != Paused {
+		return true
+	}
+
+	state.State = state.PausedFrom
+	state.PausedFrom = ""
+	return true
+}
+
+// AssetRestore restores a pending delete asset, the lost replicas are pulled again
+type AssetRestore struct {
+	Record *types.AssetRecord
+}
+
+func (evt AssetRestore) apply(state *AssetPullingInfo) {
+	*state = *assetPullingInfoFrom(evt.Record)
+}
+
 // InfoUpdate update asset info
 type InfoUpdate struct {
 	Size   int64
@@ -107,6 +155,15 @@ func (evt PulledResult) apply(state *AssetPullingInfo) {
 	}
 }
 
+// ReplicasReplaced indicates that the failed replicas are replaced by other nodes
+type ReplicasReplaced struct {
+	Count int64
+}
+
+func (evt ReplicasReplaced) apply(state *AssetPullingInfo) {
+	state.ReplacedReplicas += evt.Count
+}
+
 // PullRequestSent indicates that a pull request has been sent
 type PullRequestSent struct{}
 
@@ -126,6 +183,17 @@ type AssetStartPulls struct {
 	CreatedAt         int64
 	Expiration        int64
 	CandidateReplicas int // Number of candidate node replicas
+	Areas             []string
+	MinEdgeReplicas   int64
+	MaxEdgeReplicas   int64
+	Priority          int64
+	GroupID           string
+	IncludeLabels     []string
+	ExcludeLabels     []string
+	Policy            pullPolicy
+	SeedNodeID        string // the candidate that already holds the asset
+	DataShards        int64  // the asset is erasure coded on the edges if it is not 0
+	ParityShards      int64
 }
 
 func (evt AssetStartPulls) apply(state *AssetPullingInfo) {
@@ -136,8 +204,29 @@ func (evt AssetStartPulls) apply(state *AssetPullingInfo) {
 	state.CreatedAt = evt.CreatedAt
 	state.Expiration = evt.Expiration
 	state.CandidateReplicas = int64(seedReplicaCount + evt.CandidateReplicas)
+	state.Areas = evt.Areas
+	state.MinEdgeReplicas = evt.MinEdgeReplicas
+	state.MaxEdgeReplicas = evt.MaxEdgeReplicas
+	state.Priority = evt.Priority
+	state.GroupID = evt.GroupID
+	state.IncludeLabels = evt.IncludeLabels
+	state.ExcludeLabels = evt.ExcludeLabels
+	state.setPullPolicy(evt.Policy)
+	state.SeedNodeID = evt.SeedNodeID
+	state.DataShards = evt.DataShards
+	state.ParityShards = evt.ParityShards
 }
 
+// PullAssetDequeue starts the pulling of a queued asset
+type PullAssetDequeue struct{}
+
+func (evt PullAssetDequeue) apply(state *AssetPullingInfo) {}
+
+// PullAssetRequeue puts the asset back to the queue when no pulling slot is free
+type PullAssetRequeue struct{}
+
+func (evt PullAssetRequeue) apply(state *AssetPullingInfo) {}
+
 // ReplenishReplicas replenish asset replicas
 type ReplenishReplicas struct {
 	ID                       string
@@ -152,6 +241,12 @@ type ReplenishReplicas struct {
 	Blocks                   int64
 	EdgeReplicaSucceeds      []string
 	CandidateReplicaSucceeds []string
+	Areas                    []string
+	MinEdgeReplicas          int64
+	MaxEdgeReplicas          int64
+	IncludeLabels            []string
+	ExcludeLabels            []string
+	Policy                   pullPolicy
 }
 
 func (evt ReplenishReplicas) applyGlobal(state *AssetPullingInfo) bool {
@@ -167,19 +262,96 @@ func (evt ReplenishReplicas) applyGlobal(state *AssetPullingInfo) bool {
 	state.Blocks = evt.Blocks
 	state.EdgeReplicaSucceeds = evt.EdgeReplicaSucceeds
 	state.CandidateReplicaSucceeds = evt.CandidateReplicaSucceeds
+	state.Areas = evt.Areas
+	state.MinEdgeReplicas = evt.MinEdgeReplicas
+	state.MaxEdgeReplicas = evt.MaxEdgeReplicas
+	state.IncludeLabels = evt.IncludeLabels
+	state.ExcludeLabels = evt.ExcludeLabels
+	state.setPullPolicy(evt.Policy)
 	return true
 }
 
+// ReplicaRepair repairs the replicas of a servicing asset that has lost some of them
+type ReplicaRepair struct {
+	ID                       string
+	Hash                     AssetHash
+	Replicas                 int64
+	ServerID                 string
+	CreatedAt                int64
+	Expiration               int64
+	CandidateReplicas        int64
+	Size                     int64
+	Blocks                   int64
+	EdgeReplicaSucceeds      []string
+	CandidateReplicaSucceeds []string
+	Areas                    []string
+	MinEdgeReplicas          int64
+	MaxEdgeReplicas          int64
+	IncludeLabels            []string
+	ExcludeLabels            []string
+	Policy                   pullPolicy
+	DataShards               int64
+	ParityShards             int64
+}
+
+func (evt ReplicaRepair) apply(state *AssetPullingInfo) {
+	state.CID = evt.ID
+	state.Hash = evt.Hash
+	state.EdgeReplicas = evt.Replicas
+	state.ServerID = evt.ServerID
+	state.CreatedAt = evt.CreatedAt
+	state.Expiration = evt.Expiration
+	state.CandidateReplicas = evt.CandidateReplicas
+	state.Size = evt.Size
+	state.Blocks = evt.Blocks
+	state.EdgeReplicaSucceeds = evt.EdgeReplicaSucceeds
+	state.CandidateReplicaSucceeds = evt.CandidateReplicaSucceeds
+	state.Areas = evt.Areas
+	state.MinEdgeReplicas = evt.MinEdgeReplicas
+	state.MaxEdgeReplicas = evt.MaxEdgeReplicas
+	state.IncludeLabels = evt.IncludeLabels
+	state.ExcludeLabels = evt.ExcludeLabels
+	state.setPullPolicy(evt.Policy)
+	state.DataShards = evt.DataShards
+	state.ParityShards = evt.ParityShards
+	state.RetryCount = 0
+}
+
 // AssetRePull re-pull the asset
 type AssetRePull struct{}
 
-func (evt AssetRePull) apply(state *AssetPullingInfo) {}
+func (evt AssetRePull) apply(state *AssetPullingInfo) {
+	state.ReplacedReplicas = 0
+}
 
 // PullSucceed indicates that a node has successfully pulled an asset
 type PullSucceed struct{}
 
 func (evt PullSucceed) apply(state *AssetPullingInfo) {
 	state.RetryCount = 0
+	state.ReplacedReplicas = 0
+}
+
+// ShardsEncodeRequestSent indicates that a candidate is requested to encode the asset into shards
+type ShardsEncodeRequestSent struct{}
+
+func (evt ShardsEncodeRequestSent) apply(state *AssetPullingInfo) {}
+
+// ShardsEncoded indicates that the shards of the asset are saved
+type ShardsEncoded struct{}
+
+func (evt ShardsEncoded) apply(state *AssetPullingInfo) {}
+
+// Ignore the shards may be reported again after the asset has left the encoding state
+func (evt ShardsEncoded) Ignore() {}
+
+// ReplicasShrunk indicates that the surplus edge replicas of the asset scaled down are removed
+type ReplicasShrunk struct{}
+
+func (evt ReplicasShrunk) apply(state *AssetPullingInfo) {
+	if int64(len(state.EdgeReplicaSucceeds)) > state.EdgeReplicas {
+		state.EdgeReplicaSucceeds = state.EdgeReplicaSucceeds[:state.EdgeReplicas]
+	}
 }
 
 // SkipStep skips the current step
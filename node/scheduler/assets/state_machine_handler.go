@@ -4,20 +4,14 @@ import (
 	"time"
 
 	"github.com/filecoin-project/go-statemachine"
+	"github.com/linguohua/titan/api/types"
+	"github.com/linguohua/titan/node/scheduler/node"
 	"golang.org/x/xerrors"
 )
 
-var (
-	// MinRetryTime defines the minimum time duration between retries
-	MinRetryTime = 1 * time.Minute
-
-	// MaxRetryCount defines the maximum number of retries allowed
-	MaxRetryCount = 3
-)
-
 // failedCoolDown is called when a retry needs to be attempted and waits for the specified time duration
 func failedCoolDown(ctx statemachine.Context, info AssetPullingInfo) error {
-	retryStart := time.Now().Add(MinRetryTime)
+	retryStart := time.Now().Add(info.retryInterval())
 	if time.Now().Before(retryStart) {
 		log.Debugf("%s(%s), waiting %s before retrying", info.State, info.Hash, time.Until(retryStart))
 		select {
@@ -30,6 +24,14 @@ func failedCoolDown(ctx statemachine.Context, info AssetPullingInfo) error {
 	return nil
 }
 
+// handleQueued handles the queued asset, it is started by the pull queue when a pulling slot is free
+func (m *Manager) handleQueued(ctx statemachine.Context, info AssetPullingInfo) error {
+	log.Debugf("handle queued: %s", info.CID)
+
+	m.notifyPullQueue()
+	return nil
+}
+
 // handleSeedSelect handles the selection of seed nodes for asset pull
 func (m *Manager) handleSeedSelect(ctx statemachine.Context, info AssetPullingInfo) error {
 	log.Debugf("handle select seed: %s", info.CID)
@@ -39,24 +41,37 @@ func (m *Manager) handleSeedSelect(ctx statemachine.Context, info AssetPullingIn
 		return ctx.Send(SkipStep{})
 	}
 
-	// find nodes
-	nodes := m.chooseCandidateNodesForAssetReplica(seedReplicaCount, info.CandidateReplicaSucceeds)
+	// find nodes, the candidate that already holds the asset is preferred
+	nodes := m.seedNodeWithAsset(info)
+	if len(nodes) < 1 {
+		nodes = m.chooseCandidateNodesForAssetReplica(seedReplicaCount, info.placementRequest(info.CandidateReplicaSucceeds))
+	}
 	if len(nodes) < 1 {
 		return ctx.Send(SelectFailed{error: xerrors.New("node not found")})
 	}
 
+	if !m.addOrResetAssetTicker(info.Hash.String(), info.pullTimeout()) {
+		return ctx.Send(PullAssetRequeue{})
+	}
+
 	// save to db
 	err := m.saveReplicaInformation(nodes, info.Hash.String(), true)
 	if err != nil {
 		return ctx.Send(SelectFailed{error: err})
 	}
 
-	m.addOrResetAssetTicker(info.Hash.String())
+	// the erasure coded asset that no candidate holds is rebuilt from its shards
+	req := m.shardReconstructReq(info)
 
 	// send a cache request to the node
 	go func() {
 		for _, node := range nodes {
-			err := node.PullAsset(ctx.Context(), info.CID, nil)
+			var err error
+			if req != nil {
+				err = node.ReconstructAsset(ctx.Context(), req)
+			} else {
+				err = node.PullAsset(ctx.Context(), info.CID, nil)
+			}
 			if err != nil {
 				log.Errorf("%s pull asset err:%s", node.NodeID, err.Error())
 				continue
@@ -69,6 +84,26 @@ func (m *Manager) handleSeedSelect(ctx statemachine.Context, info AssetPullingIn
 	return ctx.Send(PullRequestSent{})
 }
 
+// seedNodeWithAsset returns the seed candidate that already holds the asset, nil if there is no such candidate online
+func (m *Manager) seedNodeWithAsset(info AssetPullingInfo) map[string]*node.Node {
+	if info.SeedNodeID == "" {
+		return nil
+	}
+
+	for _, nodeID := range append(info.CandidateReplicaSucceeds, info.CandidateReplicaFailures...) {
+		if nodeID == info.SeedNodeID {
+			return nil
+		}
+	}
+
+	cNode := m.nodeMgr.GetCandidateNode(info.SeedNodeID)
+	if cNode == nil {
+		return nil
+	}
+
+	return map[string]*node.Node{cNode.NodeID: cNode}
+}
+
 // handleSeedPulling handles the asset pulling process of seed nodes
 func (m *Manager) handleSeedPulling(ctx statemachine.Context, info AssetPullingInfo) error {
 	log.Debugf("handle seed pulling, %s", info.CID)
@@ -77,11 +112,7 @@ func (m *Manager) handleSeedPulling(ctx statemachine.Context, info AssetPullingI
 		return ctx.Send(PullSucceed{})
 	}
 
-	if len(info.CandidateReplicaSucceeds)+len(info.CandidateReplicaFailures) >= seedReplicaCount {
-		return ctx.Send(PullFailed{error: xerrors.New("node pull failed")})
-	}
-
-	return nil
+	return m.replaceFailedReplicas(ctx, info, true, seedReplicaCount, nil)
 }
 
 // handleCandidatesSelect handles the selection of candidate nodes for asset pull
@@ -95,20 +126,22 @@ func (m *Manager) handleCandidatesSelect(ctx statemachine.Context, info AssetPul
 	}
 
 	// find nodes
-	nodes := m.chooseCandidateNodesForAssetReplica(int(needCount), info.CandidateReplicaSucceeds)
+	nodes := m.chooseCandidateNodesForAssetReplica(int(needCount), info.placementRequest(info.CandidateReplicaSucceeds))
 	if len(nodes) < 1 {
 		return ctx.Send(SelectFailed{error: xerrors.New("node not found")})
 	}
 
+	if !m.addOrResetAssetTicker(info.Hash.String(), info.pullTimeout()) {
+		return ctx.Send(PullAssetRequeue{})
+	}
+
 	// save to db
 	err := m.saveReplicaInformation(nodes, info.Hash.String(), true)
 	if err != nil {
 		return ctx.Send(SelectFailed{error: err})
 	}
 
-	sources := m.getDownloadSources(info.CID, info.CandidateReplicaSucceeds)
-
-	m.addOrResetAssetTicker(info.Hash.String())
+	sources := m.getDownloadSources(info.CID, info.CandidateReplicaSucceeds, nil)
 
 	// send a pull request to the node
 	go func() {
@@ -134,42 +167,52 @@ func (m *Manager) handleCandidatesPulling(ctx statemachine.Context, info AssetPu
 		return ctx.Send(PullSucceed{})
 	}
 
-	if int64(len(info.CandidateReplicaSucceeds)+len(info.CandidateReplicaFailures)) >= info.CandidateReplicas {
-		return ctx.Send(PullFailed{error: xerrors.New("node pull failed")})
+	sources := func() []*types.CandidateDownloadInfo {
+		return m.getDownloadSources(info.CID, info.CandidateReplicaSucceeds, nil)
 	}
-
-	return nil
+	return m.replaceFailedReplicas(ctx, info, true, info.CandidateReplicas, sources)
 }
 
 // handleEdgesSelect handles the selection of edge nodes for asset pull
 func (m *Manager) handleEdgesSelect(ctx statemachine.Context, info AssetPullingInfo) error {
 	log.Debugf("handle edges select , %s", info.CID)
 
+	if info.isErasureCoded() {
+		// the shard pulls and the encoding take the pulling slot of the asset
+		if !m.addOrResetAssetTicker(info.Hash.String(), info.pullTimeout()) {
+			return ctx.Send(PullAssetRequeue{})
+		}
+
+		return m.selectShardEdges(ctx, info)
+	}
+
 	needCount := info.EdgeReplicas - int64(len(info.EdgeReplicaSucceeds))
 	if needCount < 1 {
 		// The number of edge node replicas has reached the requirement
 		return ctx.Send(SkipStep{})
 	}
 
-	sources := m.getDownloadSources(info.CID, info.CandidateReplicaSucceeds)
+	sources := m.getDownloadSources(info.CID, info.CandidateReplicaSucceeds, info.EdgeReplicaSucceeds)
 	if len(sources) < 1 {
 		return ctx.Send(SelectFailed{error: xerrors.New("source node not found")})
 	}
 
 	// find nodes
-	nodes := m.chooseEdgeNodesForAssetReplica(int(needCount), info.EdgeReplicaSucceeds)
+	nodes := m.chooseEdgeNodesForAssetReplica(int(needCount), info.placementRequest(info.EdgeReplicaSucceeds))
 	if len(nodes) < 1 {
 		return ctx.Send(SelectFailed{error: xerrors.New("node not found")})
 	}
 
+	if !m.addOrResetAssetTicker(info.Hash.String(), info.pullTimeout()) {
+		return ctx.Send(PullAssetRequeue{})
+	}
+
 	// save to db
 	err := m.saveReplicaInformation(nodes, info.Hash.String(), false)
 	if err != nil {
 		return ctx.Send(SelectFailed{error: err})
 	}
 
-	m.addOrResetAssetTicker(info.Hash.String())
-
 	// send a pull request to the node
 	go func() {
 		for _, node := range nodes {
@@ -189,15 +232,128 @@ func (m *Manager) handleEdgesSelect(ctx statemachine.Context, info AssetPullingI
 // handleEdgesPulling handles the asset pulling process of edge nodes
 func (m *Manager) handleEdgesPulling(ctx statemachine.Context, info AssetPullingInfo) error {
 	log.Debugf("handle edges pulling, %s", info.CID)
+	if info.isErasureCoded() {
+		return m.replaceFailedShards(ctx, info)
+	}
+
 	if int64(len(info.EdgeReplicaSucceeds)) >= info.EdgeReplicas {
 		return ctx.Send(PullSucceed{})
 	}
 
-	if int64(len(info.EdgeReplicaSucceeds)+len(info.EdgeReplicaFailures)) >= info.EdgeReplicas {
-		return ctx.Send(PullFailed{error: xerrors.New("node pull failed")})
+	sources := func() []*types.CandidateDownloadInfo {
+		return m.getDownloadSources(info.CID, info.CandidateReplicaSucceeds, info.EdgeReplicaSucceeds)
 	}
+	return m.replaceFailedReplicas(ctx, info, false, info.EdgeReplicas, sources)
+}
 
-	return nil
+// replaceFailedReplicas selects other nodes to pull the failed replicas of the pulling phase, the nodes holding a replica
+// or failed to pull it are excluded. The phase fails only if no replicas are pulling and no eligible nodes remain.
+// The nodes pull from the sources, or from the origin if the sources are nil
+func (m *Manager) replaceFailedReplicas(ctx statemachine.Context, info AssetPullingInfo, isCandidate bool, need int64, sources func() []*types.CandidateDownloadInfo) error {
+	failures := info.EdgeReplicaFailures
+	succeeds := info.EdgeReplicaSucceeds
+	if isCandidate {
+		failures = info.CandidateReplicaFailures
+		succeeds = info.CandidateReplicaSucceeds
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	replicaInfos, err := m.LoadAssetReplicas(info.Hash.String())
+	if err != nil {
+		log.Errorf("replaceFailedReplicas %s LoadAssetReplicas err:%s", info.CID, err.Error())
+		return nil
+	}
+
+	pulling := 0
+	filterNodes := make([]string, 0, len(replicaInfos))
+	for _, r := range replicaInfos {
+		filterNodes = append(filterNodes, r.NodeID)
+
+		if r.IsCandidate == isCandidate && (r.Status == types.ReplicaStatusPulling || r.Status == types.ReplicaStatusWaiting) {
+			pulling++
+		}
+	}
+
+	missing := need - int64(len(succeeds)) - int64(pulling)
+	if missing <= 0 {
+		return nil
+	}
+
+	maxReplacements := m.maxReplicaReplacements()
+	if budget := maxReplacements - info.ReplacedReplicas; missing > budget {
+		missing = budget
+	}
+
+	var nodes map[string]*node.Node
+	if missing > 0 {
+		if isCandidate {
+			nodes = m.chooseCandidateNodesForAssetReplica(int(missing), info.placementRequest(filterNodes))
+		} else {
+			nodes = m.chooseEdgeNodesForAssetReplica(int(missing), info.placementRequest(filterNodes))
+		}
+	}
+
+	if len(nodes) == 0 {
+		if pulling > 0 {
+			// wait for the replicas being pulled
+			return nil
+		}
+
+		if missing <= 0 {
+			return ctx.Send(PullFailed{error: xerrors.Errorf("%d replicas failed, %d replacements reached the limit", len(failures), info.ReplacedReplicas)})
+		}
+
+		return ctx.Send(PullFailed{error: xerrors.Errorf("%d replicas failed, no node to replace them", len(failures))})
+	}
+
+	if !m.addOrResetAssetTicker(info.Hash.String(), info.pullTimeout()) {
+		return ctx.Send(PullAssetRequeue{})
+	}
+
+	err = m.saveReplicaInformation(nodes, info.Hash.String(), isCandidate)
+	if err != nil {
+		log.Errorf("replaceFailedReplicas %s saveReplicaInformation err:%s", info.CID, err.Error())
+		return nil
+	}
+
+	var dss []*types.CandidateDownloadInfo
+	if sources != nil {
+		dss = sources()
+	}
+
+	log.Infof("asset event %s, replace %d failed replicas, replaced: %d/%d", info.CID, len(nodes), info.ReplacedReplicas+int64(len(nodes)), maxReplacements)
+
+	// send a pull request to the node
+	go func() {
+		for _, n := range nodes {
+			err := n.PullAsset(ctx.Context(), info.CID, dss)
+			if err != nil {
+				log.Errorf("%s pull asset err:%s", n.NodeID, err.Error())
+				continue
+			}
+
+			n.IncrCurPullingCount(1)
+		}
+	}()
+
+	return ctx.Send(ReplicasReplaced{Count: int64(len(nodes))})
+}
+
+// handleEdgesShrink removes the surplus edge replicas of the asset scaled down
+func (m *Manager) handleEdgesShrink(ctx statemachine.Context, info AssetPullingInfo) error {
+	log.Debugf("handle edges shrink, %s", info.CID)
+
+	for i := int(info.EdgeReplicas); i < len(info.EdgeReplicaSucceeds); i++ {
+		nodeID := info.EdgeReplicaSucceeds[i]
+		if err := m.RemoveReplica(info.CID, info.Hash.String(), nodeID); err != nil {
+			log.Errorf("shrink replicas %s remove replica %s err:%s", info.CID, nodeID, err.Error())
+		}
+	}
+
+	return ctx.Send(ReplicasShrunk{})
 }
 
 // handleServicing asset pull completed and in service status
@@ -209,11 +365,17 @@ func (m *Manager) handleServicing(ctx statemachine.Context, info AssetPullingInf
 	return m.DeleteUnfinishedReplicas(info.Hash.String())
 }
 
+// handlePendingDelete releases the pulling slot that a handler in flight may take after the asset is soft deleted
+func (m *Manager) handlePendingDelete(ctx statemachine.Context, info AssetPullingInfo) error {
+	m.removeTickerForAsset(info.Hash.String())
+	return nil
+}
+
 // handlePullsFailed handles the failed state of asset pulling and retries if necessary
 func (m *Manager) handlePullsFailed(ctx statemachine.Context, info AssetPullingInfo) error {
 	m.removeTickerForAsset(info.Hash.String())
 
-	if info.RetryCount >= int64(MaxRetryCount) {
+	if info.RetryCount >= info.maxRetries() {
 		log.Infof("handle pulls failed: %s, retry count: %d", info.CID, info.RetryCount)
 		return nil
 	}
@@ -0,0 +1,148 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-statemachine"
+	"github.com/linguohua/titan/api/types"
+)
+
+// planEvent plans the event for the asset and returns whether a handler is run for the new state
+func planEvent(t *testing.T, info *AssetPullingInfo, event interface{}) bool {
+	t.Helper()
+
+	next, processed, err := (&Manager{}).plan([]statemachine.Event{{User: event}}, info)
+	if err != nil {
+		t.Fatalf("plan %T in state %s err:%s", event, info.State, err)
+	}
+
+	if processed != 1 {
+		t.Fatalf("plan %T processed %d events", event, processed)
+	}
+
+	return next != nil
+}
+
+func TestPlanPauseDuringFailedCoolDown(t *testing.T) {
+	info := &AssetPullingInfo{State: SeedFailed}
+
+	if planEvent(t, info, AssetPause{}) || info.State != Paused || info.PausedFrom != SeedFailed {
+		t.Fatalf("expected paused from %s, got %s from %s", SeedFailed, info.State, info.PausedFrom)
+	}
+
+	// the retry sent when the cool-down ends
+	if planEvent(t, info, AssetRePull{}) || info.State != Paused || info.PausedFrom != SeedSelect {
+		t.Fatalf("expected paused from %s, got %s from %s", SeedSelect, info.State, info.PausedFrom)
+	}
+
+	if !planEvent(t, info, AssetResume{}) || info.State != SeedSelect || info.PausedFrom != "" {
+		t.Errorf("expected resumed to %s, got %s", SeedSelect, info.State)
+	}
+}
+
+func TestPlanPauseDuringSelect(t *testing.T) {
+	info := &AssetPullingInfo{State: SeedSelect}
+
+	planEvent(t, info, AssetPause{})
+
+	// the events sent by the select handler in flight
+	if planEvent(t, info, PullRequestSent{}) || info.PausedFrom != SeedPulling {
+		t.Fatalf("expected paused from %s, got %s", SeedPulling, info.PausedFrom)
+	}
+
+	result := &NodePulledResult{Status: int64(types.ReplicaStatusSucceeded), Size: 10, BlocksCount: 2, NodeID: "c1", IsCandidate: true}
+	planEvent(t, info, PulledResult{ResultInfo: result})
+	if info.State != Paused || info.Size != 10 || len(info.CandidateReplicaSucceeds) != 1 {
+		t.Fatalf("expected the pull result to be applied while paused, got %+v", info)
+	}
+
+	if !planEvent(t, info, AssetResume{}) || info.State != SeedPulling {
+		t.Errorf("expected resumed to %s, got %s", SeedPulling, info.State)
+	}
+}
+
+func TestPlanPendingDeleteDropsStaleEvents(t *testing.T) {
+	info := &AssetPullingInfo{State: SeedSelect, CID: "cid"}
+
+	planEvent(t, info, AssetPendingDelete{Record: &types.AssetRecord{CID: "cid"}})
+	if info.State != PendingDelete {
+		t.Fatalf("expected %s, got %s", PendingDelete, info.State)
+	}
+
+	// the events sent by the handler in flight
+	for _, event := range []interface{}{PullRequestSent{}, PullFailed{}, AssetRePull{}, PulledResult{}} {
+		planEvent(t, info, event)
+		if info.State != PendingDelete || info.RetryCount != 0 {
+			t.Fatalf("expected %T to be dropped, got %s retries %d", event, info.State, info.RetryCount)
+		}
+	}
+
+	if !planEvent(t, info, AssetRestore{Record: &types.AssetRecord{CID: "cid"}}) || info.State != SeedSelect {
+		t.Errorf("expected restored to %s, got %s", SeedSelect, info.State)
+	}
+}
+
+func TestPlanEdgesShrink(t *testing.T) {
+	info := &AssetPullingInfo{State: Servicing, EdgeReplicas: 3, EdgeReplicaSucceeds: []string{"e1", "e2", "e3"}}
+
+	if !planEvent(t, info, ReplenishReplicas{State: EdgesShrink, Replicas: 1, EdgeReplicaSucceeds: []string{"e1", "e2", "e3"}}) || info.State != EdgesShrink {
+		t.Fatalf("expected %s, got %s", EdgesShrink, info.State)
+	}
+
+	if !planEvent(t, info, ReplicasShrunk{}) || info.State != Servicing || len(info.EdgeReplicaSucceeds) != 1 {
+		t.Errorf("expected %s with 1 edge replica, got %s with %d", Servicing, info.State, len(info.EdgeReplicaSucceeds))
+	}
+}
+
+func TestPlanRequeueWithoutPullingSlot(t *testing.T) {
+	info := &AssetPullingInfo{State: CandidatesPulling}
+
+	if !planEvent(t, info, PullAssetRequeue{}) || info.State != Queued {
+		t.Fatalf("expected requeued to %s, got %s", Queued, info.State)
+	}
+
+	// the results of the pulls sent before the requeue
+	result := &NodePulledResult{Status: int64(types.ReplicaStatusSucceeded), NodeID: "c1", IsCandidate: true}
+	for _, event := range []interface{}{PulledResult{ResultInfo: result}, PullFailed{}, PullAssetRequeue{}} {
+		planEvent(t, info, event)
+		if info.State != Queued || len(info.CandidateReplicaSucceeds) != 0 {
+			t.Fatalf("expected %T to be dropped, got %+v", event, info)
+		}
+	}
+
+	if !planEvent(t, info, PullAssetDequeue{}) || info.State != SeedSelect {
+		t.Errorf("expected dequeued to %s, got %s", SeedSelect, info.State)
+	}
+}
+
+func TestPlanReplicaReplacementsReset(t *testing.T) {
+	info := &AssetPullingInfo{State: CandidatesPulling}
+
+	planEvent(t, info, ReplicasReplaced{Count: 3})
+	planEvent(t, info, ReplicasReplaced{Count: 2})
+	if info.State != CandidatesPulling || info.ReplacedReplicas != 5 {
+		t.Fatalf("expected 5 replacements in %s, got %d in %s", CandidatesPulling, info.ReplacedReplicas, info.State)
+	}
+
+	// the budget is per pulling phase
+	if !planEvent(t, info, PullSucceed{}) || info.State != EdgesSelect || info.ReplacedReplicas != 0 {
+		t.Fatalf("expected the replacements reset in %s, got %d in %s", EdgesSelect, info.ReplacedReplicas, info.State)
+	}
+
+	info = &AssetPullingInfo{State: EdgesPulling}
+	planEvent(t, info, ReplicasReplaced{Count: 10})
+	planEvent(t, info, PullFailed{})
+	if info.State != EdgesFailed || info.ReplacedReplicas != 10 {
+		t.Fatalf("expected 10 replacements in %s, got %d in %s", EdgesFailed, info.ReplacedReplicas, info.State)
+	}
+
+	if !planEvent(t, info, AssetRePull{}) || info.State != EdgesSelect || info.ReplacedReplicas != 0 {
+		t.Fatalf("expected the replacements reset in %s, got %d in %s", EdgesSelect, info.ReplacedReplicas, info.State)
+	}
+
+	info = &AssetPullingInfo{State: SeedPulling, ReplacedReplicas: 4}
+	planEvent(t, info, PullAssetRestart{})
+	if info.State != SeedPulling || info.ReplacedReplicas != 0 {
+		t.Errorf("expected the replacements reset by the restart, got %d in %s", info.ReplacedReplicas, info.State)
+	}
+}
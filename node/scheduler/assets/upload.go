@@ -0,0 +1,73 @@
+package assets
+
+import (
+	"crypto"
+	"fmt"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+	titanrsa "github.com/linguohua/titan/node/rsa"
+	"golang.org/x/xerrors"
+)
+
+// uploadTicketValidTime is the time that the upload must start in after the ticket is issued, a resumable upload can be resumed after it
+const uploadTicketValidTime = time.Hour
+
+// resumableUploadTTL returns the time that an upload can be reported in after its ticket expires
+func (m *Manager) resumableUploadTTL() time.Duration {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get schedulerConfig err:%s", err.Error())
+		return seedTaskExpiration
+	}
+
+	if cfg.ResumableUploadTTL <= 0 {
+		return seedTaskExpiration
+	}
+
+	return time.Duration(cfg.ResumableUploadTTL) * time.Hour
+}
+
+// CreateUploadTicket selects a seed candidate for the client to upload the asset of the max size to,
+// the asset pull task is created when the candidate reports the root cid of the uploaded asset
+func (m *Manager) CreateUploadTicket(info *types.PullAssetReq, maxSize int64) (*types.UploadInfo, error) {
+	m.stateMachineWait.Wait()
+
+	req := &PlacementRequest{Areas: info.Areas, Labels: info.Labels, Attempts: int(m.requestPullPolicy(info).SelectAttempts)}
+	nodes := m.chooseCandidateNodesForAssetReplica(seedReplicaCount, req)
+	if len(nodes) < 1 {
+		return nil, xerrors.New("node not found")
+	}
+
+	titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
+
+	for _, cNode := range nodes {
+		ticket, credentials, err := cNode.UploadTicket(maxSize, uploadTicketValidTime, m.resumableUploadTTL(), titanRsa, m.nodeMgr.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := m.addSeedTask(ticket.ID, cNode.NodeID, info, time.Unix(ticket.Expiration, 0)); err != nil {
+			return nil, xerrors.Errorf("save seed task err:%s", err.Error())
+		}
+
+		log.Infof("asset event: upload ticket %s, seed %s, max size %d", ticket.ID, cNode.NodeID, maxSize)
+
+		return &types.UploadInfo{
+			NodeID:       cNode.NodeID,
+			URL:          fmt.Sprintf("https://%s/upload", cNode.DownloadAddr()),
+			ResumableURL: fmt.Sprintf("https://%s/upload/resumable", cNode.DownloadAddr()),
+			Ticket:       credentials,
+			ValidTime:    time.Unix(ticket.ValidTime, 0),
+		}, nil
+	}
+
+	return nil, xerrors.New("node not found")
+}
+
+// UploadResult creates the asset pull task of the asset uploaded to the seed candidate
+func (m *Manager) UploadResult(nodeID string, result *types.UploadResult) error {
+	log.Infof("asset event: upload ticket %s, uploaded to seed %s, cid %s, size %d", result.TicketID, nodeID, result.CID, result.Size)
+
+	return m.finishSeedTask(result.TicketID, nodeID, result.CID)
+}
@@ -0,0 +1,75 @@
+package assets
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linguohua/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// CreateURLAssetPullTask selects a seed candidate to build the asset from its origin url and returns the id of the url pull,
+// the asset pull task is created when the candidate reports the root cid of the asset
+func (m *Manager) CreateURLAssetPullTask(ctx context.Context, info *types.PullAssetReq) (string, error) {
+	m.stateMachineWait.Wait()
+
+	req := &PlacementRequest{Areas: info.Areas, Labels: info.Labels, Attempts: int(m.requestPullPolicy(info).SelectAttempts)}
+	nodes := m.chooseCandidateNodesForAssetReplica(seedReplicaCount, req)
+	if len(nodes) < 1 {
+		return "", xerrors.New("node not found")
+	}
+
+	id := uuid.NewString()
+
+	for _, cNode := range nodes {
+		if err := m.addSeedTask(id, cNode.NodeID, info, time.Now().Add(seedTaskExpiration)); err != nil {
+			return "", xerrors.Errorf("save seed task err:%s", err.Error())
+		}
+
+		err := cNode.PullAssetFromURL(ctx, &types.URLPullReq{ID: id, URL: info.URL, UnixFS: info.UnixFS})
+		if err != nil {
+			m.removeSeedTask(id)
+			return "", xerrors.Errorf("%s pull asset from url err:%s", cNode.NodeID, err.Error())
+		}
+
+		log.Infof("asset event: url %s, pulled by seed %s, id %s", info.URL, cNode.NodeID, id)
+	}
+
+	return id, nil
+}
+
+// GetURLPullStatus returns the status of the url pull, it is kept until the url pull expires
+func (m *Manager) GetURLPullStatus(id string) (*types.URLPullStatus, error) {
+	task, err := m.LoadSeedTask(id)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, xerrors.Errorf("load seed task %s err:%s", id, err.Error())
+	}
+
+	if task == nil || task.Request.URL == "" {
+		return nil, xerrors.Errorf("url pull %s not found", id)
+	}
+
+	return &types.URLPullStatus{
+		ID:        task.ID,
+		NodeID:    task.NodeID,
+		URL:       task.Request.URL,
+		Status:    task.Status,
+		CID:       task.CID,
+		Msg:       task.Msg,
+		StartTime: task.StartTime,
+	}, nil
+}
+
+// URLPullResult creates the asset pull task of the root cid built by the seed candidate, the result is only accepted from the candidate of the url pull
+func (m *Manager) URLPullResult(nodeID string, result *types.URLPullResult) error {
+	if result.Err != "" {
+		log.Errorf("node %s pull asset from url %s err:%s", nodeID, result.URL, result.Err)
+		return m.failSeedTask(result.ID, nodeID, result.Err)
+	}
+
+	log.Infof("asset event: url %s, built by seed %s, cid %s, size %d", result.URL, nodeID, result.CID, result.Size)
+
+	return m.finishSeedTask(result.ID, nodeID, result.CID)
+}
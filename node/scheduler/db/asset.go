@@ -15,8 +15,8 @@ import (
 
 // UpdateUnfinishedReplica update unfinished replica info , return an error if the replica is finished
 func (n *SQLDB) UpdateUnfinishedReplica(cInfo *types.ReplicaInfo) error {
-	query := fmt.Sprintf(`UPDATE %s SET end_time=NOW(), status=?, done_size=? WHERE hash=? AND node_id=? AND (status=? or status=?)`, replicaInfoTable)
-	result, err := n.db.Exec(query, cInfo.Status, cInfo.DoneSize, cInfo.Hash, cInfo.NodeID, types.ReplicaStatusPulling, types.ReplicaStatusWaiting)
+	query := fmt.Sprintf(`UPDATE %s SET end_time=NOW(), status=?, done_size=?, fail_msg=? WHERE hash=? AND node_id=? AND (status=? or status=?)`, replicaInfoTable)
+	result, err := n.db.Exec(query, cInfo.Status, cInfo.DoneSize, truncateMsg(cInfo.FailMsg), cInfo.Hash, cInfo.NodeID, types.ReplicaStatusPulling, types.ReplicaStatusWaiting)
 	if err != nil {
 		return err
 	}
@@ -33,20 +33,30 @@ func (n *SQLDB) UpdateUnfinishedReplica(cInfo *types.ReplicaInfo) error {
 	return nil
 }
 
-// UpdateUnfinishedReplicasStatus updates the status of unfinished asset replicas
+// UpdateUnfinishedReplicasStatus updates the status of unfinished asset replicas, including the replicas of the asset shards
 func (n *SQLDB) UpdateUnfinishedReplicasStatus(hash string, status types.ReplicaStatus) error {
-	query := fmt.Sprintf(`UPDATE %s SET end_time=NOW(), status=? WHERE hash=? AND (status=? or status=?)`, replicaInfoTable)
-	_, err := n.db.Exec(query, status, hash, types.ReplicaStatusPulling, types.ReplicaStatusWaiting)
+	query := fmt.Sprintf(`UPDATE %s SET end_time=NOW(), status=? WHERE (hash=? OR hash IN (SELECT shard_hash FROM %s WHERE hash=?)) AND (status=? or status=?)`,
+		replicaInfoTable, assetShardTable)
+	_, err := n.db.Exec(query, status, hash, hash, types.ReplicaStatusPulling, types.ReplicaStatusWaiting)
 
 	return err
 }
 
+// truncateMsg truncates the message to the size of the message columns
+func truncateMsg(msg string) string {
+	if len(msg) > maxMsgLength {
+		return msg[:maxMsgLength]
+	}
+
+	return msg
+}
+
 // BatchSaveReplicas inserts or updates replica information in batch
 func (n *SQLDB) BatchSaveReplicas(infos []*types.ReplicaInfo) error {
 	query := fmt.Sprintf(
 		`INSERT INTO %s (hash, node_id, status, is_candidate) 
 				VALUES (:hash, :node_id, :status, :is_candidate) 
-				ON DUPLICATE KEY UPDATE status=VALUES(status)`, replicaInfoTable)
+				ON DUPLICATE KEY UPDATE status=VALUES(status), fail_msg=''`, replicaInfoTable)
 
 	_, err := n.db.NamedExec(query, infos)
 
@@ -56,9 +66,11 @@ func (n *SQLDB) BatchSaveReplicas(infos []*types.ReplicaInfo) error {
 // SaveAssetRecord inserts or updates asset record information
 func (n *SQLDB) SaveAssetRecord(info *types.AssetRecord) error {
 	query := fmt.Sprintf(
-		`INSERT INTO %s (hash, cid, state, edge_replicas, candidate_replicas, expiration, total_size, total_blocks, scheduler_sid, end_time) 
-				VALUES (:hash, :cid, :state, :edge_replicas, :candidate_replicas, :expiration, :total_size, :total_blocks, :scheduler_sid, NOW()) 
-				ON DUPLICATE KEY UPDATE total_size=VALUES(total_size), total_blocks=VALUES(total_blocks), state=VALUES(state), edge_replicas=VALUES(edge_replicas), candidate_replicas=VALUES(candidate_replicas), end_time=NOW()`, assetRecordTable)
+		`INSERT INTO %s (hash, cid, state, edge_replicas, candidate_replicas, expiration, total_size, total_blocks, scheduler_sid, areas, min_edge_replicas, max_edge_replicas, priority, group_id, include_labels, exclude_labels, paused_from, pull_timeout, retry_interval, max_retries, select_attempts, data_shards, parity_shards, end_time) 
+				VALUES (:hash, :cid, :state, :edge_replicas, :candidate_replicas, :expiration, :total_size, :total_blocks, :scheduler_sid, :areas, :min_edge_replicas, :max_edge_replicas, :priority, :group_id, :include_labels, :exclude_labels, :paused_from, :pull_timeout, :retry_interval, :max_retries, :select_attempts, :data_shards, :parity_shards, NOW()) 
+				ON DUPLICATE KEY UPDATE total_size=VALUES(total_size), total_blocks=VALUES(total_blocks), state=VALUES(state), edge_replicas=VALUES(edge_replicas), candidate_replicas=VALUES(candidate_replicas), areas=VALUES(areas), 
+				min_edge_replicas=VALUES(min_edge_replicas), max_edge_replicas=VALUES(max_edge_replicas), priority=VALUES(priority), group_id=VALUES(group_id), include_labels=VALUES(include_labels), exclude_labels=VALUES(exclude_labels), paused_from=VALUES(paused_from), 
+				pull_timeout=VALUES(pull_timeout), retry_interval=VALUES(retry_interval), max_retries=VALUES(max_retries), select_attempts=VALUES(select_attempts), data_shards=VALUES(data_shards), parity_shards=VALUES(parity_shards), end_time=NOW()`, assetRecordTable)
 
 	_, err := n.db.NamedExec(query, info)
 	return err
@@ -91,6 +103,29 @@ func (n *SQLDB) LoadAssetRecords(statuses []string, limit, offset int, serverID
 	return n.db.QueryxContext(context.Background(), query, args...)
 }
 
+// LoadQueuedAssetHashes load the hashes of the queued assets, ordered by priority and creation time.
+func (n *SQLDB) LoadQueuedAssetHashes(state string, serverID dtypes.ServerID, limit int) ([]string, error) {
+	var hashes []string
+	query := fmt.Sprintf(`SELECT hash FROM %s WHERE state=? AND scheduler_sid=? order by priority desc, created_time asc, hash asc LIMIT ?`, assetRecordTable)
+	if err := n.db.Select(&hashes, query, state, serverID, limit); err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// LoadAssetQueuePosition retrieves the position of the asset in the queue, starting from 1.
+func (n *SQLDB) LoadAssetQueuePosition(hash, state string) (int, error) {
+	query := fmt.Sprintf(`SELECT count(a.hash)+1 FROM %s a, (SELECT priority, created_time, scheduler_sid FROM %s WHERE hash=?) b 
+		WHERE a.state=? AND a.scheduler_sid=b.scheduler_sid AND (a.priority>b.priority OR (a.priority=b.priority AND 
+		(a.created_time<b.created_time OR (a.created_time=b.created_time AND a.hash<?))))`, assetRecordTable, assetRecordTable)
+
+	var position int
+	err := n.db.Get(&position, query, hash, state, hash)
+
+	return position, err
+}
+
 // LoadReplicasByHash load asset replica information based on hash and statuses.
 func (n *SQLDB) LoadReplicasByHash(hash string, statuses []types.ReplicaStatus) (*sqlx.Rows, error) {
 	sQuery := fmt.Sprintf(`SELECT * FROM %s WHERE hash=? AND status in (?)`, replicaInfoTable)
@@ -124,10 +159,23 @@ func (n *SQLDB) LoadNodeReplicaCount(nodeID string) (int, error) {
 	return count, err
 }
 
+// LoadReplicaHashesOfNode retrieves the asset hashes of the node replicas with the status.
+func (n *SQLDB) LoadReplicaHashesOfNode(nodeID string, status types.ReplicaStatus, limit int) ([]string, error) {
+	var hashes []string
+	query := fmt.Sprintf(`SELECT hash FROM %s WHERE node_id=? AND status=? LIMIT ?`, replicaInfoTable)
+	if err := n.db.Select(&hashes, query, nodeID, status, limit); err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
 // LoadAssetCIDsByNodeID retrieves asset CIDs of a node based on nodeID.
+// The cid of a shard replica is the cid of the shard, the node holds the whole shard but not the erasure coded asset
 func (n *SQLDB) LoadAssetCIDsByNodeID(nodeID string, limit, offset int) ([]string, error) {
 	var hashes []string
-	query := fmt.Sprintf("select cid from (select hash from %s WHERE node_id=? AND status=? LIMIT %d OFFSET %d) as a left join %s as b on a.hash = b.hash", replicaInfoTable, limit, offset, assetRecordTable)
+	query := fmt.Sprintf(`select COALESCE(b.cid, s.cid, '') from (select hash from %s WHERE node_id=? AND status=? LIMIT %d OFFSET %d) as a 
+		left join %s as b on a.hash = b.hash left join %s as s on a.hash = s.shard_hash`, replicaInfoTable, limit, offset, assetRecordTable, assetShardTable)
 	if err := n.db.Select(&hashes, query, nodeID, types.ReplicaStatusSucceeded); err != nil {
 		return nil, err
 	}
@@ -143,24 +191,221 @@ func (n *SQLDB) UpdateAssetRecordExpiry(hash string, eTime time.Time) error {
 	return err
 }
 
-// LoadMinExpiryOfAssetRecords  load the minimum expiration time of asset records based on serverID.
-func (n *SQLDB) LoadMinExpiryOfAssetRecords(serverID dtypes.ServerID) (time.Time, error) {
-	query := fmt.Sprintf(`SELECT MIN(expiration) FROM %s WHERE scheduler_sid=?`, assetRecordTable)
+// UpdateAssetGroupExpiry resets the expiration time of the asset records in the group
+func (n *SQLDB) UpdateAssetGroupExpiry(groupID string, serverID dtypes.ServerID, eTime time.Time) error {
+	query := fmt.Sprintf(`UPDATE %s SET expiration=? WHERE group_id=? AND scheduler_sid=?`, assetRecordTable)
+	_, err := n.db.Exec(query, eTime, groupID, serverID)
+
+	return err
+}
+
+// LoadAssetRecordsOfGroup load the asset records of the group
+func (n *SQLDB) LoadAssetRecordsOfGroup(groupID string, serverID dtypes.ServerID) ([]*types.AssetRecord, error) {
+	var infos []*types.AssetRecord
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE group_id=? AND scheduler_sid=? order by created_time asc`, assetRecordTable)
+	if err := n.db.Select(&infos, query, groupID, serverID); err != nil {
+		return nil, err
+	}
+
+	return infos, nil
+}
+
+// LoadScalableAssetRecords load the asset records in the given state whose edge replicas can be shrunk.
+func (n *SQLDB) LoadScalableAssetRecords(state string, serverID dtypes.ServerID, limit int) ([]*types.AssetRecord, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE scheduler_sid=? AND state=? AND max_edge_replicas>0 AND edge_replicas>min_edge_replicas LIMIT ?`, assetRecordTable)
+
+	var out []*types.AssetRecord
+	if err := n.db.Select(&out, query, serverID, state, limit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// SaveReplicaScalingRecord inserts a scaling decision of the asset edge replicas
+func (n *SQLDB) SaveReplicaScalingRecord(info *types.ReplicaScalingRecord) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (hash, cid, from_replicas, to_replicas, requests, downloads, download_size, msg) 
+				VALUES (:hash, :cid, :from_replicas, :to_replicas, :requests, :downloads, :download_size, :msg)`, replicaScalingTable)
+
+	_, err := n.db.NamedExec(query, info)
+	return err
+}
+
+// LoadReplicaScalingRecords load the scaling decisions of the asset edge replicas
+func (n *SQLDB) LoadReplicaScalingRecords(hash string, limit, offset int) ([]*types.ReplicaScalingRecord, error) {
+	if limit > loadScalingRecordsLimit || limit == 0 {
+		limit = loadScalingRecordsLimit
+	}
+
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE hash=? order by created_time desc LIMIT ? OFFSET ?`, replicaScalingTable)
+
+	var out []*types.ReplicaScalingRecord
+	if err := n.db.Select(&out, query, hash, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// SaveAssetEvents inserts the events applied to the assets in the state machine
+func (n *SQLDB) SaveAssetEvents(infos []*types.AssetEvent) error {
+	for _, info := range infos {
+		info.Msg = truncateMsg(info.Msg)
+	}
+
+	query := fmt.Sprintf(
+		`INSERT INTO %s (hash, cid, event, from_state, to_state, msg, created_time) 
+				VALUES (:hash, :cid, :event, :from_state, :to_state, :msg, :created_time)`, assetEventTable)
+
+	_, err := n.db.NamedExec(query, infos)
+	return err
+}
+
+// UpdateLatestAssetEvent replaces the latest event of the asset with the same event type
+func (n *SQLDB) UpdateLatestAssetEvent(info *types.AssetEvent) error {
+	query := fmt.Sprintf(`UPDATE %s SET from_state=?, to_state=?, msg=?, created_time=? WHERE hash=? AND event=? ORDER BY created_time DESC LIMIT 1`, assetEventTable)
+	_, err := n.db.Exec(query, info.FromState, info.ToState, truncateMsg(info.Msg), info.CreatedTime, info.Hash, info.Event)
+	return err
+}
+
+// DeleteAssetEventsBefore deletes the asset events created before the time
+func (n *SQLDB) DeleteAssetEventsBefore(before time.Time) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE created_time<?`, assetEventTable)
+	result, err := n.db.Exec(query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// LoadAssetEvents load the state machine events of the asset, the latest first
+func (n *SQLDB) LoadAssetEvents(hash string, limit, offset int) ([]*types.AssetEvent, error) {
+	if limit > loadAssetEventsLimit || limit == 0 {
+		limit = loadAssetEventsLimit
+	}
+
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE hash=? order by created_time desc LIMIT ? OFFSET ?`, assetEventTable)
+
+	var out []*types.AssetEvent
+	if err := n.db.Select(&out, query, hash, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// SaveReplicaMoveRecord inserts a replica move of the rebalancer
+func (n *SQLDB) SaveReplicaMoveRecord(info *types.ReplicaMoveRecord) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (id, hash, cid, from_node_id, to_node_id, size, status, msg, scheduler_sid) 
+				VALUES (:id, :hash, :cid, :from_node_id, :to_node_id, :size, :status, :msg, :scheduler_sid)`, replicaMoveTable)
+
+	_, err := n.db.NamedExec(query, info)
+	return err
+}
+
+// UpdateReplicaMoveRecord updates the status of a replica move
+func (n *SQLDB) UpdateReplicaMoveRecord(id string, status types.ReplicaStatus, msg string) error {
+	query := fmt.Sprintf(`UPDATE %s SET status=?, msg=?, end_time=NOW() WHERE id=?`, replicaMoveTable)
+	_, err := n.db.Exec(query, status, msg, id)
+
+	return err
+}
+
+// LoadReplicaMoveRecords load the replica moves from or to the node, all moves if the node id is empty
+func (n *SQLDB) LoadReplicaMoveRecords(nodeID string, limit, offset int) ([]*types.ReplicaMoveRecord, error) {
+	if limit > loadMoveRecordsLimit || limit == 0 {
+		limit = loadMoveRecordsLimit
+	}
+
+	var out []*types.ReplicaMoveRecord
+	if nodeID == "" {
+		query := fmt.Sprintf(`SELECT * FROM %s order by created_time desc LIMIT ? OFFSET ?`, replicaMoveTable)
+		if err := n.db.Select(&out, query, limit, offset); err != nil {
+			return nil, err
+		}
+
+		return out, nil
+	}
+
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE from_node_id=? OR to_node_id=? order by created_time desc LIMIT ? OFFSET ?`, replicaMoveTable)
+	if err := n.db.Select(&out, query, nodeID, nodeID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadUnfinishedReplicaMoveRecords load the replica moves of the scheduler that are still pulling
+func (n *SQLDB) LoadUnfinishedReplicaMoveRecords(serverID dtypes.ServerID) ([]*types.ReplicaMoveRecord, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE status=? AND scheduler_sid=?`, replicaMoveTable)
+
+	var out []*types.ReplicaMoveRecord
+	if err := n.db.Select(&out, query, types.ReplicaStatusPulling, serverID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadMinExpiryOfAssetRecords  load the minimum expiration time of asset records based on serverID, the asset records in the skip state are not counted.
+func (n *SQLDB) LoadMinExpiryOfAssetRecords(serverID dtypes.ServerID, skipState string) (time.Time, error) {
+	query := fmt.Sprintf(`SELECT MIN(expiration) FROM %s WHERE scheduler_sid=? AND state<>?`, assetRecordTable)
 
 	var out time.Time
-	if err := n.db.Get(&out, query, serverID); err != nil {
+	if err := n.db.Get(&out, query, serverID, skipState); err != nil {
 		return out, err
 	}
 
 	return out, nil
 }
 
-// LoadExpiredAssetRecords load all expired asset records based on serverID.
-func (n *SQLDB) LoadExpiredAssetRecords(serverID dtypes.ServerID) ([]*types.AssetRecord, error) {
-	query := fmt.Sprintf(`SELECT * FROM %s WHERE scheduler_sid=? AND expiration <= NOW() LIMIT ?`, assetRecordTable)
+// LoadExpiredAssetRecords load all expired asset records based on serverID, except the asset records in the skip state.
+func (n *SQLDB) LoadExpiredAssetRecords(serverID dtypes.ServerID, skipState string) ([]*types.AssetRecord, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE scheduler_sid=? AND state<>? AND expiration <= NOW() LIMIT ?`, assetRecordTable)
 
 	var out []*types.AssetRecord
-	if err := n.db.Select(&out, query, serverID, loadExpiredAssetRecordsLimit); err != nil {
+	if err := n.db.Select(&out, query, serverID, skipState, loadExpiredAssetRecordsLimit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// UpdateAssetDeleteTime sets the delete time of the asset record to now.
+func (n *SQLDB) UpdateAssetDeleteTime(hash string) error {
+	query := fmt.Sprintf(`UPDATE %s SET delete_time=NOW() WHERE hash=?`, assetRecordTable)
+	_, err := n.db.Exec(query, hash)
+
+	return err
+}
+
+// LoadDeletableAssetRecords load the asset records in the given state that were deleted before the given time.
+func (n *SQLDB) LoadDeletableAssetRecords(state string, serverID dtypes.ServerID, before time.Time, limit int) ([]*types.AssetRecord, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE scheduler_sid=? AND state=? AND delete_time <= ? LIMIT ?`, assetRecordTable)
+
+	var out []*types.AssetRecord
+	if err := n.db.Select(&out, query, serverID, state, before, limit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadAssetRecordsLackingReplicas load the asset records in the given state whose succeeded edge or candidate replicas are fewer than required,
+// the edge replicas of an erasure coded asset are its distinct shards held by the edges, a shard held by more edges is counted once.
+func (n *SQLDB) LoadAssetRecordsLackingReplicas(state string, serverID dtypes.ServerID, limit int) ([]*types.AssetRecord, error) {
+	query := fmt.Sprintf(`SELECT a.* FROM %s a WHERE a.scheduler_sid=? AND a.state=? AND (
+		(a.data_shards=0 AND (SELECT count(r.hash) FROM %s r WHERE r.hash=a.hash AND r.status=? AND r.is_candidate=0) < a.edge_replicas) OR 
+		(a.data_shards>0 AND (SELECT count(DISTINCT s.shard_hash) FROM %s s JOIN %s r ON r.hash=s.shard_hash WHERE s.hash=a.hash AND r.status=? AND r.is_candidate=0) < a.edge_replicas) OR 
+		(SELECT count(r.hash) FROM %s r WHERE r.hash=a.hash AND r.status=? AND r.is_candidate=1) < a.candidate_replicas) LIMIT ?`,
+		assetRecordTable, replicaInfoTable, assetShardTable, replicaInfoTable, replicaInfoTable)
+
+	succeeded := types.ReplicaStatusSucceeded
+	var out []*types.AssetRecord
+	if err := n.db.Select(&out, query, serverID, state, succeeded, succeeded, succeeded, limit); err != nil {
 		return nil, err
 	}
 
@@ -196,6 +441,19 @@ func (n *SQLDB) DeleteAssetRecord(hash string) error {
 		return err
 	}
 
+	// shards of the erasure coded asset and their replicas
+	sQuery := fmt.Sprintf(`DELETE FROM %s WHERE hash IN (SELECT shard_hash FROM %s WHERE hash=?)`, replicaInfoTable, assetShardTable)
+	_, err = tx.Exec(sQuery, hash)
+	if err != nil {
+		return err
+	}
+
+	sQuery = fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, assetShardTable)
+	_, err = tx.Exec(sQuery, hash)
+	if err != nil {
+		return err
+	}
+
 	// asset info
 	dQuery := fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, assetRecordTable)
 	_, err = tx.Exec(dQuery, hash)
@@ -203,6 +461,20 @@ func (n *SQLDB) DeleteAssetRecord(hash string) error {
 		return err
 	}
 
+	// key envelopes of the private asset
+	kQuery := fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, assetKeyEnvelopeTable)
+	_, err = tx.Exec(kQuery, hash)
+	if err != nil {
+		return err
+	}
+
+	// state machine events
+	eQuery := fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, assetEventTable)
+	_, err = tx.Exec(eQuery, hash)
+	if err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -213,10 +485,10 @@ func (n *SQLDB) DeleteAssetReplica(hash, nodeID string) error {
 	return err
 }
 
-// DeleteUnfinishedReplicas deletes the incomplete replicas with the given hash from the database.
+// DeleteUnfinishedReplicas deletes the incomplete replicas with the given hash from the database, including the replicas of the asset shards.
 func (n *SQLDB) DeleteUnfinishedReplicas(hash string) error {
-	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND status!=?`, replicaInfoTable)
-	_, err := n.db.Exec(query, hash, types.ReplicaStatusSucceeded)
+	query := fmt.Sprintf(`DELETE FROM %s WHERE (hash=? OR hash IN (SELECT shard_hash FROM %s WHERE hash=?)) AND status!=?`, replicaInfoTable, assetShardTable)
+	_, err := n.db.Exec(query, hash, hash, types.ReplicaStatusSucceeded)
 	return err
 }
 
@@ -26,9 +26,22 @@ CREATE TABLE `node_info` (
     `blocks`             BIGINT       DEFAULT 0,
     `disk_usage`         FLOAT        DEFAULT 0,
     `scheduler_sid`      VARCHAR(128) NOT NULL,
+    `maintenance`        VARCHAR(16)  DEFAULT '',
+    `quarantine_until`   DATETIME     DEFAULT CURRENT_TIMESTAMP,
+    `quarantine_reason`  VARCHAR(256) DEFAULT '',
+    `quarantine_count`   INT          DEFAULT 0,
     PRIMARY KEY (`node_id`)
 ) ENGINE=InnoDB COMMENT='Node information';
 
+-- Node label table
+CREATE TABLE `node_label` (
+	`node_id`     VARCHAR(128) NOT NULL,
+	`label_key`   VARCHAR(64)  NOT NULL,
+	`label_value` VARCHAR(128) DEFAULT '',
+	PRIMARY KEY (`node_id`, `label_key`),
+    KEY `idx_label` (`label_key`, `label_value`)
+) ENGINE=InnoDB COMMENT='Node labels';
+
 -- Validation results table
 CREATE TABLE `validation_result` (
     `round_id`      VARCHAR(128) NOT NULL,
@@ -78,6 +91,7 @@ CREATE TABLE `replica_info` (
     `done_size`    BIGINT       DEFAULT 0 ,
     `is_candidate` BOOLEAN,
 	`end_time`     DATETIME     DEFAULT CURRENT_TIMESTAMP,
+    `fail_msg`     VARCHAR(256) DEFAULT '',
     UNIQUE KEY (`hash`,`node_id`),
     KEY `idx_node_id` (`node_id`)
 ) ENGINE=InnoDB COMMENT='replica info';
@@ -95,10 +109,134 @@ CREATE TABLE `asset_record` (
     `created_time`       DATETIME     DEFAULT CURRENT_TIMESTAMP,
 	`end_time`           DATETIME     DEFAULT CURRENT_TIMESTAMP,
     `scheduler_sid`      VARCHAR(128) NOT NULL,
+    `areas`              VARCHAR(256) DEFAULT '',
+    `min_edge_replicas`  TINYINT      DEFAULT 0 ,
+    `max_edge_replicas`  TINYINT      DEFAULT 0 ,
+    `priority`           INT          DEFAULT 0 ,
+    `group_id`           VARCHAR(128) DEFAULT '',
+    `include_labels`     VARCHAR(512) DEFAULT '',
+    `exclude_labels`     VARCHAR(512) DEFAULT '',
+    `delete_time`        DATETIME     DEFAULT CURRENT_TIMESTAMP,
+    `paused_from`        VARCHAR(32)  DEFAULT '',
+    `pull_timeout`       INT          DEFAULT 0 ,
+    `retry_interval`     INT          DEFAULT 0 ,
+    `max_retries`        INT          DEFAULT 0 ,
+    `select_attempts`    INT          DEFAULT 0 ,
+    `data_shards`        SMALLINT     DEFAULT 0 ,
+    `parity_shards`      SMALLINT     DEFAULT 0 ,
 	PRIMARY KEY (`hash`),
-    KEY `idx_sid` (`scheduler_sid`)
+    KEY `idx_sid` (`scheduler_sid`),
+    KEY `idx_state_priority` (`state`, `priority`),
+    KEY `idx_group_id` (`group_id`)
 ) ENGINE=InnoDB COMMENT='asset record';
 
+-- Asset edge replica scaling record table
+CREATE TABLE `replica_scaling_record` (
+	`hash`          VARCHAR(128) NOT NULL,
+	`cid`           VARCHAR(128) NOT NULL,
+    `from_replicas` INT          DEFAULT 0 ,
+    `to_replicas`   INT          DEFAULT 0 ,
+    `requests`      BIGINT       DEFAULT 0 ,
+    `downloads`     BIGINT       DEFAULT 0 ,
+    `download_size` BIGINT       DEFAULT 0 ,
+    `msg`           VARCHAR(256) DEFAULT '' ,
+    `created_time`  DATETIME     DEFAULT CURRENT_TIMESTAMP,
+    KEY `idx_hash` (`hash`)
+) ENGINE=InnoDB COMMENT='replica scaling record';
+
+-- Asset state machine event table
+CREATE TABLE `asset_event` (
+	`hash`          VARCHAR(128) NOT NULL,
+	`cid`           VARCHAR(128) NOT NULL,
+    `event`         VARCHAR(64)  DEFAULT '' ,
+    `from_state`    VARCHAR(32)  DEFAULT '' ,
+    `to_state`      VARCHAR(32)  DEFAULT '' ,
+    `msg`           VARCHAR(256) DEFAULT '' ,
+    `created_time`  DATETIME(3)  DEFAULT CURRENT_TIMESTAMP(3),
+    KEY `idx_hash` (`hash`)
+) ENGINE=InnoDB COMMENT='asset event';
+
+-- Wrapped data keys of the private assets
+CREATE TABLE `asset_key_envelope` (
+	`hash`          VARCHAR(128)  NOT NULL,
+	`cid`           VARCHAR(128)  NOT NULL,
+	`user_id`       VARCHAR(128)  NOT NULL,
+	`wrapped_key`   VARBINARY(1024) NOT NULL,
+    `created_time`  DATETIME      DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (`hash`, `user_id`)
+) ENGINE=InnoDB COMMENT='asset key envelope';
+
+-- Assets being put on the seed candidates, e.g. built from the origin url or uploaded
+CREATE TABLE `seed_task` (
+	`id`            VARCHAR(128) NOT NULL UNIQUE,
+	`node_id`       VARCHAR(128) NOT NULL,
+	`request`       BLOB         NOT NULL,
+    `start_time`    DATETIME     DEFAULT CURRENT_TIMESTAMP,
+    `expiration`    DATETIME     NOT NULL,
+    `status`        TINYINT      DEFAULT 0 ,
+    `cid`           VARCHAR(128) DEFAULT '' ,
+    `msg`           VARCHAR(256) DEFAULT '' ,
+	PRIMARY KEY (`id`),
+    KEY `idx_expiration` (`expiration`)
+) ENGINE=InnoDB COMMENT='seed task';
+
+-- Shards of the erasure coded assets
+CREATE TABLE `asset_shard` (
+	`hash`          VARCHAR(128) NOT NULL,
+	`shard_index`   SMALLINT     NOT NULL,
+	`cid`           VARCHAR(128) NOT NULL,
+	`shard_hash`    VARCHAR(128) NOT NULL,
+    `size`          BIGINT       DEFAULT 0 ,
+    `blocks`        INT          DEFAULT 0 ,
+	PRIMARY KEY (`hash`, `shard_index`),
+    UNIQUE KEY `idx_shard_hash` (`shard_hash`)
+) ENGINE=InnoDB COMMENT='asset shard';
+
+-- Replica move record table
+CREATE TABLE `replica_move_record` (
+	`id`            VARCHAR(64)  NOT NULL UNIQUE,
+	`hash`          VARCHAR(128) NOT NULL,
+	`cid`           VARCHAR(128) NOT NULL,
+    `from_node_id`  VARCHAR(128) NOT NULL,
+    `to_node_id`    VARCHAR(128) NOT NULL,
+    `size`          BIGINT       DEFAULT 0 ,
+    `status`        TINYINT      DEFAULT 0 ,
+    `msg`           VARCHAR(256) DEFAULT '' ,
+    `scheduler_sid` VARCHAR(128) NOT NULL,
+    `created_time`  DATETIME     DEFAULT CURRENT_TIMESTAMP,
+    `end_time`      DATETIME     DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (`id`),
+    KEY `idx_from_node` (`from_node_id`),
+    KEY `idx_to_node` (`to_node_id`),
+    KEY `idx_status_sid` (`status`, `scheduler_sid`)
+) ENGINE=InnoDB COMMENT='replica move record';
+
+-- S3 access key table
+CREATE TABLE `s3_access_key` (
+	`access_key`    VARCHAR(32)  NOT NULL UNIQUE,
+	`secret_key`    VARCHAR(64)  NOT NULL,
+	`tenant`        VARCHAR(64)  NOT NULL,
+    `replicas`      INT          DEFAULT 0 ,
+    `expiration`    BIGINT       DEFAULT 0 ,
+    `created_time`  DATETIME     DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (`access_key`),
+    KEY `idx_tenant` (`tenant`)
+) ENGINE=InnoDB COMMENT='s3 access key';
+
+-- S3 object table, the keys are compared in bytes
+CREATE TABLE `s3_object` (
+	`tenant`        VARCHAR(64)    NOT NULL,
+	`bucket`        VARCHAR(64)    NOT NULL,
+	`object_key`    VARBINARY(1024) NOT NULL,
+	`cid`           VARCHAR(128)   NOT NULL,
+    `size`          BIGINT         DEFAULT 0 ,
+    `etag`          VARCHAR(64)    DEFAULT '' ,
+    `content_type`  VARCHAR(128)   DEFAULT '' ,
+    `last_modified` DATETIME       DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (`tenant`, `bucket`, `object_key`),
+    KEY `idx_cid` (`cid`)
+) ENGINE=InnoDB COMMENT='s3 object';
+
 -- Edge update information table
 CREATE TABLE `edge_update_info` (
 	`node_type`    INT          NOT NULL UNIQUE,
@@ -68,6 +68,190 @@ func (n *SQLDB) UpdatePortMapping(nodeID, port string) error {
 	return err
 }
 
+// UpdateNodeMaintenance sets the maintenance mode of a node.
+func (n *SQLDB) UpdateNodeMaintenance(nodeID string, mode types.NodeMaintenance) error {
+	query := fmt.Sprintf(`UPDATE %s SET maintenance=? WHERE node_id=?`, nodeInfoTable)
+	result, err := n.db.Exec(query, mode, nodeID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		var count int
+		cQuery := fmt.Sprintf(`SELECT count(node_id) FROM %s WHERE node_id=?`, nodeInfoTable)
+		if err := n.db.Get(&count, cQuery, nodeID); err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return xerrors.Errorf("node %s not found", nodeID)
+		}
+	}
+
+	return nil
+}
+
+// LoadNodeMaintenance load the maintenance mode of a node.
+func (n *SQLDB) LoadNodeMaintenance(nodeID string) (types.NodeMaintenance, error) {
+	var mode types.NodeMaintenance
+	query := fmt.Sprintf("SELECT maintenance FROM %s WHERE node_id=?", nodeInfoTable)
+	if err := n.db.Get(&mode, query, nodeID); err != nil {
+		return "", err
+	}
+
+	return mode, nil
+}
+
+// LoadMaintenanceNodes load the ids of the nodes in the maintenance mode.
+func (n *SQLDB) LoadMaintenanceNodes(mode types.NodeMaintenance, serverID dtypes.ServerID) ([]string, error) {
+	list := make([]string, 0)
+	query := fmt.Sprintf("SELECT node_id FROM %s WHERE maintenance=? AND scheduler_sid=?", nodeInfoTable)
+	if err := n.db.Select(&list, query, mode, serverID); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// UpdateNodeQuarantine sets the quarantine of a node.
+func (n *SQLDB) UpdateNodeQuarantine(nodeID string, q *types.NodeQuarantine) error {
+	query := fmt.Sprintf(`UPDATE %s SET quarantine_until=?, quarantine_reason=?, quarantine_count=? WHERE node_id=?`, nodeInfoTable)
+	_, err := n.db.Exec(query, q.QuarantineUntil, truncateMsg(q.QuarantineReason), q.QuarantineCount, nodeID)
+	return err
+}
+
+// LoadNodeQuarantine load the quarantine of a node.
+func (n *SQLDB) LoadNodeQuarantine(nodeID string) (*types.NodeQuarantine, error) {
+	var q types.NodeQuarantine
+	query := fmt.Sprintf("SELECT quarantine_until, quarantine_reason, quarantine_count FROM %s WHERE node_id=?", nodeInfoTable)
+	if err := n.db.Get(&q, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	return &q, nil
+}
+
+// SaveNodeLabels replaces the labels of a node.
+func (n *SQLDB) SaveNodeLabels(nodeID string, labels map[string]string) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveNodeLabels Rollback err:%s", err.Error())
+		}
+	}()
+
+	dQuery := fmt.Sprintf(`DELETE FROM %s WHERE node_id=?`, nodeLabelTable)
+	_, err = tx.Exec(dQuery, nodeID)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range labels {
+		iQuery := fmt.Sprintf(`INSERT INTO %s (node_id, label_key, label_value) VALUES (?, ?, ?)`, nodeLabelTable)
+		_, err = tx.Exec(iQuery, nodeID, key, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// nodeLabel is a label of a node
+type nodeLabel struct {
+	NodeID string `db:"node_id"`
+	Key    string `db:"label_key"`
+	Value  string `db:"label_value"`
+}
+
+// LoadNodeLabels load the labels of a node.
+func (n *SQLDB) LoadNodeLabels(nodeID string) (map[string]string, error) {
+	out, err := n.LoadNodesLabels([]string{nodeID})
+	if err != nil {
+		return nil, err
+	}
+
+	labels, ok := out[nodeID]
+	if !ok {
+		labels = make(map[string]string)
+	}
+
+	return labels, nil
+}
+
+// LoadNodesLabels load the labels of the nodes, keyed by the node id.
+func (n *SQLDB) LoadNodesLabels(nodeIDs []string) (map[string]map[string]string, error) {
+	out := make(map[string]map[string]string)
+	if len(nodeIDs) == 0 {
+		return out, nil
+	}
+
+	sQuery := fmt.Sprintf(`SELECT node_id, label_key, label_value FROM %s WHERE node_id IN (?)`, nodeLabelTable)
+	query, args, err := sqlx.In(sQuery, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*nodeLabel
+	query = n.db.Rebind(query)
+	if err := n.db.Select(&list, query, args...); err != nil {
+		return nil, err
+	}
+
+	for _, l := range list {
+		labels, ok := out[l.NodeID]
+		if !ok {
+			labels = make(map[string]string)
+			out[l.NodeID] = labels
+		}
+
+		labels[l.Key] = l.Value
+	}
+
+	return out, nil
+}
+
+// labelSelectorCondition returns the sql condition and args of the node_id column that matches the label selector.
+func labelSelectorCondition(selector *types.LabelSelector) (string, []interface{}) {
+	condition := ""
+	args := make([]interface{}, 0)
+
+	if selector == nil {
+		return condition, args
+	}
+
+	subQuery := func(label string) string {
+		key, value, hasValue := types.ParseLabel(label)
+		args = append(args, key)
+		if !hasValue {
+			return fmt.Sprintf(`SELECT node_id FROM %s WHERE label_key=?`, nodeLabelTable)
+		}
+
+		args = append(args, value)
+		return fmt.Sprintf(`SELECT node_id FROM %s WHERE label_key=? AND label_value=?`, nodeLabelTable)
+	}
+
+	for _, label := range selector.Include {
+		condition += fmt.Sprintf(` AND node_id IN (%s)`, subQuery(label))
+	}
+
+	for _, label := range selector.Exclude {
+		condition += fmt.Sprintf(` AND node_id NOT IN (%s)`, subQuery(label))
+	}
+
+	return condition, args
+}
+
 // SaveValidationResultInfos inserts validation result information.
 func (n *SQLDB) SaveValidationResultInfos(infos []*types.ValidationResultInfo) error {
 	query := fmt.Sprintf(`INSERT INTO %s (round_id, node_id, validator_id, status, cid) VALUES (:round_id, :node_id, :validator_id, :status, :cid)`, validationResultTable)
@@ -134,6 +318,29 @@ func (n *SQLDB) LoadValidationResultInfos(startTime, endTime time.Time, pageNumb
 	return res, nil
 }
 
+// LoadValidationSuccessRates load the validation success rates of nodes since the given time,
+// only the results caused by the node itself are counted.
+func (n *SQLDB) LoadValidationSuccessRates(since time.Time) (map[string]float64, error) {
+	var rows []struct {
+		NodeID string  `db:"node_id"`
+		Rate   float64 `db:"rate"`
+	}
+
+	query := fmt.Sprintf(`SELECT node_id, SUM(IF(status=?,1,0))/COUNT(*) AS rate FROM %s WHERE start_time>=? AND status in (?,?,?) GROUP BY node_id`, validationResultTable)
+	err := n.db.Select(&rows, query, types.ValidationStatusSuccess, since,
+		types.ValidationStatusSuccess, types.ValidationStatusNodeTimeOut, types.ValidationStatusValidateFail)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		out[row.NodeID] = row.Rate
+	}
+
+	return out, nil
+}
+
 // SaveEdgeUpdateConfig inserts edge update information.
 func (n *SQLDB) SaveEdgeUpdateConfig(info *api.EdgeUpdateConfig) error {
 	sqlString := fmt.Sprintf(`INSERT INTO %s (node_type, app_name, version, hash, download_url) VALUES (:node_type, :app_name, :version, :hash, :download_url) ON DUPLICATE KEY UPDATE app_name=:app_name, version=:version, hash=:hash, download_url=:download_url`, edgeUpdateTable)
@@ -324,11 +531,13 @@ func (n *SQLDB) NodeExists(nodeID string, nodeType types.NodeType) error {
 	return nil
 }
 
-// LoadNodeInfos load nodes information.
-func (n *SQLDB) LoadNodeInfos(limit, offset int) (*sqlx.Rows, int64, error) {
+// LoadNodeInfos load nodes information, the nodes are filtered by the label selector.
+func (n *SQLDB) LoadNodeInfos(limit, offset int, selector *types.LabelSelector) (*sqlx.Rows, int64, error) {
+	condition, args := labelSelectorCondition(selector)
+
 	var total int64
-	cQuery := fmt.Sprintf(`SELECT count(node_id) FROM %s`, nodeInfoTable)
-	err := n.db.Get(&total, cQuery)
+	cQuery := fmt.Sprintf(`SELECT count(node_id) FROM %s WHERE 1=1%s`, nodeInfoTable, condition)
+	err := n.db.Get(&total, cQuery, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -337,8 +546,8 @@ func (n *SQLDB) LoadNodeInfos(limit, offset int) (*sqlx.Rows, int64, error) {
 		limit = loadNodeInfosLimit
 	}
 
-	sQuery := fmt.Sprintf(`SELECT * FROM %s order by node_id asc LIMIT ? OFFSET ?`, nodeInfoTable)
-	rows, err := n.db.QueryxContext(context.Background(), sQuery, limit, offset)
+	sQuery := fmt.Sprintf(`SELECT * FROM %s WHERE 1=1%s order by node_id asc LIMIT ? OFFSET ?`, nodeInfoTable, condition)
+	rows, err := n.db.QueryxContext(context.Background(), sQuery, append(args, limit, offset)...)
 	return rows, total, err
 }
 
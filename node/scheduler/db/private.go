@@ -0,0 +1,50 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/linguohua/titan/api/types"
+)
+
+// SaveAssetKeyEnvelopes inserts the wrapped keys of the private asset or replaces the keys of the existing users
+func (n *SQLDB) SaveAssetKeyEnvelopes(infos []*types.AssetKeyEnvelope) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (hash, cid, user_id, wrapped_key)
+				VALUES (:hash, :cid, :user_id, :wrapped_key)
+				ON DUPLICATE KEY UPDATE wrapped_key=VALUES(wrapped_key)`, assetKeyEnvelopeTable)
+
+	_, err := n.db.NamedExec(query, infos)
+	return err
+}
+
+// LoadAssetKeyEnvelope load the wrapped key of the private asset for the user
+func (n *SQLDB) LoadAssetKeyEnvelope(hash, userID string) (*types.AssetKeyEnvelope, error) {
+	var info types.AssetKeyEnvelope
+	query := fmt.Sprintf("SELECT * FROM %s WHERE hash=? AND user_id=?", assetKeyEnvelopeTable)
+	err := n.db.Get(&info, query, hash, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// IsPrivateAsset checks if the asset has any wrapped key
+func (n *SQLDB) IsPrivateAsset(hash string) (bool, error) {
+	var count int
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE hash=?", assetKeyEnvelopeTable)
+	err := n.db.Get(&count, query, hash)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// DeleteAssetKeyEnvelope removes the wrapped key of the private asset for the user
+func (n *SQLDB) DeleteAssetKeyEnvelope(hash, userID string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND user_id=?`, assetKeyEnvelopeTable)
+	_, err := n.db.Exec(query, hash, userID)
+
+	return err
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/linguohua/titan/api/types"
+)
+
+// SaveS3AccessKey inserts an access key of the tenant
+func (n *SQLDB) SaveS3AccessKey(info *types.S3AccessKey) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (access_key, secret_key, tenant, replicas, expiration)
+				VALUES (:access_key, :secret_key, :tenant, :replicas, :expiration)`, s3AccessKeyTable)
+
+	_, err := n.db.NamedExec(query, info)
+	return err
+}
+
+// LoadS3AccessKey load the access key
+func (n *SQLDB) LoadS3AccessKey(accessKey string) (*types.S3AccessKey, error) {
+	var info types.S3AccessKey
+	query := fmt.Sprintf("SELECT * FROM %s WHERE access_key=?", s3AccessKeyTable)
+	err := n.db.Get(&info, query, accessKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// DeleteS3AccessKey removes the access key
+func (n *SQLDB) DeleteS3AccessKey(accessKey string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE access_key=?`, s3AccessKeyTable)
+	_, err := n.db.Exec(query, accessKey)
+
+	return err
+}
+
+// SaveS3Object inserts the object or replaces the asset of the existing object
+func (n *SQLDB) SaveS3Object(info *types.S3Object) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (tenant, bucket, object_key, cid, size, etag, content_type, last_modified)
+				VALUES (:tenant, :bucket, :object_key, :cid, :size, :etag, :content_type, NOW())
+				ON DUPLICATE KEY UPDATE cid=:cid, size=:size, etag=:etag, content_type=:content_type, last_modified=NOW()`, s3ObjectTable)
+
+	_, err := n.db.NamedExec(query, info)
+	return err
+}
+
+// LoadS3Object load the object of the tenant bucket
+func (n *SQLDB) LoadS3Object(tenant, bucket, key string) (*types.S3Object, error) {
+	var info types.S3Object
+	query := fmt.Sprintf("SELECT * FROM %s WHERE tenant=? AND bucket=? AND object_key=?", s3ObjectTable)
+	err := n.db.Get(&info, query, tenant, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// DeleteS3Object removes the object of the tenant bucket
+func (n *SQLDB) DeleteS3Object(tenant, bucket, key string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE tenant=? AND bucket=? AND object_key=?`, s3ObjectTable)
+	_, err := n.db.Exec(query, tenant, bucket, key)
+
+	return err
+}
+
+// LoadS3Objects load the objects of the tenant bucket with the prefix, the keys after startAfter are listed in order
+func (n *SQLDB) LoadS3Objects(tenant, bucket, prefix, startAfter string, limit int) ([]*types.S3Object, error) {
+	if limit > loadS3ObjectsLimit || limit <= 0 {
+		limit = loadS3ObjectsLimit
+	}
+
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE tenant=? AND bucket=? AND object_key LIKE ? AND object_key>?
+	    order by object_key LIMIT ?`, s3ObjectTable)
+
+	var out []*types.S3Object
+	if err := n.db.Select(&out, query, tenant, bucket, escapeLike(prefix)+"%", startAfter, limit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// escapeLike escapes the wildcards of the LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+)
+
+// SaveSeedTask inserts the seed task, the pull request of the task is gob encoded
+func (n *SQLDB) SaveSeedTask(task *types.SeedTask) error {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(task.Request); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (id, node_id, request, start_time, expiration, status) VALUES (?, ?, ?, ?, ?, ?)`, seedTaskTable)
+	_, err := n.db.Exec(query, task.ID, task.NodeID, buffer.Bytes(), task.StartTime, task.Expiration, task.Status)
+	return err
+}
+
+// LoadSeedTask load the seed task of the id
+func (n *SQLDB) LoadSeedTask(id string) (*types.SeedTask, error) {
+	var row struct {
+		NodeID     string              `db:"node_id"`
+		Request    []byte              `db:"request"`
+		StartTime  time.Time           `db:"start_time"`
+		Expiration time.Time           `db:"expiration"`
+		Status     types.ReplicaStatus `db:"status"`
+		CID        string              `db:"cid"`
+		Msg        string              `db:"msg"`
+	}
+
+	query := fmt.Sprintf(`SELECT node_id, request, start_time, expiration, status, cid, msg FROM %s WHERE id=?`, seedTaskTable)
+	if err := n.db.Get(&row, query, id); err != nil {
+		return nil, err
+	}
+
+	req := &types.PullAssetReq{}
+	if err := gob.NewDecoder(bytes.NewBuffer(row.Request)).Decode(req); err != nil {
+		return nil, err
+	}
+
+	return &types.SeedTask{
+		ID: id, NodeID: row.NodeID, Request: req, StartTime: row.StartTime, Expiration: row.Expiration,
+		Status: row.Status, CID: row.CID, Msg: row.Msg,
+	}, nil
+}
+
+// UpdateSeedTaskResult updates the result of the seed task in the from status, false is returned if the task is not in the status
+func (n *SQLDB) UpdateSeedTaskResult(id string, from, to types.ReplicaStatus, cid, msg string) (bool, error) {
+	query := fmt.Sprintf(`UPDATE %s SET status=?, cid=?, msg=? WHERE id=? AND status=?`, seedTaskTable)
+	result, err := n.db.Exec(query, to, cid, truncateMsg(msg), id, from)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// DeleteSeedTask removes the seed task, false is returned if it does not exist
+func (n *SQLDB) DeleteSeedTask(id string) (bool, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id=?`, seedTaskTable)
+	result, err := n.db.Exec(query, id)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// DeleteSeedTasksBefore removes the seed tasks that expire before the time
+func (n *SQLDB) DeleteSeedTasksBefore(t time.Time) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE expiration<?`, seedTaskTable)
+	_, err := n.db.Exec(query, t)
+	return err
+}
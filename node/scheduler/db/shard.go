@@ -0,0 +1,72 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/linguohua/titan/api/types"
+)
+
+// SaveAssetShards inserts the shards of the erasure coded asset or replaces the existing shards of the same index
+func (n *SQLDB) SaveAssetShards(infos []*types.AssetShard) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (hash, shard_index, cid, shard_hash, size, blocks)
+				VALUES (:hash, :shard_index, :cid, :shard_hash, :size, :blocks)
+				ON DUPLICATE KEY UPDATE cid=VALUES(cid), shard_hash=VALUES(shard_hash), size=VALUES(size), blocks=VALUES(blocks)`, assetShardTable)
+
+	_, err := n.db.NamedExec(query, infos)
+	return err
+}
+
+// LoadAssetShards load the shards of the erasure coded asset in the order of their index
+func (n *SQLDB) LoadAssetShards(hash string) ([]*types.AssetShard, error) {
+	var out []*types.AssetShard
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE hash=? ORDER BY shard_index`, assetShardTable)
+	if err := n.db.Select(&out, query, hash); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadShardOfHash load the shard whose own hash is the given hash
+func (n *SQLDB) LoadShardOfHash(shardHash string) (*types.AssetShard, error) {
+	var info types.AssetShard
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE shard_hash=?`, assetShardTable)
+	if err := n.db.Get(&info, query, shardHash); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// LoadShardReplicas load the replicas of all shards of the erasure coded asset, the hash of a replica is the hash of its shard
+func (n *SQLDB) LoadShardReplicas(hash string) ([]*types.ReplicaInfo, error) {
+	var out []*types.ReplicaInfo
+	query := fmt.Sprintf(`SELECT r.* FROM %s r JOIN %s s ON r.hash=s.shard_hash WHERE s.hash=?`, replicaInfoTable, assetShardTable)
+	if err := n.db.Select(&out, query, hash); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadUnfinishedShardPulls retrieves the shard cids of the erasure coded asset that the nodes have not finished pulling, keyed by node id
+func (n *SQLDB) LoadUnfinishedShardPulls(hash string) (map[string][]string, error) {
+	var rows []struct {
+		NodeID string `db:"node_id"`
+		CID    string `db:"cid"`
+	}
+
+	query := fmt.Sprintf(`SELECT r.node_id, s.cid FROM %s r JOIN %s s ON r.hash=s.shard_hash WHERE s.hash=? AND (r.status=? or r.status=?)`,
+		replicaInfoTable, assetShardTable)
+	if err := n.db.Select(&rows, query, hash, types.ReplicaStatusPulling, types.ReplicaStatusWaiting); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]string)
+	for _, row := range rows {
+		out[row.NodeID] = append(out[row.NodeID], row.CID)
+	}
+
+	return out, nil
+}
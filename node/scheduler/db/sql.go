@@ -29,10 +29,25 @@ const (
 	validationResultTable = "validation_result"
 	assetsViewTable       = "asset_view"
 	bucketTable           = "bucket"
+	replicaScalingTable   = "replica_scaling_record"
+	replicaMoveTable      = "replica_move_record"
+	nodeLabelTable        = "node_label"
+	assetEventTable       = "asset_event"
+	assetKeyEnvelopeTable = "asset_key_envelope"
+	assetShardTable       = "asset_shard"
+	s3AccessKeyTable      = "s3_access_key"
+	s3ObjectTable         = "s3_object"
+	seedTaskTable         = "seed_task"
 
 	loadNodeInfosLimit           = 100
 	loadReplicaInfosLimit        = 100
 	loadValidationResultsLimit   = 100
 	loadAssetRecordsLimit        = 100
 	loadExpiredAssetRecordsLimit = 100
+	loadScalingRecordsLimit      = 100
+	loadMoveRecordsLimit         = 100
+	loadAssetEventsLimit         = 100
+	loadS3ObjectsLimit           = 1000
+
+	maxMsgLength = 256 // size of the message columns, e.g. fail_msg of the replica
 )
@@ -8,6 +8,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/linguohua/titan/node/cidutil"
@@ -35,6 +36,12 @@ import (
 
 var log = logging.Logger("scheduler")
 
+const (
+	maxLabelKeyLen     = 64   // Maximum length of a node label key
+	maxLabelValueLen   = 128  // Maximum length of a node label value
+	labelReservedChars = ",=" // Characters that separate the labels and their keys and values
+)
+
 // Scheduler represents a scheduler node in a distributed system.
 type Scheduler struct {
 	fx.In
@@ -59,6 +66,7 @@ var _ api.Scheduler = &Scheduler{}
 type jwtPayload struct {
 	Allow  []auth.Permission
 	NodeID string
+	UserID string
 }
 
 // VerifyNodeAuthToken verifies the JWT token for a node.
@@ -74,6 +82,8 @@ func (s *Scheduler) VerifyNodeAuthToken(ctx context.Context, token string) ([]au
 		return nil, xerrors.Errorf("node id %s not match", nodeID)
 	}
 
+	handler.SetUserID(ctx, payload.UserID)
+
 	return payload.Allow, nil
 }
 
@@ -176,6 +186,21 @@ func (s *Scheduler) nodeConnect(ctx context.Context, opts *types.ConnectOptions,
 			return xerrors.Errorf("load node online duration %s err : %s", nodeID, err.Error())
 		}
 
+		maintenance, err := s.NodeManager.LoadNodeMaintenance(nodeID)
+		if err != nil && err != sql.ErrNoRows {
+			return xerrors.Errorf("load node maintenance %s err : %s", nodeID, err.Error())
+		}
+
+		labels, err := s.NodeManager.LoadNodeLabels(nodeID)
+		if err != nil {
+			return xerrors.Errorf("load node labels %s err : %s", nodeID, err.Error())
+		}
+
+		quarantine, err := s.NodeManager.LoadNodeQuarantine(nodeID)
+		if err != nil && err != sql.ErrNoRows {
+			return xerrors.Errorf("load node quarantine %s err : %s", nodeID, err.Error())
+		}
+
 		publicKey, err := titanrsa.Pem2PublicKey([]byte(pStr))
 		if err != nil {
 			return xerrors.Errorf("load node port %s err : %s", nodeID, err.Error())
@@ -184,6 +209,11 @@ func (s *Scheduler) nodeConnect(ctx context.Context, opts *types.ConnectOptions,
 		// init node info
 		nodeInfo.OnlineDuration = onlineDuration
 		nodeInfo.PortMapping = port
+		nodeInfo.Maintenance = maintenance
+		nodeInfo.Labels = labels
+		if quarantine != nil {
+			nodeInfo.NodeQuarantine = *quarantine
+		}
 		nodeInfo.ExternalIP, _, err = net.SplitHostPort(remoteAddr)
 		if err != nil {
 			return xerrors.Errorf("SplitHostPort err:%s", err.Error())
@@ -297,11 +327,33 @@ func (s *Scheduler) GetNodeInfo(ctx context.Context, nodeID string) (types.NodeI
 		}
 
 		nodeInfo = *dbInfo
+
+		nodeInfo.Labels, err = s.NodeManager.LoadNodeLabels(nodeID)
+		if err != nil {
+			log.Errorf("getNodeInfo LoadNodeLabels: %s ,nodeID : %s", err.Error(), nodeID)
+		}
 	}
 
+	nodeInfo.Failures = s.NodeManager.NodeFailures(nodeID)
+
 	return nodeInfo, nil
 }
 
+// GetNodeScores retrieves the selection scores of the online nodes for a given node type.
+func (s *Scheduler) GetNodeScores(ctx context.Context, nodeType types.NodeType) ([]*types.NodeScore, error) {
+	var nodes []*node.Node
+
+	if nodeType == types.NodeUnknown || nodeType == types.NodeCandidate {
+		nodes = append(nodes, s.NodeManager.GetCandidateNodeList()...)
+	}
+
+	if nodeType == types.NodeUnknown || nodeType == types.NodeEdge {
+		nodes = append(nodes, s.NodeManager.GetEdgeNodeList()...)
+	}
+
+	return s.NodeManager.ScoreNodes(nodes), nil
+}
+
 // UpdateNodePort sets the port for the specified node.
 func (s *Scheduler) UpdateNodePort(ctx context.Context, nodeID, port string) error {
 	baseInfo := s.NodeManager.GetNode(nodeID)
@@ -312,6 +364,94 @@ func (s *Scheduler) UpdateNodePort(ctx context.Context, nodeID, port string) err
 	return s.NodeManager.UpdatePortMapping(nodeID, port)
 }
 
+// SetNodeMaintenance sets the maintenance mode of the specified node, a cordoned node gets no new work,
+// and the replicas of a draining node are re-created on other nodes.
+func (s *Scheduler) SetNodeMaintenance(ctx context.Context, nodeID string, mode types.NodeMaintenance) error {
+	switch mode {
+	case types.NodeMaintenanceNone, types.NodeMaintenanceCordoned, types.NodeMaintenanceDraining:
+	default:
+		return xerrors.Errorf("unknown maintenance mode %s", mode)
+	}
+
+	err := s.NodeManager.UpdateNodeMaintenance(nodeID, mode)
+	if err != nil {
+		return err
+	}
+
+	if n := s.NodeManager.GetNode(nodeID); n != nil {
+		n.Maintenance = mode
+	}
+
+	log.Infof("node %s maintenance mode: %s", nodeID, mode)
+
+	return nil
+}
+
+// SetNodeQuarantine overrides the quarantine of the specified node, a quarantined node is not selected for new replicas.
+// A zero time releases the node.
+func (s *Scheduler) SetNodeQuarantine(ctx context.Context, nodeID string, until time.Time, reason string) error {
+	err := s.NodeManager.SetNodeQuarantine(nodeID, until, reason)
+	if err == sql.ErrNoRows {
+		return xerrors.Errorf("node %s not found", nodeID)
+	}
+
+	return err
+}
+
+// SetNodeLabels replaces the labels of the specified node, the labels constrain the nodes of the asset replicas.
+func (s *Scheduler) SetNodeLabels(ctx context.Context, nodeID string, labels map[string]string) error {
+	for key, value := range labels {
+		if err := checkNodeLabel(key, value); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.NodeManager.LoadNodeInfo(nodeID); err != nil {
+		return xerrors.Errorf("load node %s err:%s", nodeID, err.Error())
+	}
+
+	err := s.NodeManager.SaveNodeLabels(nodeID, labels)
+	if err != nil {
+		return err
+	}
+
+	if n := s.NodeManager.GetNode(nodeID); n != nil {
+		n.Labels = labels
+	}
+
+	log.Infof("node %s labels: %v", nodeID, labels)
+
+	return nil
+}
+
+// checkNodeLabel checks the key and value of a node label.
+func checkNodeLabel(key, value string) error {
+	if key == "" || len(key) > maxLabelKeyLen || len(value) > maxLabelValueLen {
+		return xerrors.Errorf("label %s=%s must have a key of 1-%d characters and a value of at most %d characters", key, value, maxLabelKeyLen, maxLabelValueLen)
+	}
+
+	if strings.ContainsAny(key, labelReservedChars) || strings.ContainsAny(value, labelReservedChars) {
+		return xerrors.Errorf("label %s=%s can not contain %q", key, value, labelReservedChars)
+	}
+
+	return nil
+}
+
+// GetNodeDrainProgress returns the replicas that are still on the specified node.
+func (s *Scheduler) GetNodeDrainProgress(ctx context.Context, nodeID string) (*types.NodeDrainProgress, error) {
+	mode, err := s.NodeManager.LoadNodeMaintenance(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.NodeManager.LoadNodeReplicaCount(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.NodeDrainProgress{NodeID: nodeID, Maintenance: mode, RemainingReplicas: count}, nil
+}
+
 // nodeExists checks if the node with the specified ID exists.
 func (s *Scheduler) nodeExists(nodeID string, nodeType types.NodeType) bool {
 	err := s.NodeManager.NodeExists(nodeID, nodeType)
@@ -336,10 +476,10 @@ func (s *Scheduler) NodeExists(ctx context.Context, nodeID string) error {
 }
 
 // GetNodeList retrieves a list of nodes with pagination.
-func (s *Scheduler) GetNodeList(ctx context.Context, offset int, limit int) (*types.ListNodesRsp, error) {
+func (s *Scheduler) GetNodeList(ctx context.Context, offset int, limit int, selector *types.LabelSelector) (*types.ListNodesRsp, error) {
 	rsp := &types.ListNodesRsp{Data: make([]types.NodeInfo, 0)}
 
-	rows, total, err := s.NodeManager.LoadNodeInfos(limit, offset)
+	rows, total, err := s.NodeManager.LoadNodeInfos(limit, offset, selector)
 	if err != nil {
 		return rsp, err
 	}
@@ -371,6 +511,20 @@ func (s *Scheduler) GetNodeList(ctx context.Context, offset int, limit int) (*ty
 		nodeInfos = append(nodeInfos, *nodeInfo)
 	}
 
+	nodeIDs := make([]string, 0, len(nodeInfos))
+	for _, nodeInfo := range nodeInfos {
+		nodeIDs = append(nodeIDs, nodeInfo.NodeID)
+	}
+
+	labels, err := s.NodeManager.LoadNodesLabels(nodeIDs)
+	if err != nil {
+		log.Errorf("LoadNodesLabels err: %s", err.Error())
+	}
+
+	for i := range nodeInfos {
+		nodeInfos[i].Labels = labels[nodeInfos[i].NodeID]
+	}
+
 	rsp.Data = nodeInfos
 	rsp.Total = total
 
@@ -398,6 +552,11 @@ func (s *Scheduler) GetSchedulerPublicKey(ctx context.Context) (string, error) {
 	return string(pem), nil
 }
 
+// GetAssetPullSources finds the download sources of the asset or shard for the pulling nodes.
+func (s *Scheduler) GetAssetPullSources(ctx context.Context, cid string) ([]*types.CandidateDownloadInfo, error) {
+	return s.AssetManager.GetPullSources(cid)
+}
+
 // GetCandidateDownloadInfos finds candidate download info for the given CID.
 func (s *Scheduler) GetCandidateDownloadInfos(ctx context.Context, cid string) ([]*types.CandidateDownloadInfo, error) {
 	hash, err := cidutil.CIDToHash(cid)
@@ -408,6 +567,11 @@ func (s *Scheduler) GetCandidateDownloadInfos(ctx context.Context, cid string) (
 	titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
 	sources := make([]*types.CandidateDownloadInfo, 0)
 
+	private, err := s.NodeManager.IsPrivateAsset(hash)
+	if err != nil {
+		return nil, err
+	}
+
 	rows, err := s.NodeManager.LoadReplicasByHash(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
 	if err != nil {
 		return nil, err
@@ -432,7 +596,7 @@ func (s *Scheduler) GetCandidateDownloadInfos(ctx context.Context, cid string) (
 			continue
 		}
 
-		credentials, err := cNode.Credentials(cid, titanRsa, s.NodeManager.PrivateKey)
+		credentials, err := cNode.Credentials(cid, private, titanRsa, s.NodeManager.PrivateKey)
 		if err != nil {
 			continue
 		}
@@ -26,9 +26,9 @@ func (m *Manager) NodesQuit(nodeIDs []string) {
 		return
 	}
 
+	// the lost replicas are re-created by the replica repair of the asset manager
 	for _, hash := range hashes {
-		// TODO change asset state
-		log.Infof("NodesQuit: Need to add replica for asset:%s", hash)
+		log.Infof("NodesQuit: asset %s lost replicas, waiting for repair", hash)
 	}
 }
 
@@ -44,6 +44,28 @@ func (m *Manager) GetAllCandidateNodes() []string {
 	return out
 }
 
+// GetCandidateNodeList returns all online candidate nodes
+func (m *Manager) GetCandidateNodeList() []*Node {
+	var out []*Node
+	m.candidateNodes.Range(func(key, value interface{}) bool {
+		out = append(out, value.(*Node))
+		return true
+	})
+
+	return out
+}
+
+// GetEdgeNodeList returns all online edge nodes
+func (m *Manager) GetEdgeNodeList() []*Node {
+	var out []*Node
+	m.edgeNodes.Range(func(key, value interface{}) bool {
+		out = append(out, value.(*Node))
+		return true
+	})
+
+	return out
+}
+
 // GetNode retrieves a node with the given node ID
 func (m *Manager) GetNode(nodeID string) *Node {
 	edge := m.GetEdgeNode(nodeID)
@@ -50,6 +50,12 @@ type Manager struct {
 	cUndistributedNodeNum map[int]string // Undistributed candidate node numbers
 	eDistributedNodeNum   map[int]string // Already allocated edge node numbers
 	eUndistributedNodeNum map[int]string // Undistributed edge node numbers
+
+	validationRateLock sync.RWMutex
+	validationRates    map[string]float64 // Recent validation success rates of nodes
+
+	failureLock sync.Mutex
+	failures    map[string]*types.NodeFailureStats // Recent failures of nodes counted for the quarantine
 }
 
 // NewManager creates a new instance of the node manager
@@ -68,9 +74,11 @@ func NewManager(sdb *db.SQLDB, serverID dtypes.ServerID, k *rsa.PrivateKey, p *p
 		cUndistributedNodeNum: make(map[int]string),
 		eDistributedNodeNum:   make(map[int]string),
 		eUndistributedNodeNum: make(map[int]string),
+		failures:              make(map[string]*types.NodeFailureStats),
 	}
 
 	go nodeManager.run()
+	go nodeManager.validationRateCheck()
 
 	return nodeManager
 }
@@ -162,6 +170,8 @@ func (m *Manager) nodeKeepalive(node *Node, t time.Time, isSave bool) {
 	nodeID := node.NodeID
 
 	if !lastTime.After(t) {
+		m.RecordNodeFailure(nodeID, types.NodeFailureKeepalive)
+
 		node.ClientCloser()
 		if node.Type == types.NodeCandidate {
 			m.deleteCandidateNode(node)
@@ -7,6 +7,7 @@ import (
 	"encoding/gob"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"net/http"
 	"strings"
 	"time"
@@ -21,6 +22,9 @@ import (
 	"github.com/filecoin-project/go-jsonrpc"
 )
 
+// areaGridDegrees is the size of the grid cell used as area when the node has no ip location
+const areaGridDegrees = 5.0
+
 // Node represents an Edge or Candidate node
 type Node struct {
 	*API
@@ -179,6 +183,26 @@ func (n *Node) DownloadAddr() string {
 	return addr
 }
 
+// Area returns the area of the node, the ip location is preferred, otherwise a grid cell of the coordinates is used
+func (n *Node) Area() string {
+	if n.IPLocation != "" {
+		return n.IPLocation
+	}
+
+	if n.Latitude == 0 && n.Longitude == 0 {
+		return ""
+	}
+
+	lat := math.Floor(n.Latitude/areaGridDegrees) * areaGridDegrees
+	lon := math.Floor(n.Longitude/areaGridDegrees) * areaGridDegrees
+	return fmt.Sprintf("%.0f,%.0f", lat, lon)
+}
+
+// InMaintenance checks if the node is cordoned or draining, no new work is assigned to such node
+func (n *Node) InMaintenance() bool {
+	return n.Maintenance != types.NodeMaintenanceNone
+}
+
 // LastRequestTime returns the last request time of the node
 func (n *Node) LastRequestTime() time.Time {
 	return n.lastRequestTime
@@ -209,16 +233,41 @@ func (n *Node) UpdateNodePort(port string) {
 	n.PortMapping = port
 }
 
-// Credentials returns the credentials of the node
-func (n *Node) Credentials(cid string, titanRsa *titanrsa.Rsa, privateKey *rsa.PrivateKey) (*types.GatewayCredentials, error) {
+// Credentials returns the credentials of the node, the node only serves a private asset in raw and car formats
+func (n *Node) Credentials(cid string, private bool, titanRsa *titanrsa.Rsa, privateKey *rsa.PrivateKey) (*types.GatewayCredentials, error) {
 	svc := &types.Credentials{
 		ID:        uuid.NewString(),
 		NodeID:    n.NodeID,
 		AssetCID:  cid,
 		ValidTime: time.Now().Add(10 * time.Hour).Unix(),
+		Private:   private,
 	}
 
-	b, err := n.encryptCredentials(svc, n.publicKey, titanRsa)
+	return n.signCredentials(svc, titanRsa, privateKey)
+}
+
+// UploadTicket returns the ticket to upload an asset of the max size to the node, the upload must be finished in the upload ttl after the ticket expires
+func (n *Node) UploadTicket(maxSize int64, validTime, uploadTTL time.Duration, titanRsa *titanrsa.Rsa, privateKey *rsa.PrivateKey) (*types.UploadTicket, *types.GatewayCredentials, error) {
+	valid := time.Now().Add(validTime)
+	ticket := &types.UploadTicket{
+		ID:         uuid.NewString(),
+		NodeID:     n.NodeID,
+		MaxSize:    maxSize,
+		ValidTime:  valid.Unix(),
+		Expiration: valid.Add(uploadTTL).Unix(),
+	}
+
+	credentials, err := n.signCredentials(ticket, titanRsa, privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ticket, credentials, nil
+}
+
+// signCredentials encrypts the credentials with the node public key and signs them with the scheduler private key
+func (n *Node) signCredentials(v interface{}, titanRsa *titanrsa.Rsa, privateKey *rsa.PrivateKey) (*types.GatewayCredentials, error) {
+	b, err := n.encryptCredentials(v, n.publicKey, titanRsa)
 	if err != nil {
 		return nil, xerrors.Errorf("%s encryptCredentials err:%s", n.NodeID, err.Error())
 	}
@@ -231,8 +280,8 @@ func (n *Node) Credentials(cid string, titanRsa *titanrsa.Rsa, privateKey *rsa.P
 	return &types.GatewayCredentials{Ciphertext: hex.EncodeToString(b), Sign: hex.EncodeToString(sign)}, nil
 }
 
-// encryptCredentials encrypts a Credentials object using the given public key and RSA instance.
-func (n *Node) encryptCredentials(at *types.Credentials, publicKey *rsa.PublicKey, rsa *titanrsa.Rsa) ([]byte, error) {
+// encryptCredentials encrypts the gob encoded credentials using the given public key and RSA instance.
+func (n *Node) encryptCredentials(at interface{}, publicKey *rsa.PublicKey, rsa *titanrsa.Rsa) ([]byte, error) {
 	var buffer bytes.Buffer
 	enc := gob.NewEncoder(&buffer)
 	err := enc.Encode(at)
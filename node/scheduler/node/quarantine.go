@@ -0,0 +1,144 @@
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+)
+
+const (
+	// quarantineThreshold is the number of failures within the window that quarantines the node
+	quarantineThreshold = 10
+	// quarantineWindow is the window in which the failures of a node are counted
+	quarantineWindow = time.Hour
+	// quarantineBackoff is the period of the first quarantine, each successive quarantine doubles it
+	quarantineBackoff = 30 * time.Minute
+	// maxQuarantineBackoff is the maximum period of a quarantine
+	maxQuarantineBackoff = 24 * time.Hour
+	// quarantineResetPeriod is the period after the release that resets the successive quarantines
+	quarantineResetPeriod = 24 * time.Hour
+)
+
+// RecordNodeFailure counts a failure of the node, the node is quarantined if its failures reach the threshold within the window
+func (m *Manager) RecordNodeFailure(nodeID string, kind types.NodeFailureKind) {
+	m.failureLock.Lock()
+
+	stats, exist := m.failures[nodeID]
+	if !exist || time.Since(stats.Since) > quarantineWindow {
+		stats = &types.NodeFailureStats{Since: time.Now()}
+		m.failures[nodeID] = stats
+	}
+
+	switch kind {
+	case types.NodeFailurePull:
+		stats.Pulls++
+	case types.NodeFailureValidation:
+		stats.Validations++
+	case types.NodeFailureKeepalive:
+		stats.KeepaliveDrops++
+	case types.NodeFailureSource:
+		stats.SourceErrors++
+	}
+
+	reached := stats.Total() >= quarantineThreshold
+	if reached {
+		// the counting restarts after the quarantine
+		delete(m.failures, nodeID)
+	}
+
+	m.failureLock.Unlock()
+
+	if !reached {
+		return
+	}
+
+	reason := fmt.Sprintf("%d pull, %d validation and %d keepalive failures in %s", stats.Pulls, stats.Validations, stats.KeepaliveDrops, time.Since(stats.Since).Truncate(time.Second))
+	if err := m.quarantineNode(nodeID, reason); err != nil {
+		log.Errorf("quarantine node %s err:%s", nodeID, err.Error())
+	}
+}
+
+// NodeFailures returns the failures of the node in the current window, nil if there are none
+func (m *Manager) NodeFailures(nodeID string) *types.NodeFailureStats {
+	m.failureLock.Lock()
+	defer m.failureLock.Unlock()
+
+	stats, exist := m.failures[nodeID]
+	if !exist || time.Since(stats.Since) > quarantineWindow {
+		return nil
+	}
+
+	out := *stats
+	return &out
+}
+
+// quarantineNode quarantines the node for the backoff period of its successive quarantines
+func (m *Manager) quarantineNode(nodeID, reason string) error {
+	q, err := m.LoadNodeQuarantine(nodeID)
+	if err != nil {
+		return err
+	}
+
+	if q.InQuarantine() {
+		return nil
+	}
+
+	if time.Since(q.QuarantineUntil) > quarantineResetPeriod {
+		q.QuarantineCount = 0
+	}
+
+	backoff := maxQuarantineBackoff
+	if q.QuarantineCount < 16 {
+		backoff = quarantineBackoff << q.QuarantineCount
+	}
+	if backoff > maxQuarantineBackoff {
+		backoff = maxQuarantineBackoff
+	}
+
+	q.QuarantineUntil = time.Now().Add(backoff)
+	q.QuarantineReason = reason
+	q.QuarantineCount++
+
+	log.Infof("node %s quarantined until %s: %s", nodeID, q.QuarantineUntil.Format(time.RFC3339), reason)
+
+	return m.saveNodeQuarantine(nodeID, q)
+}
+
+// SetNodeQuarantine overrides the quarantine of the node, a zero time releases the node and clears its failures
+func (m *Manager) SetNodeQuarantine(nodeID string, until time.Time, reason string) error {
+	q, err := m.LoadNodeQuarantine(nodeID)
+	if err != nil {
+		return err
+	}
+
+	if until.IsZero() {
+		m.failureLock.Lock()
+		delete(m.failures, nodeID)
+		m.failureLock.Unlock()
+
+		until = time.Now()
+		q.QuarantineCount = 0
+	}
+
+	q.QuarantineUntil = until
+	q.QuarantineReason = reason
+
+	log.Infof("node %s quarantine set until %s: %s", nodeID, until.Format(time.RFC3339), reason)
+
+	return m.saveNodeQuarantine(nodeID, q)
+}
+
+// saveNodeQuarantine saves the quarantine and applies it to the online node
+func (m *Manager) saveNodeQuarantine(nodeID string, q *types.NodeQuarantine) error {
+	err := m.UpdateNodeQuarantine(nodeID, q)
+	if err != nil {
+		return err
+	}
+
+	if n := m.GetNode(nodeID); n != nil {
+		n.NodeQuarantine = *q
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package node
+
+import (
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+)
+
+const (
+	// validationRateInterval is the interval at which the validation success rates are reloaded
+	validationRateInterval = 10 * time.Minute
+	// validationRatePeriod is the period of validation results used for the success rate
+	validationRatePeriod = 7 * 24 * time.Hour
+	// fullScoreOnlineDuration is the online duration that gets the full uptime score (Unit:Minute)
+	fullScoreOnlineDuration = 7 * 24 * 60
+	// minNodeScore keeps every node a small chance to be selected
+	minNodeScore = 0.01
+
+	// weights of the score factors
+	freeDiskWeight   = 0.3
+	bandwidthWeight  = 0.2
+	loadWeight       = 0.2
+	validationWeight = 0.2
+	uptimeWeight     = 0.1
+)
+
+// validationRateCheck Periodically reloads the validation success rates of the nodes
+func (m *Manager) validationRateCheck() {
+	ticker := time.NewTicker(validationRateInterval)
+	defer ticker.Stop()
+
+	for {
+		m.loadValidationRates()
+		<-ticker.C
+	}
+}
+
+// loadValidationRates loads the recent validation success rates of the nodes
+func (m *Manager) loadValidationRates() {
+	rates, err := m.LoadValidationSuccessRates(time.Now().Add(-validationRatePeriod))
+	if err != nil {
+		log.Errorf("LoadValidationSuccessRates err:%s", err.Error())
+		return
+	}
+
+	m.validationRateLock.Lock()
+	defer m.validationRateLock.Unlock()
+
+	m.validationRates = rates
+}
+
+// validationRate returns the validation success rate of the node, nodes without validation results get the full rate
+func (m *Manager) validationRate(nodeID string) float64 {
+	m.validationRateLock.RLock()
+	defer m.validationRateLock.RUnlock()
+
+	rate, exist := m.validationRates[nodeID]
+	if !exist {
+		return 1
+	}
+
+	return rate
+}
+
+// ScoreNodes calculates the selection scores of the nodes,
+// the bandwidth factor is relative to the maximum bandwidth of the given nodes
+func (m *Manager) ScoreNodes(nodes []*Node) []*types.NodeScore {
+	maxBandwidth := 0.0
+	for _, n := range nodes {
+		if n.BandwidthUp > maxBandwidth {
+			maxBandwidth = n.BandwidthUp
+		}
+	}
+
+	out := make([]*types.NodeScore, 0, len(nodes))
+	for _, n := range nodes {
+		score := &types.NodeScore{
+			NodeID:          n.NodeID,
+			FreeDisk:        (100 - n.DiskUsage) / 100,
+			Load:            1 / float64(1+n.CurPullingCount()),
+			Validation:      m.validationRate(n.NodeID),
+			Uptime:          float64(n.OnlineDuration) / fullScoreOnlineDuration,
+			CurPullingCount: n.CurPullingCount(),
+		}
+
+		if score.FreeDisk < 0 {
+			score.FreeDisk = 0
+		}
+
+		if maxBandwidth > 0 {
+			score.Bandwidth = n.BandwidthUp / maxBandwidth
+		}
+
+		if score.Uptime > 1 {
+			score.Uptime = 1
+		}
+
+		score.Score = freeDiskWeight*score.FreeDisk + bandwidthWeight*score.Bandwidth + loadWeight*score.Load +
+			validationWeight*score.Validation + uptimeWeight*score.Uptime
+		if score.Score < minNodeScore {
+			score.Score = minNodeScore
+		}
+
+		out = append(out, score)
+	}
+
+	return out
+}
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gbrlsnchs/jwt/v3"
+	"github.com/linguohua/titan/api"
+	"github.com/linguohua/titan/api/types"
+	"github.com/linguohua/titan/node/cidutil"
+	"github.com/linguohua/titan/node/handler"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// maxUserIDLength is the size of the user id column
+	maxUserIDLength = 128
+	// maxWrappedKeyLength is the size of the wrapped key column
+	maxWrappedKeyLength = 1024
+)
+
+// CreateUserToken creates a read token of the user, the user id of the token is the user of the requests sent with it.
+func (s *Scheduler) CreateUserToken(ctx context.Context, userID string) (string, error) {
+	if userID == "" || len(userID) > maxUserIDLength {
+		return "", xerrors.Errorf("user id length must be between 1 and %d", maxUserIDLength)
+	}
+
+	p := jwtPayload{
+		Allow:  api.DefaultPerms,
+		UserID: userID,
+	}
+
+	tk, err := jwt.Sign(&p, s.APISecret)
+	if err != nil {
+		return "", xerrors.Errorf("user %s sign err:%s", userID, err.Error())
+	}
+
+	return string(tk), nil
+}
+
+// SetAssetKeyEnvelopes saves the wrapped data keys of the private asset, the existing keys of the users are replaced.
+func (s *Scheduler) SetAssetKeyEnvelopes(ctx context.Context, cid string, envelopes []*types.AssetKeyEnvelope) error {
+	if len(envelopes) == 0 {
+		return xerrors.New("envelopes is nil")
+	}
+
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	for _, envelope := range envelopes {
+		if envelope.UserID == "" || len(envelope.UserID) > maxUserIDLength {
+			return xerrors.Errorf("user id length must be between 1 and %d", maxUserIDLength)
+		}
+
+		if len(envelope.WrappedKey) == 0 || len(envelope.WrappedKey) > maxWrappedKeyLength {
+			return xerrors.Errorf("user %s wrapped key length must be between 1 and %d", envelope.UserID, maxWrappedKeyLength)
+		}
+
+		envelope.Hash = hash
+		envelope.CID = cid
+	}
+
+	return s.NodeManager.SaveAssetKeyEnvelopes(envelopes)
+}
+
+// RemoveAssetKeyEnvelope removes the wrapped data key of the private asset for the user.
+func (s *Scheduler) RemoveAssetKeyEnvelope(ctx context.Context, cid, userID string) error {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	return s.NodeManager.DeleteAssetKeyEnvelope(hash, userID)
+}
+
+// loadKeyEnvelope returns the wrapped data key of the asset for the user of the token, nil if the user is not authorised.
+// An error is returned if the request is not sent with a user token.
+func (s *Scheduler) loadKeyEnvelope(ctx context.Context, hash string) (*types.AssetKeyEnvelope, error) {
+	userID := handler.GetUserID(ctx)
+	if userID == "" {
+		return nil, xerrors.New("private asset requires a user token")
+	}
+
+	envelope, err := s.NodeManager.LoadAssetKeyEnvelope(hash, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return envelope, err
+}
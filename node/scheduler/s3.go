@@ -0,0 +1,204 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"encoding/base64"
+	"time"
+
+	"github.com/linguohua/titan/api/types"
+	"github.com/linguohua/titan/node/cidutil"
+	"github.com/linguohua/titan/node/handler"
+	"github.com/linguohua/titan/node/scheduler/assets"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// maxS3TenantLength is the size of the tenant column
+	maxS3TenantLength = 64
+	// maxS3BucketLength is the maximum length of a bucket name
+	maxS3BucketLength = 63
+	// maxS3KeyLength is the maximum length of an object key
+	maxS3KeyLength = 1024
+)
+
+// CreateS3AccessKey creates an access key of the tenant, the secret is only returned here.
+func (s *Scheduler) CreateS3AccessKey(ctx context.Context, req *types.S3AccessKeyReq) (*types.S3AccessKey, error) {
+	if req.Tenant == "" || len(req.Tenant) > maxS3TenantLength {
+		return nil, xerrors.Errorf("tenant length must be between 1 and %d", maxS3TenantLength)
+	}
+
+	if req.Replicas < 1 {
+		return nil, xerrors.Errorf("replicas %d must greater than 1", req.Replicas)
+	}
+
+	if req.Expiration <= 0 {
+		return nil, xerrors.Errorf("expiration %s must greater than 0", req.Expiration.String())
+	}
+
+	accessKey, err := randomKey(15)
+	if err != nil {
+		return nil, err
+	}
+
+	secretKey, err := randomKey(30)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &types.S3AccessKey{
+		AccessKey:   base32.StdEncoding.EncodeToString(accessKey)[:20],
+		SecretKey:   base64.RawStdEncoding.EncodeToString(secretKey),
+		Tenant:      req.Tenant,
+		Replicas:    req.Replicas,
+		Expiration:  int64(req.Expiration / time.Second),
+		CreatedTime: time.Now(),
+	}
+
+	if err := s.NodeManager.SaveS3AccessKey(info); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// RemoveS3AccessKey removes the access key.
+func (s *Scheduler) RemoveS3AccessKey(ctx context.Context, accessKey string) error {
+	return s.NodeManager.DeleteS3AccessKey(accessKey)
+}
+
+// GetS3AccessKey retrieves the access key for the candidate to verify the request signature, nil if the key does not exist.
+func (s *Scheduler) GetS3AccessKey(ctx context.Context, accessKey string) (*types.S3AccessKey, error) {
+	info, err := s.loadS3AccessKey(ctx, accessKey)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return info, err
+}
+
+// PutS3Object saves the object stored by the candidate, the asset is pulled with the candidate as the seed if it is not pulled yet.
+// The existing asset is kept at least until the expiration of the object, a pending delete asset is restored.
+func (s *Scheduler) PutS3Object(ctx context.Context, accessKey string, obj *types.S3Object) error {
+	nodeID := handler.GetNodeID(ctx)
+
+	key, err := s.loadS3AccessKey(ctx, accessKey)
+	if err != nil {
+		return err
+	}
+
+	if err := checkS3ObjectKey(obj.Bucket, obj.Key); err != nil {
+		return err
+	}
+
+	hash, err := cidutil.CIDToHash(obj.CID)
+	if err != nil {
+		return xerrors.Errorf("%s cid to hash err:%s", obj.CID, err.Error())
+	}
+
+	record, err := s.AssetManager.LoadAssetRecord(hash)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	expiration := time.Now().Add(time.Duration(key.Expiration) * time.Second)
+
+	switch {
+	case record == nil:
+		info := &types.PullAssetReq{
+			CID:        obj.CID,
+			Hash:       hash,
+			Replicas:   key.Replicas,
+			Expiration: expiration,
+		}
+
+		if err := s.AssetManager.CreateSeededAssetPullTask(info, nodeID); err != nil {
+			return err
+		}
+	case record.State == assets.PendingDelete.String():
+		if record.Expiration.After(expiration) {
+			expiration = record.Expiration
+		}
+
+		if err := s.AssetManager.RestoreAsset(obj.CID, hash, expiration); err != nil {
+			return err
+		}
+	case record.Expiration.Before(expiration):
+		if err := s.AssetManager.UpdateAssetExpiration(obj.CID, expiration); err != nil {
+			return err
+		}
+	}
+
+	obj.Tenant = key.Tenant
+	return s.NodeManager.SaveS3Object(obj)
+}
+
+// GetS3Object retrieves the object of the bucket, nil if the object does not exist.
+func (s *Scheduler) GetS3Object(ctx context.Context, accessKey, bucket, key string) (*types.S3Object, error) {
+	info, err := s.loadS3AccessKey(ctx, accessKey)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := s.NodeManager.LoadS3Object(info.Tenant, bucket, key)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return obj, err
+}
+
+// RemoveS3Object removes the object of the bucket.
+func (s *Scheduler) RemoveS3Object(ctx context.Context, accessKey, bucket, key string) error {
+	info, err := s.loadS3AccessKey(ctx, accessKey)
+	if err != nil {
+		return err
+	}
+
+	return s.NodeManager.DeleteS3Object(info.Tenant, bucket, key)
+}
+
+// ListS3Objects lists the objects of the bucket in the order of the keys.
+func (s *Scheduler) ListS3Objects(ctx context.Context, accessKey string, req *types.S3ListObjectsReq) ([]*types.S3Object, error) {
+	info, err := s.loadS3AccessKey(ctx, accessKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.NodeManager.LoadS3Objects(info.Tenant, req.Bucket, req.Prefix, req.StartAfter, req.Limit)
+}
+
+// loadS3AccessKey loads the access key, only the candidates serve the S3 compatible api.
+func (s *Scheduler) loadS3AccessKey(ctx context.Context, accessKey string) (*types.S3AccessKey, error) {
+	nodeID := handler.GetNodeID(ctx)
+	if s.NodeManager.GetCandidateNode(nodeID) == nil {
+		return nil, xerrors.Errorf("node %s is not an online candidate", nodeID)
+	}
+
+	return s.NodeManager.LoadS3AccessKey(accessKey)
+}
+
+// checkS3ObjectKey validates the bucket and the key of the object.
+func checkS3ObjectKey(bucket, key string) error {
+	if bucket == "" || len(bucket) > maxS3BucketLength {
+		return xerrors.Errorf("bucket length must be between 1 and %d", maxS3BucketLength)
+	}
+
+	if key == "" || len(key) > maxS3KeyLength {
+		return xerrors.Errorf("key length must be between 1 and %d", maxS3KeyLength)
+	}
+
+	return nil
+}
+
+// randomKey returns the random bytes of the size.
+func randomKey(size int) ([]byte, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
@@ -2,6 +2,7 @@ package validation
 
 import (
 	"context"
+	"database/sql"
 	"math/rand"
 	"time"
 
@@ -115,7 +116,16 @@ func (m *Manager) getValidationDetails(vrs []*VWindow) (map[string]*api.Validate
 			continue
 		}
 
+		if vNode.InMaintenance() {
+			log.Infof("%s validator in maintenance %s", vID, vNode.Maintenance)
+			continue
+		}
+
 		for nodeID := range vr.ValidatableNodes {
+			if n := m.nodeMgr.GetNode(nodeID); n != nil && n.InMaintenance() {
+				continue
+			}
+
 			cid, err := m.getNodeValidationCID(nodeID)
 			if err != nil {
 				log.Errorf("%s getNodeValidationCID err:%s", nodeID, err.Error())
@@ -145,6 +155,7 @@ func (m *Manager) getValidationDetails(vrs []*VWindow) (map[string]*api.Validate
 }
 
 // getNodeValidationCID retrieves a random validation CID from the node with the given ID.
+// A node holding a shard of an erasure coded asset is validated on the shard, which it holds completely.
 func (m *Manager) getNodeValidationCID(nodeID string) (string, error) {
 	count, err := m.nodeMgr.LoadNodeReplicaCount(nodeID)
 	if err != nil {
@@ -171,6 +182,25 @@ func (m *Manager) getNodeValidationCID(nodeID string) (string, error) {
 	return cids[0], nil
 }
 
+// loadAssetBlocks returns the number of blocks of the asset, the hash may be the hash of a shard of an erasure coded asset
+func (m *Manager) loadAssetBlocks(hash string) (int64, error) {
+	record, err := m.nodeMgr.LoadAssetRecord(hash)
+	if err == nil {
+		return record.TotalBlocks, nil
+	}
+
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	shard, err := m.nodeMgr.LoadShardOfHash(hash)
+	if err != nil {
+		return 0, err
+	}
+
+	return shard.Blocks, nil
+}
+
 // getRandNum generates a random number up to a given maximum value.
 func (m *Manager) getRandNum(max int, r *rand.Rand) int {
 	if max > 0 {
@@ -206,6 +236,10 @@ func (m *Manager) HandleResult(vr *api.ValidationResult) error {
 		if err != nil {
 			log.Errorf("updateResultInfo [%s] fail : %s", nodeID, err.Error())
 		}
+
+		if status == types.ValidationStatusValidateFail || status == types.ValidationStatusNodeTimeOut {
+			m.nodeMgr.RecordNodeFailure(nodeID, types.NodeFailureValidation)
+		}
 	}()
 
 	if vr.IsCancel {
@@ -286,7 +320,7 @@ func (m *Manager) HandleResult(vr *api.ValidationResult) error {
 		return nil
 	}
 
-	record, err := m.nodeMgr.LoadAssetRecord(hash)
+	totalBlocks, err := m.loadAssetBlocks(hash)
 	if err != nil {
 		status = types.ValidationStatusLoadDBErr
 		log.Errorf("handleValidationResult asset record %s , err:%s", vr.CID, err.Error())
@@ -297,7 +331,7 @@ func (m *Manager) HandleResult(vr *api.ValidationResult) error {
 	// do validate
 	for i := 0; i < cidCount; i++ {
 		resultCid := vr.Cids[i]
-		randNum := m.getRandNum(int(record.TotalBlocks), r)
+		randNum := m.getRandNum(int(totalBlocks), r)
 		vCid := cCidMap[randNum]
 
 		// TODO Penalize the candidate if vCid error
@@ -7,14 +7,30 @@ import (
 	"github.com/linguohua/titan/api/types"
 	"github.com/linguohua/titan/node/cidutil"
 	titanrsa "github.com/linguohua/titan/node/rsa"
+	"github.com/linguohua/titan/node/scheduler/assets"
 	"golang.org/x/xerrors"
 )
 
+// SubmitUserProofsOfWork records the user downloads of the assets, they are weighted into the asset demand up to the download info requests
 func (s *Scheduler) SubmitUserProofsOfWork(ctx context.Context, proofs []*types.UserProofOfWork) error {
+	for _, proof := range proofs {
+		if proof.AssetCID == "" {
+			continue
+		}
+
+		hash, err := cidutil.CIDToHash(proof.AssetCID)
+		if err != nil {
+			log.Errorf("SubmitUserProofsOfWork %s cid to hash err:%s", proof.AssetCID, err.Error())
+			continue
+		}
+
+		s.AssetManager.RecordDownload(hash, proof.DownloadSize)
+	}
+
 	return nil
 }
 
-// GetEdgeDownloadInfos finds edge download information for a given CID
+// GetEdgeDownloadInfos finds edge download information for a given CID, the wrapped key of a private asset is returned to the authorised user
 func (s *Scheduler) GetEdgeDownloadInfos(ctx context.Context, cid string) (*types.EdgeDownloadInfoList, error) {
 	if cid == "" {
 		return nil, xerrors.New("cids is nil")
@@ -25,6 +41,31 @@ func (s *Scheduler) GetEdgeDownloadInfos(ctx context.Context, cid string) (*type
 		return nil, xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
 	}
 
+	ret := &types.EdgeDownloadInfoList{
+		Infos:        make([]*types.EdgeDownloadInfo, 0),
+		SchedulerURL: s.SchedulerCfg.RPCURL,
+	}
+
+	record, err := s.NodeManager.LoadAssetRecord(hash)
+	if err == nil && record.State == assets.PendingDelete.String() {
+		// the replicas of a pending delete asset are kept but not served
+		return ret, nil
+	}
+
+	s.AssetManager.RecordDownloadRequest(hash)
+
+	private, err := s.NodeManager.IsPrivateAsset(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if private {
+		ret.KeyEnvelope, err = s.loadKeyEnvelope(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	rows, err := s.NodeManager.LoadReplicasByHash(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
 	if err != nil {
 		return nil, err
@@ -32,7 +73,6 @@ func (s *Scheduler) GetEdgeDownloadInfos(ctx context.Context, cid string) (*type
 	defer rows.Close()
 
 	titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
-	infos := make([]*types.EdgeDownloadInfo, 0)
 
 	for rows.Next() {
 		rInfo := &types.ReplicaInfo{}
@@ -48,11 +88,11 @@ func (s *Scheduler) GetEdgeDownloadInfos(ctx context.Context, cid string) (*type
 
 		nodeID := rInfo.NodeID
 		eNode := s.NodeManager.GetEdgeNode(nodeID)
-		if eNode == nil {
+		if eNode == nil || eNode.InMaintenance() {
 			continue
 		}
 
-		credentials, err := eNode.Credentials(cid, titanRsa, s.NodeManager.PrivateKey)
+		credentials, err := eNode.Credentials(cid, private, titanRsa, s.NodeManager.PrivateKey)
 		if err != nil {
 			continue
 		}
@@ -63,12 +103,7 @@ func (s *Scheduler) GetEdgeDownloadInfos(ctx context.Context, cid string) (*type
 			Credentials: credentials,
 			NatType:     eNode.NATType,
 		}
-		infos = append(infos, info)
-	}
-
-	ret := &types.EdgeDownloadInfoList{
-		Infos:        infos,
-		SchedulerURL: s.SchedulerCfg.RPCURL,
+		ret.Infos = append(ret.Infos, info)
 	}
 
 	return ret, nil